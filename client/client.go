@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -22,7 +25,12 @@ type Client struct {
 	requestID          atomic.Int64
 	clientCapabilities mcp.ClientCapabilities
 	serverCapabilities mcp.ServerCapabilities
+	protocolVersion    string
 	samplingHandler    SamplingHandler
+	rootsHandler       RootsHandler
+	elicitationHandler ElicitationHandler
+	tracePropagator    mcp.TracePropagator
+	logger             *slog.Logger
 }
 
 type ClientOption func(*Client)
@@ -42,6 +50,40 @@ func WithSamplingHandler(handler SamplingHandler) ClientOption {
 	}
 }
 
+// WithElicitationHandler sets the elicitation handler for the client.
+// When set, the client will declare elicitation capability during initialization.
+func WithElicitationHandler(handler ElicitationHandler) ClientOption {
+	return func(c *Client) {
+		c.elicitationHandler = handler
+	}
+}
+
+// WithRootsHandler sets the roots handler for the client.
+// When set, the client will declare roots capability during initialization,
+// and answer the roots/list requests of the server.
+func WithRootsHandler(handler RootsHandler) ClientOption {
+	return func(c *Client) {
+		c.rootsHandler = handler
+	}
+}
+
+// WithTracePropagator propagates the trace context of the requests to the
+// server, in the traceparent and tracestate fields of their _meta, and that of
+// the requests of the server, such as sampling requests, to their handlers.
+func WithTracePropagator(propagator mcp.TracePropagator) ClientOption {
+	return func(c *Client) {
+		c.tracePropagator = propagator
+	}
+}
+
+// WithLogger sets the logger of the client, slog.Default() by default. The
+// requests sent to the server are logged at debug level.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
 // WithSession assumes a MCP Session has already been initialized
 func WithSession() ClientOption {
 	return func(c *Client) {
@@ -60,6 +102,7 @@ func WithSession() ClientOption {
 func NewClient(transport transport.Interface, options ...ClientOption) *Client {
 	client := &Client{
 		transport: transport,
+		logger:    slog.Default(),
 	}
 
 	for _, opt := range options {
@@ -122,6 +165,14 @@ func (c *Client) sendRequest(
 		return nil, fmt.Errorf("client not initialized")
 	}
 
+	if c.tracePropagator != nil {
+		var err error
+		params, err = mcp.InjectTraceContext(ctx, c.tracePropagator, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject trace context: %w", err)
+		}
+	}
+
 	id := c.requestID.Add(1)
 
 	request := transport.JSONRPCRequest{
@@ -131,20 +182,54 @@ func (c *Client) sendRequest(
 		Params:  params,
 	}
 
+	start := time.Now()
 	response, err := c.transport.SendRequest(ctx, request)
 	if err != nil {
+		c.logRequest(ctx, id, method, start, slog.Any("error", err))
 		return nil, transport.NewError(err)
 	}
 
 	if response.Error != nil {
+		c.logRequest(ctx, id, method, start, slog.Int("errorCode", response.Error.Code), slog.String("error", response.Error.Message))
 		return nil, errors.New(response.Error.Message)
 	}
 
+	c.logRequest(ctx, id, method, start)
 	return &response.Result, nil
 }
 
+// logRequest logs a request sent to the server at debug level, with the
+// attributes of its error if it failed
+func (c *Client) logRequest(ctx context.Context, id int64, method string, start time.Time, errAttrs ...slog.Attr) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := append([]slog.Attr{
+		slog.Int64("requestID", id),
+		slog.String("method", method),
+		slog.Duration("duration", time.Since(start)),
+	}, errAttrs...)
+	if len(errAttrs) > 0 {
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "request failed", attrs...)
+		return
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "request answered", attrs...)
+}
+
+// UnsupportedProtocolVersionError is returned by Initialize when the server
+// answers with a protocol version the client does not support.
+type UnsupportedProtocolVersionError struct {
+	Version string
+}
+
+func (e UnsupportedProtocolVersionError) Error() string {
+	return fmt.Sprintf("unsupported protocol version: %q", e.Version)
+}
+
 // Initialize negotiates with the server.
 // Must be called after Start, and before any request methods.
+// It fails with an UnsupportedProtocolVersionError if the server answers
+// with a protocol version the client does not support.
 func (c *Client) Initialize(
 	ctx context.Context,
 	request mcp.InitializeRequest,
@@ -154,6 +239,14 @@ func (c *Client) Initialize(
 	if c.samplingHandler != nil {
 		capabilities.Sampling = &struct{}{}
 	}
+	if c.elicitationHandler != nil {
+		capabilities.Elicitation = &struct{}{}
+	}
+	if c.rootsHandler != nil {
+		capabilities.Roots = &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: true}
+	}
 
 	// Ensure we send a params object with all required fields
 	params := struct {
@@ -176,8 +269,16 @@ func (c *Client) Initialize(
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Store serverCapabilities
+	if !slices.Contains(mcp.ValidProtocolVersions, result.ProtocolVersion) {
+		return nil, UnsupportedProtocolVersionError{Version: result.ProtocolVersion}
+	}
+
+	// Store serverCapabilities and the negotiated protocol version
 	c.serverCapabilities = result.Capabilities
+	c.protocolVersion = result.ProtocolVersion
+	if versioned, ok := c.transport.(transport.ProtocolVersionInterface); ok {
+		versioned.SetProtocolVersion(result.ProtocolVersion)
+	}
 
 	// Send initialized notification
 	notification := mcp.JSONRPCNotification{
@@ -429,9 +530,25 @@ func (c *Client) Complete(
 // handleIncomingRequest processes incoming requests from the server.
 // This is the main entry point for server-to-client requests like sampling.
 func (c *Client) handleIncomingRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if c.tracePropagator != nil && request.Params != nil {
+		if params, err := json.Marshal(request.Params); err == nil {
+			ctx = mcp.ExtractTraceContext(ctx, c.tracePropagator, params)
+		}
+	}
+
 	switch request.Method {
 	case string(mcp.MethodSamplingCreateMessage):
 		return c.handleSamplingRequestTransport(ctx, request)
+	case string(mcp.MethodListRoots):
+		return c.handleListRootsRequestTransport(ctx, request)
+	case string(mcp.MethodElicitationCreate):
+		return c.handleElicitationRequestTransport(ctx, request)
+	case string(mcp.MethodPing):
+		return &transport.JSONRPCResponse{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      request.ID,
+			Result:  json.RawMessage(`{}`),
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported request method: %s", request.Method)
 	}
@@ -514,6 +631,12 @@ func (c *Client) GetServerCapabilities() mcp.ServerCapabilities {
 	return c.serverCapabilities
 }
 
+// GetProtocolVersion returns the protocol version negotiated during
+// initialization.
+func (c *Client) GetProtocolVersion() string {
+	return c.protocolVersion
+}
+
 // GetClientCapabilities returns the client capabilities.
 func (c *Client) GetClientCapabilities() mcp.ClientCapabilities {
 	return c.clientCapabilities
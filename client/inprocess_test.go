@@ -2,8 +2,11 @@ package client
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -419,3 +422,202 @@ func TestInProcessMCPClient(t *testing.T) {
 		}
 	})
 }
+
+func TestInProcessMCPClient_Progress(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithProgressInterval(0))
+	mcpServer.AddTool(mcp.NewTool("index"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		reporter := server.ProgressReporterFromContext(ctx)
+		for i := 1; i <= 3; i++ {
+			if err := reporter.Report(float64(i), 3, "indexing"); err != nil {
+				return nil, err
+			}
+		}
+		return mcp.NewToolResultText("indexed"), nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	progress := make(chan mcp.JSONRPCNotification, 10)
+	client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == mcp.MethodNotificationProgress {
+			progress <- notification
+		}
+	})
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "index"
+	request.Params.Meta = &mcp.Meta{ProgressToken: "index-1"}
+	if _, err := client.CallTool(context.Background(), request); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case notification := <-progress:
+			if token := notification.Params.AdditionalFields["progressToken"]; token != "index-1" {
+				t.Errorf("Expected progress token 'index-1', got %v", token)
+			}
+			if p := notification.Params.AdditionalFields["progress"]; p != float64(i) {
+				t.Errorf("Expected progress %d, got %v", i, p)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected progress notification %d", i)
+		}
+	}
+}
+
+type fakeRootsHandler struct {
+	mu    sync.Mutex
+	roots []mcp.Root
+}
+
+func (h *fakeRootsHandler) setRoots(roots []mcp.Root) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.roots = roots
+}
+
+func (h *fakeRootsHandler) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &mcp.ListRootsResult{Roots: h.roots}, nil
+}
+
+func TestInProcessMCPClient_Roots(t *testing.T) {
+	changes := make(chan []mcp.Root, 10)
+	hooks := &server.Hooks{}
+	hooks.AddOnRootsChanged(func(ctx context.Context, session server.ClientSession, roots []mcp.Root) {
+		changes <- roots
+	})
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithHooks(hooks))
+	mcpServer.AddTool(mcp.NewTool("workspace"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		roots, err := mcpServer.SessionRoots(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(roots[0].URI), nil
+	})
+
+	handler := &fakeRootsHandler{roots: []mcp.Root{{URI: "file:///workspace", Name: "workspace"}}}
+	client := NewClient(transport.NewInProcessTransport(mcpServer), WithRootsHandler(handler))
+	defer client.Close()
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "workspace"
+	result, err := client.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "file:///workspace" {
+		t.Errorf("Expected root 'file:///workspace', got %q", text)
+	}
+	<-changes
+
+	handler.setRoots([]mcp.Root{{URI: "file:///other"}})
+	if err := client.RootsListChanged(context.Background()); err != nil {
+		t.Fatalf("RootsListChanged failed: %v", err)
+	}
+	select {
+	case roots := <-changes:
+		if len(roots) != 1 || roots[0].URI != "file:///other" {
+			t.Errorf("Expected refreshed roots, got %v", roots)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected roots to be refreshed")
+	}
+}
+
+type fakeElicitationHandler struct {
+	action mcp.ElicitationResponseAction
+}
+
+func (h fakeElicitationHandler) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	if h.action != mcp.ElicitationResponseActionAccept {
+		return &mcp.ElicitationResult{Action: h.action}, nil
+	}
+	return &mcp.ElicitationResult{
+		Action:  h.action,
+		Content: map[string]any{"confirm": true, "message": request.Params.Message},
+	}, nil
+}
+
+func TestInProcessMCPClient_Elicitation(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("deploy"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.RequestElicitation(ctx, "Deploy to production?", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"confirm": map[string]any{"type": "boolean"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.Action != mcp.ElicitationResponseActionAccept {
+			return mcp.NewToolResultText("deployment " + string(result.Action)), nil
+		}
+		var answer struct {
+			Confirm bool   `json:"confirm"`
+			Message string `json:"message"`
+		}
+		if err := result.BindContent(&answer); err != nil {
+			return nil, err
+		}
+		if !answer.Confirm || answer.Message != "Deploy to production?" {
+			return mcp.NewToolResultError("unexpected answer"), nil
+		}
+		return mcp.NewToolResultText("deployed"), nil
+	})
+
+	for action, expected := range map[mcp.ElicitationResponseAction]string{
+		mcp.ElicitationResponseActionAccept:  "deployed",
+		mcp.ElicitationResponseActionDecline: "deployment decline",
+	} {
+		t.Run(string(action), func(t *testing.T) {
+			client := NewClient(transport.NewInProcessTransport(mcpServer),
+				WithElicitationHandler(fakeElicitationHandler{action: action}))
+			defer client.Close()
+
+			if err := client.Start(context.Background()); err != nil {
+				t.Fatalf("Failed to start client: %v", err)
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+				t.Fatalf("Failed to initialize: %v", err)
+			}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "deploy"
+			result, err := client.CallTool(context.Background(), request)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != expected {
+				t.Errorf("Expected %q, got %q", expected, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_Initialize_UnsupportedProtocolVersion(t *testing.T) {
+	mockTransport := newMockTransport()
+	client := NewClient(mockTransport)
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+
+	go func() {
+		<-mockTransport.requestChan
+		mockTransport.responseChan <- &transport.JSONRPCResponse{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(1),
+			Result:  []byte(`{"protocolVersion":"1999-01-01","capabilities":{},"serverInfo":{"name":"test-server","version":"1.0.0"}}`),
+		}
+	}()
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err := client.Initialize(ctx, initRequest)
+
+	var versionErr UnsupportedProtocolVersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("Expected UnsupportedProtocolVersionError, got %v", err)
+	}
+	if versionErr.Version != "1999-01-01" {
+		t.Errorf("Expected version 1999-01-01, got %s", versionErr.Version)
+	}
+	if client.initialized {
+		t.Error("Client should not be initialized")
+	}
+}
+
+func TestHTTPClient_ProtocolVersion(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(
+		mcp.NewTool("version", mcp.WithToolTitle("Protocol version")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(server.ProtocolVersionFromContext(ctx)), nil
+		},
+	)
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	for _, version := range []string{mcp.ProtocolVersion20250326, mcp.ProtocolVersion20250618} {
+		t.Run(version, func(t *testing.T) {
+			client, err := NewStreamableHttpClient(testServer.URL)
+			if err != nil {
+				t.Fatalf("create client failed %v", err)
+			}
+			defer client.Close()
+
+			ctx := context.Background()
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = version
+			initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+			if _, err := client.Initialize(ctx, initRequest); err != nil {
+				t.Fatalf("Initialize failed: %v", err)
+			}
+			if client.GetProtocolVersion() != version {
+				t.Errorf("Expected negotiated version %s, got %s", version, client.GetProtocolVersion())
+			}
+
+			// The server knows the version from the MCP-Protocol-Version header
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "version"
+			result, err := client.CallTool(ctx, request)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != version {
+				t.Errorf("Expected server to see version %s, got %s", version, text)
+			}
+
+			tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			wantTitle := ""
+			if version == mcp.ProtocolVersion20250618 {
+				wantTitle = "Protocol version"
+			}
+			if tools.Tools[0].Title != wantTitle {
+				t.Errorf("Expected tool title %q, got %q", wantTitle, tools.Tools[0].Title)
+			}
+		})
+	}
+}
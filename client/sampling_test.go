@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // mockSamplingHandler implements SamplingHandler for testing
@@ -272,3 +274,80 @@ func mockJSONRPCRequest(mcpRequest mcp.CreateMessageRequest) transport.JSONRPCRe
 		Params:  mcpRequest.CreateMessageParams,
 	}
 }
+
+func TestClient_ServerRequestsOverHTTP(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		srv := server.ServerFromContext(ctx)
+		if err := srv.PingClient(ctx); err != nil {
+			return nil, err
+		}
+		result, err := srv.RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages: []mcp.SamplingMessage{
+					{Role: mcp.RoleUser, Content: mcp.NewTextContent("Hello")},
+				},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+
+	sseServer := server.NewTestServer(mcpServer)
+	defer sseServer.Close()
+	streamableServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer streamableServer.Close()
+
+	newTransports := map[string]func() (transport.Interface, error){
+		"sse": func() (transport.Interface, error) {
+			return transport.NewSSE(sseServer.URL + "/sse")
+		},
+		"streamable-http": func() (transport.Interface, error) {
+			return transport.NewStreamableHTTP(streamableServer.URL)
+		},
+	}
+
+	for name, newTransport := range newTransports {
+		t.Run(name, func(t *testing.T) {
+			trans, err := newTransport()
+			if err != nil {
+				t.Fatalf("Failed to create transport: %v", err)
+			}
+			client := NewClient(trans, WithSamplingHandler(&mockSamplingHandler{
+				result: &mcp.CreateMessageResult{
+					SamplingMessage: mcp.SamplingMessage{
+						Role:    mcp.RoleAssistant,
+						Content: mcp.NewTextContent("Hi there"),
+					},
+					Model: "test-model",
+				},
+			}))
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := client.Start(ctx); err != nil {
+				t.Fatalf("Failed to start client: %v", err)
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+			if _, err := client.Initialize(ctx, initRequest); err != nil {
+				t.Fatalf("Initialize failed: %v", err)
+			}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "ask"
+			result, err := client.CallTool(ctx, request)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != "Hi there" {
+				t.Errorf("Expected sampled text 'Hi there', got %q", text)
+			}
+		})
+	}
+}
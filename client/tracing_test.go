@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type traceParentKey struct{}
+
+// contextPropagator propagates the traceparent stored in the context
+type contextPropagator struct{}
+
+func (contextPropagator) Inject(ctx context.Context, fields map[string]string) {
+	if traceParent, ok := ctx.Value(traceParentKey{}).(string); ok {
+		fields[mcp.TraceParentMetaKey] = traceParent
+	}
+}
+
+func (contextPropagator) Extract(ctx context.Context, fields map[string]string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, fields[mcp.TraceParentMetaKey])
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(map[string]any) {}
+func (noopSpan) RecordError(error)            {}
+func (noopSpan) End()                         {}
+
+// spanTracer gives every span the traceparent "server-" followed by the name
+// of the span
+type spanTracer struct {
+	contextPropagator
+}
+
+func (spanTracer) Start(ctx context.Context, name string, kind server.SpanKind, attributes map[string]any) (context.Context, server.Span) {
+	return context.WithValue(ctx, traceParentKey{}, "server-"+name), noopSpan{}
+}
+
+// tracingSamplingHandler answers with the traceparent of its context
+type tracingSamplingHandler struct{}
+
+func (tracingSamplingHandler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	traceParent, _ := ctx.Value(traceParentKey{}).(string)
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(traceParent),
+		},
+		Model: "test-model",
+	}, nil
+}
+
+func TestClient_TraceContextPropagation(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithTracer(spanTracer{}))
+	mcpServer.AddTool(mcp.NewTool("trace"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received, _ := request.Params.Meta.AdditionalFields[mcp.TraceParentMetaKey].(string)
+		result, err := server.ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("trace")}},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(received + " " + result.Content.(mcp.TextContent).Text), nil
+	})
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer httpServer.Close()
+
+	trans, err := transport.NewStreamableHTTP(httpServer.URL)
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	client := NewClient(trans, WithTracePropagator(contextPropagator{}), WithSamplingHandler(tracingSamplingHandler{}))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "trace"
+	request.Params.Meta = &mcp.Meta{ProgressToken: "progress"}
+	result, err := client.CallTool(context.WithValue(ctx, traceParentKey{}, "agent"), request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	// The server receives the trace context of the agent, and the sampling
+	// handler that of the span of the sampling request
+	if text := result.Content[0].(mcp.TextContent).Text; text != "agent server-sampling/createMessage" {
+		t.Errorf("Expected propagated trace contexts, got %q", text)
+	}
+}
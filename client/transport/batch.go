@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var errEmptyBatch = errors.New("batch must contain at least one request")
+
+// ErrBatchNotSupported is returned by SendBatch when the protocol version
+// negotiated with the server, 2025-06-18 or later, no longer has JSON-RPC
+// batching.
+var ErrBatchNotSupported = errors.New("batches are not supported by the negotiated protocol version")
+
+var (
+	_ BatchInterface = (*Stdio)(nil)
+	_ BatchInterface = (*SSE)(nil)
+	_ BatchInterface = (*StreamableHTTP)(nil)
+	_ BatchInterface = (*InProcessTransport)(nil)
+)
+
+// checkBatchSupported fails if the negotiated protocol version, as stored by
+// SetProtocolVersion, removed batching. Batches can be sent before the version
+// is known.
+func checkBatchSupported(version string) error {
+	if version != "" && mcp.ProtocolVersionAtLeast(version, mcp.ProtocolVersion20250618) {
+		return fmt.Errorf("%w: %s", ErrBatchNotSupported, version)
+	}
+	return nil
+}
+
+// splitMessages returns the JSON-RPC messages contained in data, which is
+// either a single message or a batch of them.
+func splitMessages(data []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return []json.RawMessage{data}, nil
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(trimmed, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// batchResponses matches the responses received for a batch to its requests.
+type batchResponses struct {
+	index     map[string]int
+	responses []*JSONRPCResponse
+	remaining int
+}
+
+func newBatchResponses(requests []JSONRPCRequest) *batchResponses {
+	b := &batchResponses{
+		index:     make(map[string]int, len(requests)),
+		responses: make([]*JSONRPCResponse, len(requests)),
+		remaining: len(requests),
+	}
+	for i, request := range requests {
+		b.index[request.ID.String()] = i
+	}
+	return b
+}
+
+// add records a response of the batch. Responses to requests that are not
+// part of the batch are ignored. An error response without ID rejects the
+// whole batch.
+func (b *batchResponses) add(response *JSONRPCResponse) error {
+	if response.ID.IsNil() {
+		if response.Error != nil {
+			return fmt.Errorf("batch rejected: %s", response.Error.Message)
+		}
+		return nil
+	}
+	i, ok := b.index[response.ID.String()]
+	if !ok || b.responses[i] != nil {
+		return nil
+	}
+	b.responses[i] = response
+	b.remaining--
+	return nil
+}
+
+// addMessages records the responses contained in data, which is either a
+// single response or a batch of them.
+func (b *batchResponses) addMessages(data []byte) error {
+	messages, err := splitMessages(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	for _, message := range messages {
+		var response JSONRPCResponse
+		if err := json.Unmarshal(message, &response); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if err := b.add(&response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *batchResponses) complete() bool {
+	return b.remaining == 0
+}
+
+func (b *batchResponses) result() ([]*JSONRPCResponse, error) {
+	if !b.complete() {
+		return nil, fmt.Errorf("missing responses for %d requests of the batch", b.remaining)
+	}
+	return b.responses, nil
+}
+
+// awaitResponses waits for a response on each of the channels, in order. A
+// closed channel means the connection was closed before the response arrived.
+func awaitResponses(ctx context.Context, channels []chan *JSONRPCResponse) ([]*JSONRPCResponse, error) {
+	responses := make([]*JSONRPCResponse, len(channels))
+	for i, ch := range channels {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case response, ok := <-ch:
+			if !ok {
+				return nil, fmt.Errorf("connection has been closed")
+			}
+			responses[i] = response
+		}
+	}
+	return responses, nil
+}
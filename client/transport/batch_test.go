@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSendBatch(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(true),
+	)
+	mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+
+	transports := map[string]func(t *testing.T) BatchInterface{
+		"stdio": func(t *testing.T) BatchInterface {
+			serverIn, clientOut := io.Pipe()
+			clientIn, serverOut := io.Pipe()
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				_ = server.NewStdioServer(mcpServer).Listen(ctx, serverIn, serverOut)
+			}()
+			t.Cleanup(func() {
+				cancel()
+				clientOut.Close()
+				serverOut.Close()
+			})
+			return NewIO(clientIn, clientOut, io.NopCloser(nil))
+		},
+		"sse": func(t *testing.T) BatchInterface {
+			testServer := server.NewTestServer(mcpServer)
+			t.Cleanup(testServer.Close)
+			trans, err := NewSSE(testServer.URL + "/sse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return trans
+		},
+		"streamable http": func(t *testing.T) BatchInterface {
+			testServer := server.NewTestStreamableHTTPServer(mcpServer)
+			t.Cleanup(testServer.Close)
+			trans, err := NewStreamableHTTP(testServer.URL + "/mcp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return trans
+		},
+		"in process": func(t *testing.T) BatchInterface {
+			return NewInProcessTransport(mcpServer)
+		},
+	}
+
+	for name, newTransport := range transports {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			trans := newTransport(t)
+			if err := trans.Start(ctx); err != nil {
+				t.Fatalf("Failed to start transport: %v", err)
+			}
+			defer trans.Close()
+
+			_, err := trans.SendRequest(ctx, JSONRPCRequest{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				ID:      mcp.NewRequestId(int64(1)),
+				Method:  string(mcp.MethodInitialize),
+				Params: map[string]any{
+					// the last version with batches
+					"protocolVersion": mcp.ProtocolVersion20250326,
+					"clientInfo":      map[string]any{"name": "test", "version": "1.0.0"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Failed to initialize: %v", err)
+			}
+			trans.(ProtocolVersionInterface).SetProtocolVersion(mcp.ProtocolVersion20250326)
+
+			responses, err := trans.SendBatch(ctx, []JSONRPCRequest{
+				{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(int64(2)), Method: string(mcp.MethodToolsList)},
+				{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(int64(3)), Method: string(mcp.MethodPromptsList)},
+				{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(int64(4)), Method: "unknown/method"},
+			})
+			if err != nil {
+				t.Fatalf("SendBatch failed: %v", err)
+			}
+			if len(responses) != 3 {
+				t.Fatalf("Expected 3 responses, got %d", len(responses))
+			}
+			for i, response := range responses {
+				if id := response.ID.String(); id != mcp.NewRequestId(int64(i+2)).String() {
+					t.Errorf("Expected response %d to have ID %d, got %s", i, i+2, id)
+				}
+			}
+
+			var tools mcp.ListToolsResult
+			if err := json.Unmarshal(responses[0].Result, &tools); err != nil {
+				t.Fatalf("Failed to unmarshal tools: %v", err)
+			}
+			if len(tools.Tools) != 1 || tools.Tools[0].Name != "echo" {
+				t.Errorf("Expected the echo tool, got %v", tools.Tools)
+			}
+			if responses[1].Error != nil {
+				t.Errorf("Expected prompts/list to succeed, got %v", responses[1].Error.Message)
+			}
+			if responses[2].Error == nil || responses[2].Error.Code != mcp.METHOD_NOT_FOUND {
+				t.Errorf("Expected method not found error, got %+v", responses[2])
+			}
+
+			if _, err := trans.SendBatch(ctx, nil); err != errEmptyBatch {
+				t.Errorf("Expected errEmptyBatch, got %v", err)
+			}
+
+			trans.(ProtocolVersionInterface).SetProtocolVersion(mcp.ProtocolVersion20250618)
+			_, err = trans.SendBatch(ctx, []JSONRPCRequest{
+				{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(int64(5)), Method: string(mcp.MethodToolsList)},
+			})
+			if !errors.Is(err, ErrBatchNotSupported) {
+				t.Errorf("Expected ErrBatchNotSupported, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBatchResponses(t *testing.T) {
+	requests := []JSONRPCRequest{
+		{ID: mcp.NewRequestId(int64(1))},
+		{ID: mcp.NewRequestId("two")},
+	}
+
+	t.Run("single responses and batches", func(t *testing.T) {
+		responses := newBatchResponses(requests)
+		if err := responses.addMessages([]byte(`{"jsonrpc":"2.0","id":"two","result":{}}`)); err != nil {
+			t.Fatal(err)
+		}
+		if responses.complete() {
+			t.Fatal("Expected the batch to be incomplete")
+		}
+		if _, err := responses.result(); err == nil {
+			t.Error("Expected an error for missing responses")
+		}
+		if err := responses.addMessages([]byte(`[{"jsonrpc":"2.0","id":99,"result":{}},{"jsonrpc":"2.0","id":1,"result":{}}]`)); err != nil {
+			t.Fatal(err)
+		}
+		result, err := responses.result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result[0].ID.String() != requests[0].ID.String() || result[1].ID.String() != requests[1].ID.String() {
+			t.Errorf("Responses are not in the order of the requests: %v", result)
+		}
+	})
+
+	t.Run("rejected batch", func(t *testing.T) {
+		responses := newBatchResponses(requests)
+		err := responses.addMessages([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Empty batch"}}`))
+		if err == nil || err.Error() != "batch rejected: Empty batch" {
+			t.Errorf("Expected the batch to be rejected, got %v", err)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,7 +18,13 @@ type InProcessTransport struct {
 	sessionID       string
 
 	onNotification func(mcp.JSONRPCNotification)
+	onRequest      RequestHandler
 	notifyMu       sync.RWMutex
+	requestMu      sync.RWMutex
+	requestID      atomic.Int64
+	done           chan struct{}
+	// protocolVersion is the protocol version negotiated with the server
+	protocolVersion atomic.Value // string
 }
 
 type InProcessOption func(*InProcessTransport)
@@ -48,16 +55,40 @@ func NewInProcessTransportWithOptions(server *server.MCPServer, opts ...InProces
 }
 
 func (c *InProcessTransport) Start(ctx context.Context) error {
-	// Create and register session if we have a sampling handler
-	if c.samplingHandler != nil {
-		c.session = server.NewInProcessSession(c.sessionID, c.samplingHandler)
-		if err := c.server.RegisterSession(ctx, c.session); err != nil {
-			return fmt.Errorf("failed to register session: %w", err)
-		}
+	// Create and register a session so that the server can send notifications
+	// and sampling requests back to the client
+	if c.sessionID == "" {
+		c.sessionID = server.GenerateInProcessSessionID()
+	}
+	c.session = server.NewInProcessSession(c.sessionID, c.samplingHandler)
+	c.session.SetRequestHandler(c.handleServerRequest)
+	if err := c.server.RegisterSession(ctx, c.session); err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
 	}
+
+	c.done = make(chan struct{})
+	go c.forwardNotifications(c.session.Notifications(), c.done)
 	return nil
 }
 
+// forwardNotifications delivers the notifications sent by the server on the
+// session to the notification handler, until the transport is closed.
+func (c *InProcessTransport) forwardNotifications(notifications <-chan mcp.JSONRPCNotification, done <-chan struct{}) {
+	for {
+		select {
+		case notification := <-notifications:
+			c.notifyMu.RLock()
+			handler := c.onNotification
+			c.notifyMu.RUnlock()
+			if handler != nil {
+				handler(notification)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
@@ -84,12 +115,51 @@ func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCReq
 	return &rpcResp, nil
 }
 
+// SendBatch handles the requests as a single JSON-RPC batch, and returns their
+// responses in the order of the requests.
+func (c *InProcessTransport) SendBatch(ctx context.Context, requests []JSONRPCRequest) ([]*JSONRPCResponse, error) {
+	if len(requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	version, _ := c.protocolVersion.Load().(string)
+	if err := checkBatchSupported(version); err != nil {
+		return nil, err
+	}
+	batchBytes, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	// Add session to context if available
+	if c.session != nil {
+		ctx = c.server.WithContext(ctx, c.session)
+	}
+
+	respMessage := c.server.HandleMessage(ctx, batchBytes)
+	respBytes, err := json.Marshal(respMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response message: %w", err)
+	}
+
+	responses := newBatchResponses(requests)
+	if err := responses.addMessages(respBytes); err != nil {
+		return nil, err
+	}
+	return responses.result()
+}
+
 func (c *InProcessTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
 	notificationBytes, err := json.Marshal(notification)
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 	notificationBytes = append(notificationBytes, '\n')
+
+	// Add session to context if available
+	if c.session != nil {
+		ctx = c.server.WithContext(ctx, c.session)
+	}
+
 	c.server.HandleMessage(ctx, notificationBytes)
 
 	return nil
@@ -101,13 +171,60 @@ func (c *InProcessTransport) SetNotificationHandler(handler func(notification mc
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler for requests sent by the server, such as
+// roots/list.
+func (c *InProcessTransport) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.onRequest = handler
+}
+
+// handleServerRequest passes a request of the server to the request handler
+// and returns the result.
+func (c *InProcessTransport) handleServerRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.requestMu.RLock()
+	handler := c.onRequest
+	c.requestMu.RUnlock()
+	if handler == nil {
+		return nil, fmt.Errorf("no request handler set for %s", method)
+	}
+
+	response, err := handler(ctx, JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(c.requestID.Add(1)),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s request failed: %s", method, response.Error.Message)
+	}
+	return response.Result, nil
+}
+
 func (c *InProcessTransport) Close() error {
 	if c.session != nil {
 		c.server.UnregisterSession(context.Background(), c.sessionID)
 	}
+	if c.done != nil {
+		close(c.done)
+		c.done = nil
+	}
 	return nil
 }
 
 func (c *InProcessTransport) GetSessionId() string {
 	return ""
 }
+
+// SetProtocolVersion sets the protocol version negotiated with the server,
+// which decides whether batches can be sent.
+func (c *InProcessTransport) SetProtocolVersion(version string) {
+	c.protocolVersion.Store(version)
+}
+
+var _ ProtocolVersionInterface = (*InProcessTransport)(nil)
+
+var _ BidirectionalInterface = (*InProcessTransport)(nil)
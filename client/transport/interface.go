@@ -47,6 +47,29 @@ type BidirectionalInterface interface {
 	SetRequestHandler(handler RequestHandler)
 }
 
+// BatchInterface extends Interface to support sending several requests at
+// once, as a JSON-RPC batch.
+type BatchInterface interface {
+	Interface
+
+	// SendBatch sends the requests as a single JSON-RPC batch and returns
+	// their responses, in the order of the requests. It fails with
+	// ErrBatchNotSupported if the negotiated protocol version is 2025-06-18
+	// or later, which removed batching.
+	SendBatch(ctx context.Context, requests []JSONRPCRequest) ([]*JSONRPCResponse, error)
+}
+
+// ProtocolVersionInterface extends Interface for transports that need the
+// protocol version negotiated during initialization, such as the HTTP
+// transports which send it in the MCP-Protocol-Version header, and the
+// transports refusing to send batches from version 2025-06-18 on.
+type ProtocolVersionInterface interface {
+	Interface
+
+	// SetProtocolVersion sets the negotiated protocol version.
+	SetProtocolVersion(version string)
+}
+
 type JSONRPCRequest struct {
 	JSONRPC string        `json:"jsonrpc"`
 	ID      mcp.RequestId `json:"id"`
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// isRequest reports whether a message received from the server is a request,
+// rather than a response to one of ours.
+func isRequest(message []byte) bool {
+	var baseMessage struct {
+		ID     *mcp.RequestId `json:"id,omitempty"`
+		Method string         `json:"method,omitempty"`
+	}
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		return false
+	}
+	return baseMessage.Method != "" && baseMessage.ID != nil
+}
+
+// newErrorResponse builds the error response to the request with the given ID.
+func newErrorResponse(id mcp.RequestId, code int, message string) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Error: &struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// handleServerRequest passes a request of the server to handler and returns
+// the response to send back, which is nil if the handler has none. Requests
+// are answered with an error if there is no handler, if the handler fails, or
+// if ctx is already done.
+func handleServerRequest(ctx context.Context, handler RequestHandler, request JSONRPCRequest) *JSONRPCResponse {
+	if handler == nil {
+		return newErrorResponse(request.ID, mcp.METHOD_NOT_FOUND, "No request handler configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return newErrorResponse(request.ID, mcp.INTERNAL_ERROR, err.Error())
+	}
+	response, err := handler(ctx, request)
+	if err != nil {
+		return newErrorResponse(request.ID, mcp.INTERNAL_ERROR, err.Error())
+	}
+	return response
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -30,16 +31,23 @@ type SSE struct {
 	mu             sync.RWMutex
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	onRequest      RequestHandler
+	requestMu      sync.RWMutex
 	endpointChan   chan struct{}
 	headers        map[string]string
 	headerFunc     HTTPHeaderFunc
 
+	protocolVersion atomic.Value // string
+
 	started         atomic.Bool
 	closed          atomic.Bool
+	streamCtx       context.Context // done when the SSE stream is closed
 	cancelSSEStream context.CancelFunc
 
 	// OAuth support
 	oauthHandler *OAuthHandler
+
+	logger *slog.Logger
 }
 
 type ClientOption func(*SSE)
@@ -68,6 +76,13 @@ func WithOAuth(config OAuthConfig) ClientOption {
 	}
 }
 
+// WithSSELogger sets the logger of the transport, slog.Default() by default.
+func WithSSELogger(logger *slog.Logger) ClientOption {
+	return func(sc *SSE) {
+		sc.logger = logger
+	}
+}
+
 // NewSSE creates a new SSE-based MCP client with the given base URL.
 // Returns an error if the URL is invalid.
 func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
@@ -82,6 +97,7 @@ func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
 		responses:    make(map[string]chan *JSONRPCResponse),
 		endpointChan: make(chan struct{}),
 		headers:      make(map[string]string),
+		logger:       slog.Default(),
 	}
 
 	for _, opt := range options {
@@ -107,6 +123,7 @@ func (c *SSE) Start(ctx context.Context) error {
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
+	c.streamCtx = ctx
 	c.cancelSSEStream = cancel
 
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL.String(), nil)
@@ -204,7 +221,7 @@ func (c *SSE) readSSE(reader io.ReadCloser) {
 				break
 			}
 			if !c.closed.Load() {
-				fmt.Printf("SSE stream error: %v\n", err)
+				c.logger.Error("SSE stream error", "error", err)
 			}
 			return
 		}
@@ -240,50 +257,75 @@ func (c *SSE) handleSSEEvent(event, data string) {
 	case "endpoint":
 		endpoint, err := c.baseURL.Parse(data)
 		if err != nil {
-			fmt.Printf("Error parsing endpoint URL: %v\n", err)
+			c.logger.Error("failed to parse endpoint URL", "endpoint", data, "error", err)
 			return
 		}
 		if endpoint.Host != c.baseURL.Host {
-			fmt.Printf("Endpoint origin does not match connection origin\n")
+			c.logger.Error("endpoint origin does not match connection origin", "endpoint", endpoint.String())
 			return
 		}
 		c.endpoint = endpoint
 		close(c.endpointChan)
 
 	case "message":
-		var baseMessage JSONRPCResponse
-		if err := json.Unmarshal([]byte(data), &baseMessage); err != nil {
-			fmt.Printf("Error unmarshaling message: %v\n", err)
+		// Batches are handled message by message
+		messages, err := splitMessages([]byte(data))
+		if err != nil {
+			c.logger.Error("failed to unmarshal message", "error", err)
 			return
 		}
+		for _, message := range messages {
+			c.handleMessage(message)
+		}
+	}
+}
 
-		// Handle notification
-		if baseMessage.ID.IsNil() {
-			var notification mcp.JSONRPCNotification
-			if err := json.Unmarshal([]byte(data), &notification); err != nil {
-				return
-			}
-			c.notifyMu.RLock()
-			if c.onNotification != nil {
-				c.onNotification(notification)
-			}
-			c.notifyMu.RUnlock()
+// handleMessage routes a single message received from the server: responses
+// to their pending request, and notifications and requests to their handlers.
+func (c *SSE) handleMessage(message []byte) {
+	// Handle request from the server
+	if isRequest(message) {
+		var request JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			c.logger.Error("failed to unmarshal request", "error", err)
 			return
 		}
+		c.handleIncomingRequest(request)
+		return
+	}
 
-		// Create string key for map lookup
-		idKey := baseMessage.ID.String()
-
-		c.mu.RLock()
-		ch, exists := c.responses[idKey]
-		c.mu.RUnlock()
+	var baseMessage JSONRPCResponse
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		c.logger.Error("failed to unmarshal message", "error", err)
+		return
+	}
 
-		if exists {
-			ch <- &baseMessage
-			c.mu.Lock()
-			delete(c.responses, idKey)
-			c.mu.Unlock()
+	// Handle notification
+	if baseMessage.ID.IsNil() {
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(message, &notification); err != nil {
+			return
+		}
+		c.notifyMu.RLock()
+		if c.onNotification != nil {
+			c.onNotification(notification)
 		}
+		c.notifyMu.RUnlock()
+		return
+	}
+
+	// Create string key for map lookup
+	idKey := baseMessage.ID.String()
+
+	c.mu.RLock()
+	ch, exists := c.responses[idKey]
+	c.mu.RUnlock()
+
+	if exists {
+		ch <- &baseMessage
+		c.mu.Lock()
+		delete(c.responses, idKey)
+		c.mu.Unlock()
 	}
 }
 
@@ -293,6 +335,39 @@ func (c *SSE) SetNotificationHandler(handler func(notification mcp.JSONRPCNotifi
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler for requests sent by the server on the
+// SSE stream, such as sampling or roots requests.
+func (c *SSE) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.onRequest = handler
+}
+
+// handleIncomingRequest handles a request of the server in the background and
+// posts the response to the message endpoint.
+func (c *SSE) handleIncomingRequest(request JSONRPCRequest) {
+	c.requestMu.RLock()
+	handler := c.onRequest
+	c.requestMu.RUnlock()
+
+	go func() {
+		response := handleServerRequest(c.streamCtx, handler, request)
+		if response == nil {
+			return
+		}
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			c.logger.Error("failed to marshal response", "error", err)
+			return
+		}
+		if err := c.postMessage(c.streamCtx, responseBytes); err != nil {
+			c.logger.Error("failed to send response", "error", err)
+		}
+	}()
+}
+
+var _ BidirectionalInterface = (*SSE)(nil)
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response.
 // Returns the raw JSON response message or an error if the request fails.
 func (c *SSE) SendRequest(
@@ -316,14 +391,105 @@ func (c *SSE) SendRequest(
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// Create string key for map lookup
+	idKey := request.ID.String()
+
+	// Register response channel
+	responseChan := make(chan *JSONRPCResponse, 1)
+	c.mu.Lock()
+	c.responses[idKey] = responseChan
+	c.mu.Unlock()
+	deleteResponseChan := func() {
+		c.mu.Lock()
+		delete(c.responses, idKey)
+		c.mu.Unlock()
+	}
+
+	if err := c.postMessage(ctx, requestBytes); err != nil {
+		deleteResponseChan()
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		deleteResponseChan()
+		return nil, ctx.Err()
+	case response, ok := <-responseChan:
+		if ok {
+			return response, nil
+		}
+		return nil, fmt.Errorf("connection has been closed")
+	}
+}
+
+// SendBatch sends the requests to the server as a single JSON-RPC batch and
+// waits for all their responses on the SSE stream. The responses are returned
+// in the order of the requests.
+func (c *SSE) SendBatch(
+	ctx context.Context,
+	requests []JSONRPCRequest,
+) ([]*JSONRPCResponse, error) {
+	if !c.started.Load() {
+		return nil, fmt.Errorf("transport not started yet")
+	}
+	if c.closed.Load() {
+		return nil, fmt.Errorf("transport has been closed")
+	}
+	if c.endpoint == nil {
+		return nil, fmt.Errorf("endpoint not received")
+	}
+	if len(requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	version, _ := c.protocolVersion.Load().(string)
+	if err := checkBatchSupported(version); err != nil {
+		return nil, err
+	}
+
+	batchBytes, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	// Register a response channel for each request
+	channels := make([]chan *JSONRPCResponse, len(requests))
+	c.mu.Lock()
+	for i, request := range requests {
+		channels[i] = make(chan *JSONRPCResponse, 1)
+		c.responses[request.ID.String()] = channels[i]
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		for i, request := range requests {
+			if c.responses[request.ID.String()] == channels[i] {
+				delete(c.responses, request.ID.String())
+			}
+		}
+		c.mu.Unlock()
+	}()
+
+	if err := c.postMessage(ctx, batchBytes); err != nil {
+		return nil, err
+	}
+
+	return awaitResponses(ctx, channels)
+}
+
+// postMessage posts a message to the endpoint of the server. The response to
+// requests is delivered on the SSE stream.
+func (c *SSE) postMessage(ctx context.Context, body []byte) error {
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String(), bytes.NewReader(requestBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String(), bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
+	if version, _ := c.protocolVersion.Load().(string); version != "" {
+		req.Header.Set(headerKeyProtocolVersion, version)
+	}
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
@@ -334,11 +500,11 @@ func (c *SSE) SendRequest(
 		if err != nil {
 			// If we get an authorization error, return a specific error that can be handled by the client
 			if err.Error() == "no valid token available, authorization required" {
-				return nil, &OAuthAuthorizationRequiredError{
+				return &OAuthAuthorizationRequiredError{
 					Handler: c.oauthHandler,
 				}
 			}
-			return nil, fmt.Errorf("failed to get authorization header: %w", err)
+			return fmt.Errorf("failed to get authorization header: %w", err)
 		}
 		req.Header.Set("Authorization", authHeader)
 	}
@@ -349,59 +515,33 @@ func (c *SSE) SendRequest(
 		}
 	}
 
-	// Create string key for map lookup
-	idKey := request.ID.String()
-
-	// Register response channel
-	responseChan := make(chan *JSONRPCResponse, 1)
-	c.mu.Lock()
-	c.responses[idKey] = responseChan
-	c.mu.Unlock()
-	deleteResponseChan := func() {
-		c.mu.Lock()
-		delete(c.responses, idKey)
-		c.mu.Unlock()
-	}
-
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		deleteResponseChan()
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 
 	// Drain any outstanding io
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check if we got an error response
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		deleteResponseChan()
-
 		// Handle OAuth unauthorized error
 		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return nil, &OAuthAuthorizationRequiredError{
+			return &OAuthAuthorizationRequiredError{
 				Handler: c.oauthHandler,
 			}
 		}
 
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, respBody)
 	}
 
-	select {
-	case <-ctx.Done():
-		deleteResponseChan()
-		return nil, ctx.Err()
-	case response, ok := <-responseChan:
-		if ok {
-			return response, nil
-		}
-		return nil, fmt.Errorf("connection has been closed")
-	}
+	return nil
 }
 
 // Close shuts down the SSE client connection and cleans up any pending responses.
@@ -434,6 +574,14 @@ func (c *SSE) GetSessionId() string {
 	return ""
 }
 
+// SetProtocolVersion sets the protocol version negotiated with the server,
+// sent in the MCP-Protocol-Version header of subsequent messages.
+func (c *SSE) SetProtocolVersion(version string) {
+	c.protocolVersion.Store(version)
+}
+
+var _ ProtocolVersionInterface = (*SSE)(nil)
+
 // SendNotification sends a JSON-RPC notification to the server without expecting a response.
 func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
 	if c.endpoint == nil {
@@ -456,6 +604,9 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if version, _ := c.protocolVersion.Load().(string); version != "" {
+		req.Header.Set(headerKeyProtocolVersion, version)
+	}
 	// Set custom HTTP headers
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
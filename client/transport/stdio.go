@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -36,6 +38,9 @@ type Stdio struct {
 	requestMu      sync.RWMutex
 	ctx            context.Context
 	ctxMu          sync.RWMutex
+	logger         *slog.Logger
+	// protocolVersion is the protocol version negotiated with the server
+	protocolVersion atomic.Value // string
 }
 
 // StdioOption defines a function that configures a Stdio transport instance.
@@ -56,6 +61,13 @@ func WithCommandFunc(f CommandFunc) StdioOption {
 	}
 }
 
+// WithStdioLogger sets the logger of the transport, slog.Default() by default.
+func WithStdioLogger(logger *slog.Logger) StdioOption {
+	return func(s *Stdio) {
+		s.logger = logger
+	}
+}
+
 // NewIO returns a new stdio-based transport using existing input, output, and
 // logging streams instead of spawning a subprocess.
 // This is useful for testing and simulating client behavior.
@@ -68,6 +80,7 @@ func NewIO(input io.Reader, output io.WriteCloser, logging io.ReadCloser) *Stdio
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
 		ctx:       context.Background(),
+		logger:    slog.Default(),
 	}
 }
 
@@ -101,6 +114,7 @@ func NewStdioWithOptions(
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
 		ctx:       context.Background(),
+		logger:    slog.Default(),
 	}
 
 	for _, opt := range opts {
@@ -208,6 +222,14 @@ func (c *Stdio) GetSessionId() string {
 	return ""
 }
 
+// SetProtocolVersion sets the protocol version negotiated with the server,
+// which decides whether batches can be sent.
+func (c *Stdio) SetProtocolVersion(version string) {
+	c.protocolVersion.Store(version)
+}
+
+var _ ProtocolVersionInterface = (*Stdio)(nil)
+
 // SetNotificationHandler sets the handler function to be called when a notification is received.
 // Only one handler can be set at a time; setting a new one replaces the previous handler.
 func (c *Stdio) SetNotificationHandler(
@@ -238,64 +260,77 @@ func (c *Stdio) readResponses() {
 			line, err := c.stdout.ReadString('\n')
 			if err != nil {
 				if err != io.EOF {
-					fmt.Printf("Error reading response: %v\n", err)
+					c.logger.Error("failed to read response", "error", err)
 				}
 				return
 			}
 
-			// First try to parse as a generic message to check for ID field
-			var baseMessage struct {
-				JSONRPC string         `json:"jsonrpc"`
-				ID      *mcp.RequestId `json:"id,omitempty"`
-				Method  string         `json:"method,omitempty"`
-			}
-			if err := json.Unmarshal([]byte(line), &baseMessage); err != nil {
+			// Batches are handled message by message
+			messages, err := splitMessages([]byte(line))
+			if err != nil {
 				continue
 			}
-
-			// If it has a method but no ID, it's a notification
-			if baseMessage.Method != "" && baseMessage.ID == nil {
-				var notification mcp.JSONRPCNotification
-				if err := json.Unmarshal([]byte(line), &notification); err != nil {
-					continue
-				}
-				c.notifyMu.RLock()
-				if c.onNotification != nil {
-					c.onNotification(notification)
-				}
-				c.notifyMu.RUnlock()
-				continue
+			for _, message := range messages {
+				c.handleMessage(message)
 			}
+		}
+	}
+}
 
-			// If it has a method and an ID, it's an incoming request
-			if baseMessage.Method != "" && baseMessage.ID != nil {
-				var request JSONRPCRequest
-				if err := json.Unmarshal([]byte(line), &request); err == nil {
-					c.handleIncomingRequest(request)
-					continue
-				}
-			}
+// handleMessage routes a single message received from the server: responses
+// to their pending request, and notifications and requests to their handlers.
+func (c *Stdio) handleMessage(message []byte) {
+	// First try to parse as a generic message to check for ID field
+	var baseMessage struct {
+		JSONRPC string         `json:"jsonrpc"`
+		ID      *mcp.RequestId `json:"id,omitempty"`
+		Method  string         `json:"method,omitempty"`
+	}
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		return
+	}
 
-			// Otherwise, it's a response to our request
-			var response JSONRPCResponse
-			if err := json.Unmarshal([]byte(line), &response); err != nil {
-				continue
-			}
+	// If it has a method but no ID, it's a notification
+	if baseMessage.Method != "" && baseMessage.ID == nil {
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(message, &notification); err != nil {
+			return
+		}
+		c.notifyMu.RLock()
+		if c.onNotification != nil {
+			c.onNotification(notification)
+		}
+		c.notifyMu.RUnlock()
+		return
+	}
 
-			// Create string key for map lookup
-			idKey := response.ID.String()
+	// If it has a method and an ID, it's an incoming request
+	if baseMessage.Method != "" && baseMessage.ID != nil {
+		var request JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err == nil {
+			c.handleIncomingRequest(request)
+			return
+		}
+	}
 
-			c.mu.RLock()
-			ch, exists := c.responses[idKey]
-			c.mu.RUnlock()
+	// Otherwise, it's a response to our request
+	var response JSONRPCResponse
+	if err := json.Unmarshal(message, &response); err != nil {
+		return
+	}
 
-			if exists {
-				ch <- &response
-				c.mu.Lock()
-				delete(c.responses, idKey)
-				c.mu.Unlock()
-			}
-		}
+	// Create string key for map lookup
+	idKey := response.ID.String()
+
+	c.mu.RLock()
+	ch, exists := c.responses[idKey]
+	c.mu.RUnlock()
+
+	if exists {
+		ch <- &response
+		c.mu.Lock()
+		delete(c.responses, idKey)
+		c.mu.Unlock()
 	}
 }
 
@@ -347,6 +382,55 @@ func (c *Stdio) SendRequest(
 	}
 }
 
+// SendBatch sends the requests to the server as a single JSON-RPC batch and
+// waits for all their responses, which are returned in the order of the
+// requests.
+func (c *Stdio) SendBatch(
+	ctx context.Context,
+	requests []JSONRPCRequest,
+) ([]*JSONRPCResponse, error) {
+	if c.stdin == nil {
+		return nil, fmt.Errorf("stdio client not started")
+	}
+	if len(requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	version, _ := c.protocolVersion.Load().(string)
+	if err := checkBatchSupported(version); err != nil {
+		return nil, err
+	}
+
+	batchBytes, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+	batchBytes = append(batchBytes, '\n')
+
+	// Register a response channel for each request
+	channels := make([]chan *JSONRPCResponse, len(requests))
+	c.mu.Lock()
+	for i, request := range requests {
+		channels[i] = make(chan *JSONRPCResponse, 1)
+		c.responses[request.ID.String()] = channels[i]
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		for i, request := range requests {
+			if c.responses[request.ID.String()] == channels[i] {
+				delete(c.responses, request.ID.String())
+			}
+		}
+		c.mu.Unlock()
+	}()
+
+	if _, err := c.stdin.Write(batchBytes); err != nil {
+		return nil, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	return awaitResponses(ctx, channels)
+}
+
 // SendNotification sends a json RPC Notification to the server.
 func (c *Stdio) SendNotification(
 	ctx context.Context,
@@ -378,19 +462,7 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 
 	if handler == nil {
 		// Send error response if no handler is configured
-		errorResponse := JSONRPCResponse{
-			JSONRPC: mcp.JSONRPC_VERSION,
-			ID:      request.ID,
-			Error: &struct {
-				Code    int             `json:"code"`
-				Message string          `json:"message"`
-				Data    json.RawMessage `json:"data"`
-			}{
-				Code:    mcp.METHOD_NOT_FOUND,
-				Message: "No request handler configured",
-			},
-		}
-		c.sendResponse(errorResponse)
+		c.sendResponse(*handleServerRequest(context.Background(), nil, request))
 		return
 	}
 
@@ -400,46 +472,7 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 		ctx := c.ctx
 		c.ctxMu.RUnlock()
 
-		// Check if context is already cancelled before processing
-		select {
-		case <-ctx.Done():
-			errorResponse := JSONRPCResponse{
-				JSONRPC: mcp.JSONRPC_VERSION,
-				ID:      request.ID,
-				Error: &struct {
-					Code    int             `json:"code"`
-					Message string          `json:"message"`
-					Data    json.RawMessage `json:"data"`
-				}{
-					Code:    mcp.INTERNAL_ERROR,
-					Message: ctx.Err().Error(),
-				},
-			}
-			c.sendResponse(errorResponse)
-			return
-		default:
-		}
-
-		response, err := handler(ctx, request)
-
-		if err != nil {
-			errorResponse := JSONRPCResponse{
-				JSONRPC: mcp.JSONRPC_VERSION,
-				ID:      request.ID,
-				Error: &struct {
-					Code    int             `json:"code"`
-					Message string          `json:"message"`
-					Data    json.RawMessage `json:"data"`
-				}{
-					Code:    mcp.INTERNAL_ERROR,
-					Message: err.Error(),
-				},
-			}
-			c.sendResponse(errorResponse)
-			return
-		}
-
-		if response != nil {
+		if response := handleServerRequest(ctx, handler, request); response != nil {
 			c.sendResponse(*response)
 		}
 	}()
@@ -449,13 +482,13 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 func (c *Stdio) sendResponse(response JSONRPCResponse) {
 	responseBytes, err := json.Marshal(response)
 	if err != nil {
-		fmt.Printf("Error marshaling response: %v\n", err)
+		c.logger.Error("failed to marshal response", "error", err)
 		return
 	}
 	responseBytes = append(responseBytes, '\n')
 
 	if _, err := c.stdin.Write(responseBytes); err != nil {
-		fmt.Printf("Error writing response: %v\n", err)
+		c.logger.Error("failed to write response", "error", err)
 	}
 }
 
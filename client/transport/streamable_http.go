@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime"
 	"net/http"
 	"net/url"
@@ -68,12 +69,22 @@ func WithHTTPOAuth(config OAuthConfig) StreamableHTTPCOption {
 	}
 }
 
-func WithLogger(logger util.Logger) StreamableHTTPCOption {
+// WithHTTPLogger sets the logger of the transport, slog.Default() by default.
+func WithHTTPLogger(logger *slog.Logger) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
 		sc.logger = logger
 	}
 }
 
+// WithLogger sets the logger of the transport.
+//
+// Deprecated: use WithHTTPLogger.
+func WithLogger(logger util.Logger) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.logger = util.ToSlog(logger)
+	}
+}
+
 // WithSession creates a client with a pre-configured session
 func WithSession(sessionID string) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
@@ -99,10 +110,12 @@ type StreamableHTTP struct {
 	httpClient          *http.Client
 	headers             map[string]string
 	headerFunc          HTTPHeaderFunc
-	logger              util.Logger
+	logger              *slog.Logger
 	getListeningEnabled bool
 
-	sessionID atomic.Value // string
+	sessionID       atomic.Value // string
+	protocolVersion atomic.Value // string
+	lastEventID     atomic.Value // string, the last event received on the GET stream
 
 	initialized     chan struct{}
 	initializedOnce sync.Once
@@ -110,6 +123,9 @@ type StreamableHTTP struct {
 	notificationHandler func(mcp.JSONRPCNotification)
 	notifyMu            sync.RWMutex
 
+	requestHandler RequestHandler
+	requestMu      sync.RWMutex
+
 	closed chan struct{}
 
 	// OAuth support
@@ -130,10 +146,12 @@ func NewStreamableHTTP(serverURL string, options ...StreamableHTTPCOption) (*Str
 		httpClient:  &http.Client{},
 		headers:     make(map[string]string),
 		closed:      make(chan struct{}),
-		logger:      util.DefaultLogger(),
+		logger:      slog.Default(),
 		initialized: make(chan struct{}),
 	}
 	smc.sessionID.Store("") // set initial value to simplify later usage
+	smc.protocolVersion.Store("")
+	smc.lastEventID.Store("")
 
 	for _, opt := range options {
 		if opt != nil {
@@ -191,13 +209,16 @@ func (c *StreamableHTTP) Close() error {
 			defer cancel()
 			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.serverURL.String(), nil)
 			if err != nil {
-				c.logger.Errorf("failed to create close request: %v", err)
+				c.logger.Error("failed to create close request", "error", err)
 				return
 			}
 			req.Header.Set(headerKeySessionID, sessionId)
+			if version := c.protocolVersion.Load().(string); version != "" {
+				req.Header.Set(headerKeyProtocolVersion, version)
+			}
 			res, err := c.httpClient.Do(req)
 			if err != nil {
-				c.logger.Errorf("failed to send close request: %v", err)
+				c.logger.Error("failed to send close request", "error", err)
 				return
 			}
 			res.Body.Close()
@@ -208,7 +229,9 @@ func (c *StreamableHTTP) Close() error {
 }
 
 const (
-	headerKeySessionID = "Mcp-Session-Id"
+	headerKeySessionID       = "Mcp-Session-Id"
+	headerKeyProtocolVersion = "Mcp-Protocol-Version"
+	headerKeyLastEventID     = "Last-Event-ID"
 )
 
 // ErrOAuthAuthorizationRequired is a sentinel error for OAuth authorization required
@@ -286,6 +309,8 @@ func (c *StreamableHTTP) SendRequest(
 		if sessionID := resp.Header.Get(headerKeySessionID); sessionID != "" {
 			c.sessionID.Store(sessionID)
 		}
+		// the events of a previous session can't be resumed
+		c.lastEventID.Store("")
 
 		c.initializedOnce.Do(func() {
 			close(c.initialized)
@@ -318,6 +343,121 @@ func (c *StreamableHTTP) SendRequest(
 	}
 }
 
+// SendBatch sends the requests to the server as a single JSON-RPC batch and
+// returns their responses, in the order of the requests. The server may answer
+// with a JSON array or stream the responses over SSE.
+func (c *StreamableHTTP) SendBatch(
+	ctx context.Context,
+	requests []JSONRPCRequest,
+) ([]*JSONRPCResponse, error) {
+	if len(requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	version, _ := c.protocolVersion.Load().(string)
+	if err := checkBatchSupported(version); err != nil {
+		return nil, err
+	}
+
+	batchBody, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	ctx, cancel := c.contextAwareOfClientClose(ctx)
+	defer cancel()
+
+	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(batchBody), "application/json, text/event-stream")
+	if err != nil {
+		return nil, fmt.Errorf("failed to send batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Handle OAuth unauthorized error
+		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
+			return nil, &OAuthAuthorizationRequiredError{
+				Handler: c.oauthHandler,
+			}
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("batch failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	responses := newBatchResponses(requests)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if err := responses.addMessages(body); err != nil {
+			return nil, err
+		}
+
+	case "text/event-stream":
+		// Responses may be streamed one by one, or as a batch, among
+		// notifications from the server
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var batchErr error
+		c.readSSE(ctx, resp.Body, func(event, data, id string) {
+			messages, err := splitMessages([]byte(data))
+			if err != nil {
+				c.logger.Error("failed to unmarshal message", "error", err)
+				return
+			}
+			for _, message := range messages {
+				if isRequest(message) {
+					var request JSONRPCRequest
+					if err := json.Unmarshal(message, &request); err != nil {
+						c.logger.Error("failed to unmarshal request", "error", err)
+						continue
+					}
+					c.handleIncomingRequest(request)
+					continue
+				}
+				var response JSONRPCResponse
+				if err := json.Unmarshal(message, &response); err != nil {
+					c.logger.Error("failed to unmarshal message", "error", err)
+					continue
+				}
+				if response.ID.IsNil() && response.Error == nil {
+					var notification mcp.JSONRPCNotification
+					if err := json.Unmarshal(message, &notification); err != nil {
+						c.logger.Error("failed to unmarshal notification", "error", err)
+						continue
+					}
+					c.notifyMu.RLock()
+					if c.notificationHandler != nil {
+						c.notificationHandler(notification)
+					}
+					c.notifyMu.RUnlock()
+					continue
+				}
+				if err := responses.add(&response); err != nil {
+					batchErr = err
+				}
+			}
+			if batchErr != nil || responses.complete() {
+				cancel()
+			}
+		})
+		if batchErr != nil {
+			return nil, batchErr
+		}
+		if err := ctx.Err(); err != nil && !responses.complete() {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+
+	return responses.result()
+}
+
 func (c *StreamableHTTP) sendHTTP(
 	ctx context.Context,
 	method string,
@@ -337,6 +477,16 @@ func (c *StreamableHTTP) sendHTTP(
 	if sessionID != "" {
 		req.Header.Set(headerKeySessionID, sessionID)
 	}
+	if version := c.protocolVersion.Load().(string); version != "" {
+		req.Header.Set(headerKeyProtocolVersion, version)
+	}
+	if method == http.MethodGet {
+		// resume the GET stream where it was interrupted, if the server
+		// supports it
+		if lastEventID := c.lastEventID.Load().(string); lastEventID != "" {
+			req.Header.Set(headerKeyLastEventID, lastEventID)
+		}
+	}
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
@@ -392,12 +542,28 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 		// only close responseChan after readingSSE()
 		defer close(responseChan)
 
-		c.readSSE(ctx, reader, func(event, data string) {
+		c.readSSE(ctx, reader, func(event, data, id string) {
 			// (unsupported: batching)
 
+			if ignoreResponse && id != "" {
+				// remember where the listening stream is, to resume it
+				c.lastEventID.Store(id)
+			}
+
+			// Handle request from the server
+			if isRequest([]byte(data)) {
+				var request JSONRPCRequest
+				if err := json.Unmarshal([]byte(data), &request); err != nil {
+					c.logger.Error("failed to unmarshal request", "error", err)
+					return
+				}
+				c.handleIncomingRequest(request)
+				return
+			}
+
 			var message JSONRPCResponse
 			if err := json.Unmarshal([]byte(data), &message); err != nil {
-				c.logger.Errorf("failed to unmarshal message: %v", err)
+				c.logger.Error("failed to unmarshal message", "error", err)
 				return
 			}
 
@@ -405,7 +571,7 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 			if message.ID.IsNil() {
 				var notification mcp.JSONRPCNotification
 				if err := json.Unmarshal([]byte(data), &notification); err != nil {
-					c.logger.Errorf("failed to unmarshal notification: %v", err)
+					c.logger.Error("failed to unmarshal notification", "error", err)
 					return
 				}
 				c.notifyMu.RLock()
@@ -434,13 +600,14 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 	}
 }
 
-// readSSE reads the SSE stream(reader) and calls the handler for each event and data pair.
+// readSSE reads the SSE stream(reader) and calls the handler for each event and data pair,
+// along with the last event ID received on the stream.
 // It will end when the reader is closed (or the context is done).
-func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, handler func(event, data string)) {
+func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, handler func(event, data, id string)) {
 	defer reader.Close()
 
 	br := bufio.NewReader(reader)
-	var event, data string
+	var event, data, id string
 
 	for {
 		select {
@@ -456,7 +623,7 @@ func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, hand
 						if event == "" {
 							event = "message"
 						}
-						handler(event, data)
+						handler(event, data, id)
 					}
 					return
 				}
@@ -464,7 +631,7 @@ func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, hand
 				case <-ctx.Done():
 					return
 				default:
-					c.logger.Errorf("SSE stream error: %v", err)
+					c.logger.Error("SSE stream error", "error", err)
 					return
 				}
 			}
@@ -478,7 +645,7 @@ func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, hand
 					if event == "" {
 						event = "message"
 					}
-					handler(event, data)
+					handler(event, data, id)
 					event = ""
 					data = ""
 				}
@@ -487,6 +654,8 @@ func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, hand
 
 			if strings.HasPrefix(line, "event:") {
 				event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			} else if strings.HasPrefix(line, "id:") {
+				id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
 			} else if strings.HasPrefix(line, "data:") {
 				data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
 			}
@@ -536,10 +705,60 @@ func (c *StreamableHTTP) SetNotificationHandler(handler func(mcp.JSONRPCNotifica
 	c.notificationHandler = handler
 }
 
+// SetRequestHandler sets the handler for requests sent by the server on the
+// SSE streams, such as sampling or roots requests.
+func (c *StreamableHTTP) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.requestHandler = handler
+}
+
+// handleIncomingRequest handles a request of the server in the background and
+// posts the response back to the server.
+func (c *StreamableHTTP) handleIncomingRequest(request JSONRPCRequest) {
+	c.requestMu.RLock()
+	handler := c.requestHandler
+	c.requestMu.RUnlock()
+
+	go func() {
+		ctx, cancel := c.contextAwareOfClientClose(context.Background())
+		defer cancel()
+
+		response := handleServerRequest(ctx, handler, request)
+		if response == nil {
+			return
+		}
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			c.logger.Error("failed to marshal response", "error", err)
+			return
+		}
+		resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(responseBody), "application/json, text/event-stream")
+		if err != nil {
+			c.logger.Error("failed to send response", "error", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+			c.logger.Error("response to server request failed", "method", request.Method, "status", resp.StatusCode)
+		}
+	}()
+}
+
+var _ BidirectionalInterface = (*StreamableHTTP)(nil)
+
 func (c *StreamableHTTP) GetSessionId() string {
 	return c.sessionID.Load().(string)
 }
 
+// SetProtocolVersion sets the protocol version negotiated with the server,
+// sent in the MCP-Protocol-Version header of subsequent requests.
+func (c *StreamableHTTP) SetProtocolVersion(version string) {
+	c.protocolVersion.Store(version)
+}
+
+var _ ProtocolVersionInterface = (*StreamableHTTP)(nil)
+
 // GetOAuthHandler returns the OAuth handler if configured
 func (c *StreamableHTTP) GetOAuthHandler() *OAuthHandler {
 	return c.oauthHandler
@@ -551,12 +770,12 @@ func (c *StreamableHTTP) IsOAuthEnabled() bool {
 }
 
 func (c *StreamableHTTP) listenForever(ctx context.Context) {
-	c.logger.Infof("listening to server forever")
+	c.logger.Debug("listening to server forever")
 	for {
 		err := c.createGETConnectionToServer(ctx)
 		if errors.Is(err, ErrGetMethodNotAllowed) {
 			// server does not support listening
-			c.logger.Errorf("server does not support listening")
+			c.logger.Warn("server does not support listening")
 			return
 		}
 
@@ -567,7 +786,7 @@ func (c *StreamableHTTP) listenForever(ctx context.Context) {
 		}
 
 		if err != nil {
-			c.logger.Errorf("failed to listen to server. retry in 1 second: %v", err)
+			c.logger.Error("failed to listen to server, retrying", "retryInterval", retryInterval, "error", err)
 		}
 		time.Sleep(retryInterval)
 	}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStreamableHTTP_ResumesListeningStream(t *testing.T) {
+	retryInterval = 10 * time.Millisecond
+
+	lastEventIDs := make(chan string, 10)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(headerKeySessionID, "test-session")
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":0,"result":{}}`)
+		case http.MethodGet:
+			lastEventIDs <- r.Header.Get(headerKeyLastEventID)
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			// an event with an ID, then a heartbeat without one, then
+			// the connection drops
+			fmt.Fprint(w, "id: event-1\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"test/notification\"}\n\n")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"ping\"}\n\n")
+			w.(http.Flusher).Flush()
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL, WithContinuousListening())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trans.Close()
+	if err := trans.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(0)),
+		Method:  "initialize",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"", "event-1"} {
+		select {
+		case got := <-lastEventIDs:
+			if got != want {
+				t.Errorf("Connection %d: expected Last-Event-ID %q, got %q", i, want, got)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timed out waiting for connection %d", i)
+		}
+	}
+}
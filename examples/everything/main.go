@@ -415,28 +415,20 @@ func handleLongRunningOperationTool(
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
 	arguments := request.GetArguments()
-	progressToken := request.Params.Meta.ProgressToken
 	duration, _ := arguments["duration"].(float64)
 	steps, _ := arguments["steps"].(float64)
 	stepDuration := duration / steps
-	server := server.ServerFromContext(ctx)
+	progress := server.ProgressReporterFromContext(ctx)
 
 	for i := 1; i < int(steps)+1; i++ {
 		time.Sleep(time.Duration(stepDuration * float64(time.Second)))
-		if progressToken != nil {
-			err := server.SendNotificationToClient(
-				ctx,
-				"notifications/progress",
-				map[string]any{
-					"progress":      i,
-					"total":         int(steps),
-					"progressToken": progressToken,
-					"message":       fmt.Sprintf("Server progress %v%%", int(float64(i)*100/steps)),
-				},
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to send notification: %w", err)
-			}
+		err := progress.Report(
+			float64(i),
+			steps,
+			fmt.Sprintf("Server progress %v%%", int(float64(i)*100/steps)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send notification: %w", err)
 		}
 	}
 
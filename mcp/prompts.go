@@ -49,6 +49,9 @@ type GetPromptResult struct {
 type Prompt struct {
 	// The name of the prompt or prompt template.
 	Name string `json:"name"`
+	// A human-readable name for display purposes, since protocol version
+	// 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// An optional description of what this prompt provides
 	Description string `json:"description,omitempty"`
 	// A list of arguments to use for templating the prompt.
@@ -134,6 +137,13 @@ func WithPromptDescription(description string) PromptOption {
 	}
 }
 
+// WithPromptTitle sets the display title of the Prompt.
+func WithPromptTitle(title string) PromptOption {
+	return func(p *Prompt) {
+		p.Title = title
+	}
+}
+
 // WithArgument adds an argument to the prompt's argument list.
 // The argument will be configured based on the provided options.
 func WithArgument(name string, opts ...ArgumentOption) PromptOption {
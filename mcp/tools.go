@@ -9,7 +9,10 @@ import (
 	"strconv"
 )
 
-var errToolSchemaConflict = errors.New("provide either InputSchema or RawInputSchema, not both")
+var (
+	errToolSchemaConflict       = errors.New("provide either InputSchema or RawInputSchema, not both")
+	errToolOutputSchemaConflict = errors.New("provide either OutputSchema or RawOutputSchema, not both")
+)
 
 // ListToolsRequest is sent from the client to request a list of tools the
 // server has.
@@ -42,6 +45,11 @@ type CallToolResult struct {
 	//
 	// If not set, this is assumed to be false (the call was successful).
 	IsError bool `json:"isError,omitempty"`
+	// Structured result of the tool call, conforming to the tool's output
+	// schema if it declares one. Tools returning structured content should
+	// also return its JSON serialization as a text content block, for
+	// clients that do not support structured content.
+	StructuredContent any `json:"structuredContent,omitempty"`
 }
 
 // CallToolRequest is used by the client to invoke a tool provided by the server.
@@ -467,24 +475,29 @@ func (r CallToolRequest) RequireBoolSlice(key string) ([]bool, error) {
 // MarshalJSON implements custom JSON marshaling for CallToolResult
 func (r CallToolResult) MarshalJSON() ([]byte, error) {
 	m := make(map[string]any)
-	
+
 	// Marshal Meta if present
 	if r.Meta != nil {
 		m["_meta"] = r.Meta
 	}
-	
+
 	// Marshal Content array
 	content := make([]any, len(r.Content))
 	for i, c := range r.Content {
 		content[i] = c
 	}
 	m["content"] = content
-	
+
 	// Marshal IsError if true
 	if r.IsError {
 		m["isError"] = r.IsError
 	}
-	
+
+	// Marshal StructuredContent if present
+	if r.StructuredContent != nil {
+		m["structuredContent"] = r.StructuredContent
+	}
+
 	return json.Marshal(m)
 }
 
@@ -494,14 +507,14 @@ func (r *CallToolResult) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
 	}
-	
+
 	// Unmarshal Meta
 	if meta, ok := raw["_meta"]; ok {
 		if metaMap, ok := meta.(map[string]any); ok {
 			r.Meta = metaMap
 		}
 	}
-	
+
 	// Unmarshal Content array
 	if contentRaw, ok := raw["content"]; ok {
 		if contentArray, ok := contentRaw.([]any); ok {
@@ -519,14 +532,19 @@ func (r *CallToolResult) UnmarshalJSON(data []byte) error {
 			}
 		}
 	}
-	
+
 	// Unmarshal IsError
 	if isError, ok := raw["isError"]; ok {
 		if isErrorBool, ok := isError.(bool); ok {
 			r.IsError = isErrorBool
 		}
 	}
-	
+
+	// Unmarshal StructuredContent
+	if structuredContent, ok := raw["structuredContent"]; ok {
+		r.StructuredContent = structuredContent
+	}
+
 	return nil
 }
 
@@ -541,12 +559,20 @@ type ToolListChangedNotification struct {
 type Tool struct {
 	// The name of the tool.
 	Name string `json:"name"`
+	// A human-readable name for display purposes, since protocol version
+	// 2025-06-18. Takes precedence over the title annotation.
+	Title string `json:"title,omitempty"`
 	// A human-readable description of the tool.
 	Description string `json:"description,omitempty"`
 	// A JSON Schema object defining the expected parameters for the tool.
 	InputSchema ToolInputSchema `json:"inputSchema"`
 	// Alternative to InputSchema - allows arbitrary JSON Schema to be provided
 	RawInputSchema json.RawMessage `json:"-"` // Hide this from JSON marshaling
+	// An optional JSON Schema object defining the structure of the tool's
+	// structured content.
+	OutputSchema ToolOutputSchema `json:"outputSchema,omitempty"`
+	// Alternative to OutputSchema - allows arbitrary JSON Schema to be provided
+	RawOutputSchema json.RawMessage `json:"-"` // Hide this from JSON marshaling
 	// Optional properties describing tool behavior
 	Annotations ToolAnnotation `json:"annotations"`
 }
@@ -562,8 +588,11 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 	// Create a map to build the JSON structure
 	m := make(map[string]any, 3)
 
-	// Add the name and description
+	// Add the name, title and description
 	m["name"] = t.Name
+	if t.Title != "" {
+		m["title"] = t.Title
+	}
 	if t.Description != "" {
 		m["description"] = t.Description
 	}
@@ -579,6 +608,16 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 		m["inputSchema"] = t.InputSchema
 	}
 
+	// The output schema is optional
+	if t.RawOutputSchema != nil {
+		if t.OutputSchema.Type != "" {
+			return nil, fmt.Errorf("tool %s has both OutputSchema and RawOutputSchema set: %w", t.Name, errToolOutputSchemaConflict)
+		}
+		m["outputSchema"] = t.RawOutputSchema
+	} else if t.OutputSchema.Type != "" {
+		m["outputSchema"] = t.OutputSchema
+	}
+
 	m["annotations"] = t.Annotations
 
 	return json.Marshal(m)
@@ -607,6 +646,44 @@ func (tis ToolInputSchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// ToolOutputSchema describes the structured content returned by a tool. Its
+// type must be "object".
+type ToolOutputSchema ToolInputSchema
+
+// MarshalJSON implements the json.Marshaler interface for ToolOutputSchema.
+func (tos ToolOutputSchema) MarshalJSON() ([]byte, error) {
+	return ToolInputSchema(tos).MarshalJSON()
+}
+
+// GetInputSchema returns the input schema of the tool, either the raw or the
+// structured one, or nil if the tool does not declare any.
+func (t Tool) GetInputSchema() any {
+	if t.RawInputSchema != nil {
+		return t.RawInputSchema
+	}
+	if t.InputSchema.Type != "" {
+		return t.InputSchema
+	}
+	return nil
+}
+
+// HasOutputSchema reports whether the tool declares an output schema.
+func (t Tool) HasOutputSchema() bool {
+	return t.RawOutputSchema != nil || t.OutputSchema.Type != ""
+}
+
+// GetOutputSchema returns the output schema of the tool, either the raw or
+// the structured one, or nil if the tool does not declare any.
+func (t Tool) GetOutputSchema() any {
+	if t.RawOutputSchema != nil {
+		return t.RawOutputSchema
+	}
+	if t.OutputSchema.Type != "" {
+		return t.OutputSchema
+	}
+	return nil
+}
+
 type ToolAnnotation struct {
 	// Human-readable title for the tool
 	Title string `json:"title,omitempty"`
@@ -684,6 +761,32 @@ func WithDescription(description string) ToolOption {
 	}
 }
 
+// WithOutputSchema sets the output schema of the Tool, describing the
+// structured content of its results. The schema type defaults to "object".
+func WithOutputSchema(schema ToolOutputSchema) ToolOption {
+	return func(t *Tool) {
+		if schema.Type == "" {
+			schema.Type = "object"
+		}
+		t.OutputSchema = schema
+	}
+}
+
+// WithRawOutputSchema sets the output schema of the Tool to an arbitrary JSON
+// Schema, describing the structured content of its results.
+func WithRawOutputSchema(schema json.RawMessage) ToolOption {
+	return func(t *Tool) {
+		t.RawOutputSchema = schema
+	}
+}
+
+// WithToolTitle sets the display title of the Tool.
+func WithToolTitle(title string) ToolOption {
+	return func(t *Tool) {
+		t.Title = title
+	}
+}
+
 // WithToolAnnotation adds optional hints about the Tool.
 func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
 	return func(t *Tool) {
@@ -712,3 +712,99 @@ func TestNewItemsAPICompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestToolWithOutputSchema(t *testing.T) {
+	tool := NewTool("weather",
+		WithString("city", Required()),
+		WithOutputSchema(ToolOutputSchema{
+			Properties: map[string]any{
+				"temperature": map[string]any{"type": "number"},
+			},
+			Required: []string{"temperature"},
+		}),
+	)
+	assert.True(t, tool.HasOutputSchema())
+
+	data, err := json.Marshal(tool)
+	assert.NoError(t, err)
+
+	var result map[string]any
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"temperature": map[string]any{"type": "number"},
+		},
+		"required": []any{"temperature"},
+	}, result["outputSchema"])
+
+	var unmarshalled Tool
+	assert.NoError(t, json.Unmarshal(data, &unmarshalled))
+	assert.Equal(t, "object", unmarshalled.OutputSchema.Type)
+	assert.Equal(t, []string{"temperature"}, unmarshalled.OutputSchema.Required)
+
+	// Tools without output schema do not marshal one
+	data, err = json.Marshal(NewTool("plain"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "outputSchema")
+	assert.False(t, NewTool("plain").HasOutputSchema())
+}
+
+func TestToolWithRawOutputSchema(t *testing.T) {
+	rawSchema := json.RawMessage(`{"type":"object","properties":{"count":{"type":"integer"}}}`)
+	tool := NewTool("count", WithRawOutputSchema(rawSchema))
+
+	data, err := json.Marshal(tool)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"outputSchema":{"type":"object","properties":{"count":{"type":"integer"}}}`)
+	assert.Equal(t, rawSchema, tool.GetOutputSchema())
+
+	tool.OutputSchema = ToolOutputSchema{Type: "object"}
+	_, err = json.Marshal(tool)
+	assert.ErrorIs(t, err, errToolOutputSchemaConflict)
+}
+
+func TestCallToolResultStructuredContent(t *testing.T) {
+	result := NewToolResultStructured(map[string]any{"temperature": 21.5})
+	assert.Equal(t, map[string]any{"temperature": 21.5}, result.StructuredContent)
+	assert.Equal(t, `{"temperature":21.5}`, result.Content[0].(TextContent).Text)
+
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"content": [{"type": "text", "text": "{\"temperature\":21.5}"}],
+		"structuredContent": {"temperature": 21.5}
+	}`, string(data))
+
+	var unmarshalled CallToolResult
+	assert.NoError(t, json.Unmarshal(data, &unmarshalled))
+	assert.Equal(t, map[string]any{"temperature": 21.5}, unmarshalled.StructuredContent)
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"temperature": 21.5}, parsed.StructuredContent)
+	assert.Len(t, parsed.Content, 1)
+
+	// Results without structured content do not marshal it
+	data, err = json.Marshal(NewToolResultText("plain"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "structuredContent")
+}
+
+func TestToolWithTitle(t *testing.T) {
+	tool := NewTool("weather", WithToolTitle("Weather"))
+
+	data, err := json.Marshal(tool)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"title":"Weather"`)
+
+	var unmarshalled Tool
+	assert.NoError(t, json.Unmarshal(data, &unmarshalled))
+	assert.Equal(t, "Weather", unmarshalled.Title)
+
+	// Tools without a title do not marshal it
+	data, err = json.Marshal(NewTool("weather"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "title")
+}
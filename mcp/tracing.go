@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The W3C Trace Context fields of the _meta of a request, which carry the
+// trace of the sender of the request to its receiver.
+const (
+	TraceParentMetaKey = "traceparent"
+	TraceStateMetaKey  = "tracestate"
+)
+
+// TracePropagator propagates the trace context of the requests between the
+// client and the server, such as an OpenTelemetry W3C Trace Context propagator.
+type TracePropagator interface {
+	// Inject sets the trace context fields of the span of ctx, if any.
+	Inject(ctx context.Context, fields map[string]string)
+	// Extract returns a copy of ctx with the span of the trace context fields
+	// as its remote parent.
+	Extract(ctx context.Context, fields map[string]string) context.Context
+}
+
+// InjectTraceContext returns the params of a request with the trace context
+// of ctx added to their _meta. The params are returned unchanged if ctx has
+// no trace context.
+func InjectTraceContext(ctx context.Context, propagator TracePropagator, params any) (any, error) {
+	fields := make(map[string]string)
+	propagator.Inject(ctx, fields)
+	if len(fields) == 0 {
+		return params, nil
+	}
+
+	object := make(map[string]any)
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		// Numbers are kept as they are rather than converted to float64
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("params are not an object: %w", err)
+		}
+		if object == nil {
+			object = make(map[string]any)
+		}
+	}
+
+	meta, _ := object["_meta"].(map[string]any)
+	if meta == nil {
+		meta = make(map[string]any)
+	}
+	for key, value := range fields {
+		meta[key] = value
+	}
+	object["_meta"] = meta
+	return object, nil
+}
+
+// ExtractTraceContext returns a copy of ctx with the trace context of the
+// _meta of the params of a request, or ctx if they have none.
+func ExtractTraceContext(ctx context.Context, propagator TracePropagator, params json.RawMessage) context.Context {
+	var request struct {
+		Meta map[string]any `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &request) != nil {
+		return ctx
+	}
+	fields := make(map[string]string)
+	for _, key := range []string{TraceParentMetaKey, TraceStateMetaKey} {
+		if value, ok := request.Meta[key].(string); ok {
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, fields)
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type traceParentKey struct{}
+
+// contextPropagator propagates the traceparent stored in the context
+type contextPropagator struct{}
+
+func (contextPropagator) Inject(ctx context.Context, fields map[string]string) {
+	if traceParent, ok := ctx.Value(traceParentKey{}).(string); ok {
+		fields[TraceParentMetaKey] = traceParent
+	}
+}
+
+func (contextPropagator) Extract(ctx context.Context, fields map[string]string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, fields[TraceParentMetaKey])
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := context.WithValue(context.Background(), traceParentKey{}, traceParent)
+
+	params := CallToolParams{
+		Name:      "search",
+		Arguments: map[string]any{"limit": 9007199254740993},
+		Meta:      &Meta{ProgressToken: "token"},
+	}
+	injected, err := InjectTraceContext(ctx, contextPropagator{}, params)
+	require.NoError(t, err)
+	data, err := json.Marshal(injected)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "search",
+		"arguments": {"limit": 9007199254740993},
+		"_meta": {"progressToken": "token", "traceparent": "`+traceParent+`"}
+	}`, string(data))
+
+	injected, err = InjectTraceContext(ctx, contextPropagator{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"_meta": map[string]any{TraceParentMetaKey: traceParent}}, injected)
+
+	_, err = InjectTraceContext(ctx, contextPropagator{}, []string{"not", "an", "object"})
+	assert.Error(t, err)
+
+	// without a trace context the params are left alone
+	injected, err = InjectTraceContext(context.Background(), contextPropagator{}, params)
+	require.NoError(t, err)
+	assert.Equal(t, params, injected)
+}
+
+func TestExtractTraceContext(t *testing.T) {
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ExtractTraceContext(context.Background(), contextPropagator{},
+		json.RawMessage(`{"name":"search","_meta":{"traceparent":"`+traceParent+`"}}`))
+	assert.Equal(t, traceParent, ctx.Value(traceParentKey{}))
+
+	for _, params := range []string{``, `{"name":"search"}`, `{"_meta":{"traceparent":1}}`, `[1]`} {
+		ctx := ExtractTraceContext(context.Background(), contextPropagator{}, json.RawMessage(params))
+		assert.Nil(t, ctx.Value(traceParentKey{}), params)
+	}
+}
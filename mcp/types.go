@@ -35,6 +35,14 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
 	MethodResourcesRead MCPMethod = "resources/read"
 
+	// MethodResourcesSubscribe subscribes to update notifications for a specific resource.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesSubscribe MCPMethod = "resources/subscribe"
+
+	// MethodResourcesUnsubscribe cancels a previous resource subscription.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesUnsubscribe MCPMethod = "resources/unsubscribe"
+
 	// MethodPromptsList lists all available prompt templates.
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/prompts/
 	MethodPromptsList MCPMethod = "prompts/list"
@@ -51,14 +59,30 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/tools/
 	MethodToolsCall MCPMethod = "tools/call"
 
+	// MethodCompletionComplete requests completion options for a prompt or
+	// resource template argument.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/completion
+	MethodCompletionComplete MCPMethod = "completion/complete"
+
 	// MethodSetLogLevel configures the minimum log level for client
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/logging
 	MethodSetLogLevel MCPMethod = "logging/setLevel"
 
+	// MethodNotificationCancelled notifies the receiver that a previously
+	// issued request has been cancelled.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/cancellation
+	MethodNotificationCancelled = "notifications/cancelled"
+
+	// MethodNotificationProgress reports progress of a long-running request.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/progress
+	MethodNotificationProgress = "notifications/progress"
+
 	// MethodNotificationResourcesListChanged notifies when the list of available resources changes.
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#list-changed-notification
 	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
 
+	// MethodNotificationResourceUpdated notifies subscribed clients that a resource has changed.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
 	MethodNotificationResourceUpdated = "notifications/resources/updated"
 
 	// MethodNotificationPromptsListChanged notifies when the list of available prompt templates changes.
@@ -96,15 +120,38 @@ func (t *URITemplate) UnmarshalJSON(data []byte) error {
 // JSONRPCMessage represents either a JSONRPCRequest, JSONRPCNotification, JSONRPCResponse, or JSONRPCError
 type JSONRPCMessage any
 
+// JSONRPCBatchResponse is the reply to a batch of JSON-RPC messages: one
+// JSONRPCResponse or JSONRPCError for each request of the batch. Notifications
+// get no response.
+type JSONRPCBatchResponse []JSONRPCMessage
+
+// Known versions of the MCP protocol. Versions are dates, so that they can be
+// ordered by comparing them as strings.
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+)
+
 // LATEST_PROTOCOL_VERSION is the most recent version of the MCP protocol.
-const LATEST_PROTOCOL_VERSION = "2025-03-26"
+const LATEST_PROTOCOL_VERSION = ProtocolVersion20250618
 
 // ValidProtocolVersions lists all known valid MCP protocol versions.
 var ValidProtocolVersions = []string{
-	"2024-11-05",
+	ProtocolVersion20241105,
+	ProtocolVersion20250326,
 	LATEST_PROTOCOL_VERSION,
 }
 
+// ProtocolVersionAtLeast reports whether version is the same as, or more
+// recent than, minimum. An empty version stands for the latest version.
+func ProtocolVersionAtLeast(version, minimum string) bool {
+	if version == "" {
+		return true
+	}
+	return version >= minimum
+}
+
 // JSONRPC_VERSION is the version of JSON-RPC used by MCP.
 const JSONRPC_VERSION = "2.0"
 
@@ -114,6 +161,11 @@ type ProgressToken any
 // Cursor is an opaque token used to represent a cursor for pagination.
 type Cursor string
 
+// TimeoutMetaKey is the field of the request metadata holding the number of
+// milliseconds the sender waits for the response. The receiver may give up on
+// the request after that.
+const TimeoutMetaKey = "timeoutMs"
+
 // Meta is metadata attached to a request's parameters. This can include fields
 // formally defined by the protocol or other arbitrary data.
 type Meta struct {
@@ -358,6 +410,23 @@ const (
 	RESOURCE_NOT_FOUND = -32002
 )
 
+// Implementation-defined error codes
+const (
+	// REQUEST_TIMEOUT is returned for the requests that did not complete in time.
+	REQUEST_TIMEOUT = -32001
+	// RATE_LIMIT_EXCEEDED is returned by servers for the requests exceeding
+	// one of their rate limits. The data of the error is a RateLimitErrorData.
+	RATE_LIMIT_EXCEEDED = -32029
+)
+
+// RateLimitErrorData is the data of a RATE_LIMIT_EXCEEDED error.
+type RateLimitErrorData struct {
+	// Limit is the name of the exceeded limit.
+	Limit string `json:"limit"`
+	// RetryAfter is the number of seconds after which the request may succeed.
+	RetryAfter float64 `json:"retryAfter"`
+}
+
 /* Empty result */
 
 // EmptyResult represents a response that indicates success but carries no data.
@@ -448,6 +517,8 @@ type ClientCapabilities struct {
 	} `json:"roots,omitempty"`
 	// Present if the client supports sampling from an LLM.
 	Sampling *struct{} `json:"sampling,omitempty"`
+	// Present if the client supports elicitation requests from the server.
+	Elicitation *struct{} `json:"elicitation,omitempty"`
 }
 
 // ServerCapabilities represents capabilities that a server may support. Known
@@ -458,6 +529,8 @@ type ServerCapabilities struct {
 	Experimental map[string]any `json:"experimental,omitempty"`
 	// Present if the server supports sending log messages to the client.
 	Logging *struct{} `json:"logging,omitempty"`
+	// Present if the server supports argument autocompletion suggestions.
+	Completions *struct{} `json:"completions,omitempty"`
 	// Present if the server offers any prompt templates.
 	Prompts *struct {
 		// Whether this server supports notifications for changes to the prompt list.
@@ -480,7 +553,10 @@ type ServerCapabilities struct {
 
 // Implementation describes the name and version of an MCP implementation.
 type Implementation struct {
-	Name    string `json:"name"`
+	Name string `json:"name"`
+	// A human-readable name for display purposes, since protocol version
+	// 2025-06-18.
+	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
 }
 
@@ -1017,6 +1093,13 @@ type CompleteResult struct {
 	} `json:"completion"`
 }
 
+const (
+	// RefTypePrompt is the reference type used to complete prompt arguments.
+	RefTypePrompt = "ref/prompt"
+	// RefTypeResource is the reference type used to complete resource template arguments.
+	RefTypeResource = "ref/resource"
+)
+
 // ResourceReference is a reference to a resource or resource template definition.
 type ResourceReference struct {
 	Type string `json:"type"`
@@ -1033,6 +1116,16 @@ type PromptReference struct {
 
 /* Roots */
 
+const (
+	// MethodListRoots allows servers to request the list of roots exposed by the client.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots
+	MethodListRoots MCPMethod = "roots/list"
+
+	// MethodNotificationRootsListChanged notifies the server that the list of roots of the client has changed.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots#root-list-changes
+	MethodNotificationRootsListChanged = "notifications/roots/list_changed"
+)
+
 // ListRootsRequest is sent from the server to request a list of root URIs from the client. Roots allow
 // servers to ask for specific directories or files to operate on. A common example
 // for roots is providing a set of repositories or directories a server should operate
@@ -1073,6 +1166,66 @@ type RootsListChangedNotification struct {
 	Notification
 }
 
+/* Elicitation */
+
+const (
+	// MethodElicitationCreate allows servers to request additional information from the user via the client.
+	// https://modelcontextprotocol.io/specification/2025-06-18/client/elicitation
+	MethodElicitationCreate MCPMethod = "elicitation/create"
+)
+
+// ElicitationRequest is a request from the server to the client, asking the
+// user for structured input. The client should present the message and a form
+// matching the requested schema, and let the user accept, decline or cancel.
+type ElicitationRequest struct {
+	Request
+	Params ElicitationParams `json:"params"`
+}
+
+// ElicitationParams contains the parameters of an elicitation request.
+type ElicitationParams struct {
+	// The message to present to the user.
+	Message string `json:"message"`
+	// A restricted subset of JSON Schema describing the expected response.
+	// Only top-level properties of primitive types are allowed.
+	RequestedSchema any `json:"requestedSchema"`
+}
+
+// ElicitationResponseAction is the action taken by the user in response to an
+// elicitation request.
+type ElicitationResponseAction string
+
+const (
+	// ElicitationResponseActionAccept means the user submitted the requested data.
+	ElicitationResponseActionAccept ElicitationResponseAction = "accept"
+	// ElicitationResponseActionDecline means the user explicitly declined the request.
+	ElicitationResponseActionDecline ElicitationResponseAction = "decline"
+	// ElicitationResponseActionCancel means the user dismissed the request
+	// without making an explicit choice.
+	ElicitationResponseActionCancel ElicitationResponseAction = "cancel"
+)
+
+// ElicitationResult is the client's response to an elicitation/create request.
+type ElicitationResult struct {
+	Result
+	// The action taken by the user.
+	Action ElicitationResponseAction `json:"action"`
+	// The submitted data, matching the requested schema. Only present when
+	// the action is accept.
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// BindContent unmarshals the submitted data into target, which is typically
+// a pointer to a struct matching the requested schema.
+func (r ElicitationResult) BindContent(target any) error {
+	data, err := json.Marshal(r.Content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content: %w", err)
+	}
+
+	return json.Unmarshal(data, target)
+}
+
 // ClientRequest represents any request that can be sent from client to server.
 type ClientRequest any
 
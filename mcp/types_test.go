@@ -138,3 +138,27 @@ func TestCallToolResultWithResourceLink(t *testing.T) {
 	assert.Equal(t, "A test document", resourceLink.Description)
 	assert.Equal(t, "application/pdf", resourceLink.MIMEType)
 }
+
+func TestElicitationResultBindContent(t *testing.T) {
+	var result ElicitationResult
+	err := json.Unmarshal([]byte(`{"action":"accept","content":{"environment":"staging","replicas":3}}`), &result)
+	require.NoError(t, err)
+	assert.Equal(t, ElicitationResponseActionAccept, result.Action)
+
+	var deployment struct {
+		Environment string `json:"environment"`
+		Replicas    int    `json:"replicas"`
+	}
+	require.NoError(t, result.BindContent(&deployment))
+	assert.Equal(t, "staging", deployment.Environment)
+	assert.Equal(t, 3, deployment.Replicas)
+}
+
+func TestProtocolVersionAtLeast(t *testing.T) {
+	assert.True(t, ProtocolVersionAtLeast(ProtocolVersion20250618, ProtocolVersion20250618))
+	assert.True(t, ProtocolVersionAtLeast(ProtocolVersion20250618, ProtocolVersion20250326))
+	assert.False(t, ProtocolVersionAtLeast(ProtocolVersion20241105, ProtocolVersion20250326))
+	// An unknown version is the latest one
+	assert.True(t, ProtocolVersionAtLeast("", ProtocolVersion20250618))
+	assert.Equal(t, ProtocolVersion20250618, LATEST_PROTOCOL_VERSION)
+}
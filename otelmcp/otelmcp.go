@@ -0,0 +1,157 @@
+// Package otelmcp adapts OpenTelemetry to the tracing of MCP clients and
+// servers. Its Tracer traces the requests handled by a server, and its
+// Propagator carries the W3C trace context of the requests of a client:
+//
+//	mcpServer := server.NewMCPServer("example", "1.0.0", server.WithTracer(otelmcp.NewTracer()))
+//	mcpClient := client.NewClient(transport, client.WithTracePropagator(otelmcp.NewPropagator()))
+package otelmcp
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ScopeName is the instrumentation scope of the spans of the Tracer.
+const ScopeName = "github.com/mark3labs/mcp-go/otelmcp"
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures a Tracer or a Propagator.
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, the global one by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator of the trace context, the W3C Trace
+// Context propagator by default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{propagator: propagation.TraceContext{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	return c
+}
+
+// Propagator carries the trace context of the requests in their _meta. It
+// implements mcp.TracePropagator.
+type Propagator struct {
+	propagator propagation.TextMapPropagator
+}
+
+// NewPropagator returns a Propagator for client.WithTracePropagator.
+func NewPropagator(opts ...Option) *Propagator {
+	return &Propagator{propagator: newConfig(opts).propagator}
+}
+
+// Inject sets the trace context fields of the span of ctx.
+func (p *Propagator) Inject(ctx context.Context, fields map[string]string) {
+	p.propagator.Inject(ctx, propagation.MapCarrier(fields))
+}
+
+// Extract returns a copy of ctx with the remote span of the fields.
+func (p *Propagator) Extract(ctx context.Context, fields map[string]string) context.Context {
+	return p.propagator.Extract(ctx, propagation.MapCarrier(fields))
+}
+
+// Tracer traces the requests of a server with OpenTelemetry. It implements
+// server.Tracer.
+type Tracer struct {
+	Propagator
+	tracer trace.Tracer
+}
+
+var _ server.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a Tracer for server.WithTracer.
+func NewTracer(opts ...Option) *Tracer {
+	c := newConfig(opts)
+	return &Tracer{
+		Propagator: Propagator{propagator: c.propagator},
+		tracer:     c.tracerProvider.Tracer(ScopeName),
+	}
+}
+
+// Start starts an OpenTelemetry span.
+func (t *Tracer) Start(ctx context.Context, name string, kind server.SpanKind, attributes map[string]any) (context.Context, server.Span) {
+	ctx, s := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(spanKind(kind)),
+		trace.WithAttributes(keyValues(attributes)...),
+	)
+	return ctx, span{s}
+}
+
+func spanKind(kind server.SpanKind) trace.SpanKind {
+	switch kind {
+	case server.SpanKindServer:
+		return trace.SpanKindServer
+	case server.SpanKindClient:
+		return trace.SpanKindClient
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+// span adapts an OpenTelemetry span to server.Span
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetAttributes(attributes map[string]any) {
+	s.span.SetAttributes(keyValues(attributes)...)
+}
+
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.span.End()
+}
+
+func keyValues(attributes map[string]any) []attribute.KeyValue {
+	keyValues := make([]attribute.KeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		switch value := value.(type) {
+		case string:
+			keyValues = append(keyValues, attribute.String(key, value))
+		case bool:
+			keyValues = append(keyValues, attribute.Bool(key, value))
+		case int:
+			keyValues = append(keyValues, attribute.Int(key, value))
+		case int64:
+			keyValues = append(keyValues, attribute.Int64(key, value))
+		case float64:
+			keyValues = append(keyValues, attribute.Float64(key, value))
+		case []string:
+			keyValues = append(keyValues, attribute.StringSlice(key, value))
+		default:
+			keyValues = append(keyValues, attribute.String(key, fmt.Sprint(value)))
+		}
+	}
+	return keyValues
+}
@@ -0,0 +1,89 @@
+package otelmcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func newRecordedTracer() (*Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return NewTracer(WithTracerProvider(provider)), recorder
+}
+
+func spanNamed(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestPropagator(t *testing.T) {
+	propagator := NewPropagator()
+	ctx := propagator.Extract(context.Background(), map[string]string{
+		mcp.TraceParentMetaKey: traceParent,
+		mcp.TraceStateMetaKey:  "vendor=value",
+	})
+	spanContext := trace.SpanContextFromContext(ctx)
+	require.True(t, spanContext.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+
+	fields := make(map[string]string)
+	propagator.Inject(ctx, fields)
+	assert.Equal(t, map[string]string{
+		mcp.TraceParentMetaKey: traceParent,
+		mcp.TraceStateMetaKey:  "vendor=value",
+	}, fields)
+
+	fields = make(map[string]string)
+	propagator.Inject(context.Background(), fields)
+	assert.Empty(t, fields)
+}
+
+func TestTracer(t *testing.T) {
+	tracer, recorder := newRecordedTracer()
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithTracer(tracer))
+	mcpServer.AddTool(mcp.NewTool("search"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("index unavailable")
+	})
+
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{
+		"name":"search","_meta":{"traceparent":"`+traceParent+`"}}}`))
+	_, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok)
+
+	spans := recorder.Ended()
+	request := spanNamed(spans, "tools/call search")
+	require.NotNil(t, request)
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+	assert.Contains(t, request.Attributes(), attribute.String("gen_ai.tool.name", "search"))
+	assert.Contains(t, request.Attributes(), attribute.Int("rpc.jsonrpc.error_code", mcp.INTERNAL_ERROR))
+	assert.Equal(t, codes.Error, request.Status().Code)
+	assert.Equal(t, ScopeName, request.InstrumentationScope().Name)
+
+	handler := spanNamed(spans, "tool handler")
+	require.NotNil(t, handler)
+	assert.Equal(t, trace.SpanKindInternal, handler.SpanKind())
+	assert.Equal(t, request.SpanContext().SpanID(), handler.Parent().SpanID())
+	assert.Equal(t, "index unavailable", handler.Status().Description)
+	require.Len(t, handler.Events(), 1)
+	assert.Equal(t, "exception", handler.Events()[0].Name)
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Principal is the authenticated identity the requests of a client are made
+// on behalf of. Principals returned by an Authenticator must not be modified
+// afterwards, since they are shared by the requests.
+type Principal struct {
+	ID         string
+	Roles      []string
+	Scopes     []string
+	Attributes map[string]any
+}
+
+// HasRole reports whether the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+// HasScope reports whether the principal was granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// AuthRequest describes the client an Authenticator authenticates.
+type AuthRequest struct {
+	// HTTPRequest is the request received by the SSE and streamable-http
+	// transports, nil for stdio.
+	HTTPRequest *http.Request
+	// Conn is the connection the stdio transport reads from when it is a
+	// network connection, such as a unix socket, nil otherwise.
+	Conn net.Conn
+}
+
+// Authenticator maps the client of a transport to a Principal. It returns an
+// error wrapping ErrUnauthenticated if the client cannot be authenticated,
+// and may return a nil principal to let anonymous clients in.
+type Authenticator interface {
+	Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error)
+}
+
+// AuthenticatorFunc is an adapter to use a function as an Authenticator.
+type AuthenticatorFunc func(ctx context.Context, request *AuthRequest) (*Principal, error)
+
+// Authenticate implements Authenticator.
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	return f(ctx, request)
+}
+
+// AnyAuthenticator returns an Authenticator trying the authenticators in
+// order, and returning the principal of the first one that succeeds.
+func AnyAuthenticator(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, request *AuthRequest) (*Principal, error) {
+		errs := make([]error, 0, len(authenticators))
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx, request)
+			if err == nil {
+				return principal, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	})
+}
+
+// principalKey is the context key for storing the authenticated principal
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal the current request is made on
+// behalf of, or nil if the client was not authenticated. It is available to
+// handlers, hooks and filters.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if principal, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		return principal
+	}
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithPrincipal); ok {
+		return session.GetPrincipal()
+	}
+	return nil
+}
+
+// withPrincipal returns a copy of ctx carrying the principal
+func withPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// authenticateHTTP authenticates an HTTP request. It returns the request with
+// the principal in its context, or writes a Bearer challenge and returns false
+// if the request is not authenticated. The challenge points to the metadata of
+// protectedResource, if not nil. The error of the authenticator is not sent to
+// the client.
+func authenticateHTTP(authenticator Authenticator, protectedResource *ProtectedResource, w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	principal, err := authenticator.Authenticate(r.Context(), &AuthRequest{HTTPRequest: r})
+	if err != nil {
+		if protectedResource != nil {
+			protectedResource.challenge(w, http.StatusUnauthorized, "", "")
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}
+		return nil, false
+	}
+	return r.WithContext(withPrincipal(r.Context(), principal)), true
+}
+
+// samePrincipal reports whether a request made by principal may use a
+// session created by owner
+func samePrincipal(owner, principal *Principal) bool {
+	if owner == nil {
+		return true
+	}
+	return principal != nil && principal.ID == owner.ID
+}
+
+// lookupSecret returns the principal of a secret, comparing the secrets in
+// constant time
+func lookupSecret(secrets map[string]*Principal, secret string) (*Principal, bool) {
+	var found *Principal
+	for candidate, principal := range secrets {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(secret)) == 1 {
+			found = principal
+		}
+	}
+	return found, found != nil
+}
+
+// APIKeyAuthenticator authenticates HTTP requests by the API key sent in a
+// header.
+type APIKeyAuthenticator struct {
+	// Header is the header carrying the key, X-API-Key if empty.
+	Header string
+	// Keys maps the valid keys to their principal.
+	Keys map[string]*Principal
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	if request.HTTPRequest == nil {
+		return nil, fmt.Errorf("%w: API keys require an HTTP transport", ErrUnauthenticated)
+	}
+	header := a.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	key := request.HTTPRequest.Header.Get(header)
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrUnauthenticated, header)
+	}
+	principal, ok := lookupSecret(a.Keys, key)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+	return principal, nil
+}
+
+// BearerTokenAuthenticator authenticates HTTP requests by static bearer
+// tokens. Use a ProtectedResource for OAuth access tokens.
+type BearerTokenAuthenticator struct {
+	// Tokens maps the valid tokens to their principal.
+	Tokens map[string]*Principal
+}
+
+// Authenticate implements Authenticator.
+func (a *BearerTokenAuthenticator) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	if request.HTTPRequest == nil {
+		return nil, fmt.Errorf("%w: bearer tokens require an HTTP transport", ErrUnauthenticated)
+	}
+	token, err := bearerToken(request.HTTPRequest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+	principal, ok := lookupSecret(a.Tokens, token)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid bearer token", ErrUnauthenticated)
+	}
+	return principal, nil
+}
+
+// ClientCertificateAuthenticator authenticates HTTP requests by their TLS
+// client certificate. The server must verify the certificates, typically with
+// tls.RequireAndVerifyClientCert.
+type ClientCertificateAuthenticator struct {
+	// PrincipalFunc maps a verified certificate to its principal. By default,
+	// the principal is identified by the common name of the subject.
+	PrincipalFunc func(certificate *x509.Certificate) (*Principal, error)
+}
+
+// Authenticate implements Authenticator.
+func (a *ClientCertificateAuthenticator) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	r := request.HTTPRequest
+	if r == nil || r.TLS == nil {
+		return nil, fmt.Errorf("%w: client certificates require TLS", ErrUnauthenticated)
+	}
+	if len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("%w: missing verified client certificate", ErrUnauthenticated)
+	}
+	certificate := r.TLS.VerifiedChains[0][0]
+	if a.PrincipalFunc != nil {
+		return a.PrincipalFunc(certificate)
+	}
+	if certificate.Subject.CommonName == "" {
+		return nil, fmt.Errorf("%w: client certificate without common name", ErrUnauthenticated)
+	}
+	return &Principal{
+		ID: certificate.Subject.CommonName,
+		Attributes: map[string]any{
+			"subject":      certificate.Subject.String(),
+			"issuer":       certificate.Issuer.String(),
+			"serialNumber": certificate.SerialNumber.String(),
+			"dnsNames":     certificate.DNSNames,
+			"emails":       certificate.EmailAddresses,
+		},
+	}, nil
+}
+
+// EnvAuthenticator authenticates the client of the stdio transport by the
+// environment the server was started with: <Prefix>ID, <Prefix>ROLES and
+// <Prefix>SCOPES, the latter two being comma-separated lists.
+type EnvAuthenticator struct {
+	// Prefix is the prefix of the variables, MCP_PRINCIPAL_ if empty.
+	Prefix string
+}
+
+// Authenticate implements Authenticator.
+func (a *EnvAuthenticator) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	prefix := a.Prefix
+	if prefix == "" {
+		prefix = "MCP_PRINCIPAL_"
+	}
+	id := os.Getenv(prefix + "ID")
+	if id == "" {
+		return nil, fmt.Errorf("%w: %sID is not set", ErrUnauthenticated, prefix)
+	}
+	return &Principal{
+		ID:     id,
+		Roles:  splitList(os.Getenv(prefix + "ROLES")),
+		Scopes: splitList(os.Getenv(prefix + "SCOPES")),
+	}, nil
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// PeerCredentials are the credentials of the process at the other end of the
+// stdio transport.
+type PeerCredentials struct {
+	PID int
+	UID int
+	GID int
+}
+
+// PeerCredentialsAuthenticator authenticates the client of the stdio
+// transport by the credentials of its process. They are read from the socket
+// when the transport is a unix socket, which is only supported on Linux.
+// Other clients are rejected, unless TrustParentProcess is set.
+type PeerCredentialsAuthenticator struct {
+	// PrincipalFunc maps the credentials to a principal. By default, the
+	// principal is identified by the user ID, as "uid:<uid>".
+	PrincipalFunc func(credentials PeerCredentials) (*Principal, error)
+	// TrustParentProcess authenticates the clients of a stdio transport that
+	// is not a unix socket as the parent process, with the user and group of
+	// the server. Only set it when the server is spawned by its client over
+	// pipes, since nothing else is known about the other end.
+	TrustParentProcess bool
+}
+
+// Authenticate implements Authenticator.
+func (a *PeerCredentialsAuthenticator) Authenticate(ctx context.Context, request *AuthRequest) (*Principal, error) {
+	var credentials PeerCredentials
+	if conn, ok := request.Conn.(*net.UnixConn); ok {
+		var err error
+		if credentials, err = unixPeerCredentials(conn); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+		}
+	} else if a.TrustParentProcess && request.HTTPRequest == nil {
+		credentials = PeerCredentials{PID: os.Getppid(), UID: os.Getuid(), GID: os.Getgid()}
+	} else {
+		return nil, fmt.Errorf("%w: peer credentials require a unix socket", ErrUnauthenticated)
+	}
+
+	if a.PrincipalFunc != nil {
+		return a.PrincipalFunc(credentials)
+	}
+	return &Principal{
+		ID: "uid:" + strconv.Itoa(credentials.UID),
+		Attributes: map[string]any{
+			"pid": credentials.PID,
+			"uid": credentials.UID,
+			"gid": credentials.GID,
+		},
+	}, nil
+}
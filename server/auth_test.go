@@ -0,0 +1,438 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	alice = &Principal{ID: "alice", Roles: []string{"admin"}, Scopes: []string{"tools:write"}}
+	bob   = &Principal{ID: "bob", Roles: []string{"viewer"}}
+)
+
+func httpAuthRequest(header, value string) *AuthRequest {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	return &AuthRequest{HTTPRequest: r}
+}
+
+func TestPrincipal(t *testing.T) {
+	assert.True(t, alice.HasRole("admin"))
+	assert.False(t, alice.HasRole("viewer"))
+	assert.True(t, alice.HasScope("tools:write"))
+	assert.False(t, bob.HasScope("tools:write"))
+
+	var anonymous *Principal
+	assert.False(t, anonymous.HasRole("admin"))
+	assert.False(t, anonymous.HasScope("tools:write"))
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator := &APIKeyAuthenticator{Keys: map[string]*Principal{"key-a": alice, "key-b": bob}}
+
+	principal, err := authenticator.Authenticate(context.Background(), httpAuthRequest("X-API-Key", "key-b"))
+	require.NoError(t, err)
+	assert.Equal(t, bob, principal)
+
+	_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("X-API-Key", "key-c"))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("", ""))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = authenticator.Authenticate(context.Background(), &AuthRequest{})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	authenticator.Header = "X-Custom-Key"
+	principal, err = authenticator.Authenticate(context.Background(), httpAuthRequest("X-Custom-Key", "key-a"))
+	require.NoError(t, err)
+	assert.Equal(t, alice, principal)
+}
+
+func TestBearerTokenAuthenticator(t *testing.T) {
+	authenticator := &BearerTokenAuthenticator{Tokens: map[string]*Principal{"token-a": alice}}
+
+	principal, err := authenticator.Authenticate(context.Background(), httpAuthRequest("Authorization", "Bearer token-a"))
+	require.NoError(t, err)
+	assert.Equal(t, alice, principal)
+
+	for _, authorization := range []string{"", "Bearer token-b", "Basic dXNlcjpwYXNz"} {
+		_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("Authorization", authorization))
+		assert.ErrorIs(t, err, ErrUnauthenticated, authorization)
+	}
+}
+
+func TestClientCertificateAuthenticator(t *testing.T) {
+	certificate := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "client-1", Organization: []string{"Example"}},
+		DNSNames:     []string{"client-1.example.com"},
+	}
+	request := httpAuthRequest("", "")
+	request.HTTPRequest.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+
+	authenticator := &ClientCertificateAuthenticator{}
+	principal, err := authenticator.Authenticate(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", principal.ID)
+	assert.Equal(t, "42", principal.Attributes["serialNumber"])
+	assert.Equal(t, []string{"client-1.example.com"}, principal.Attributes["dnsNames"])
+
+	authenticator.PrincipalFunc = func(certificate *x509.Certificate) (*Principal, error) {
+		return &Principal{ID: certificate.Subject.Organization[0]}, nil
+	}
+	principal, err = authenticator.Authenticate(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "Example", principal.ID)
+
+	// certificates must have been verified
+	request.HTTPRequest.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{certificate}}
+	_, err = authenticator.Authenticate(context.Background(), request)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("", ""))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestEnvAuthenticator(t *testing.T) {
+	authenticator := &EnvAuthenticator{Prefix: "TEST_PRINCIPAL_"}
+	_, err := authenticator.Authenticate(context.Background(), &AuthRequest{})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	t.Setenv("TEST_PRINCIPAL_ID", "ci-bot")
+	t.Setenv("TEST_PRINCIPAL_ROLES", "deployer, viewer")
+	t.Setenv("TEST_PRINCIPAL_SCOPES", "tools:read,")
+	principal, err := authenticator.Authenticate(context.Background(), &AuthRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{ID: "ci-bot", Roles: []string{"deployer", "viewer"}, Scopes: []string{"tools:read"}}, principal)
+}
+
+func TestPeerCredentialsAuthenticator(t *testing.T) {
+	authenticator := &PeerCredentialsAuthenticator{}
+
+	t.Run("no unix socket", func(t *testing.T) {
+		_, err := authenticator.Authenticate(context.Background(), &AuthRequest{})
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("parent process", func(t *testing.T) {
+		authenticator := &PeerCredentialsAuthenticator{TrustParentProcess: true}
+		principal, err := authenticator.Authenticate(context.Background(), &AuthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "uid:"+strconv.Itoa(os.Getuid()), principal.ID)
+		assert.Equal(t, os.Getppid(), principal.Attributes["pid"])
+
+		_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("", ""))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("unix socket", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("peer credentials of unix sockets are only supported on Linux")
+		}
+		listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+		require.NoError(t, err)
+		defer listener.Close()
+		client, err := net.Dial("unix", listener.Addr().String())
+		require.NoError(t, err)
+		defer client.Close()
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var credentials PeerCredentials
+		authenticator := &PeerCredentialsAuthenticator{PrincipalFunc: func(c PeerCredentials) (*Principal, error) {
+			credentials = c
+			return alice, nil
+		}}
+		principal, err := authenticator.Authenticate(context.Background(), &AuthRequest{Conn: conn})
+		require.NoError(t, err)
+		assert.Equal(t, alice, principal)
+		assert.Equal(t, PeerCredentials{PID: os.Getpid(), UID: os.Getuid(), GID: os.Getgid()}, credentials)
+	})
+
+	_, err := authenticator.Authenticate(context.Background(), httpAuthRequest("", ""))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestAnyAuthenticator(t *testing.T) {
+	authenticator := AnyAuthenticator(
+		&APIKeyAuthenticator{Keys: map[string]*Principal{"key-a": alice}},
+		&BearerTokenAuthenticator{Tokens: map[string]*Principal{"token-b": bob}},
+	)
+
+	principal, err := authenticator.Authenticate(context.Background(), httpAuthRequest("Authorization", "Bearer token-b"))
+	require.NoError(t, err)
+	assert.Equal(t, bob, principal)
+
+	_, err = authenticator.Authenticate(context.Background(), httpAuthRequest("Authorization", "Bearer token-a"))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	assert.Contains(t, err.Error(), "missing X-API-Key header")
+	assert.Contains(t, err.Error(), "invalid bearer token")
+}
+
+func TestAuthenticateHTTP_Challenge(t *testing.T) {
+	authenticator := &APIKeyAuthenticator{Keys: map[string]*Principal{"key-a": alice}}
+	protectedResource, err := NewProtectedResource(ProtectedResourceMetadata{Resource: "https://mcp.example.com/mcp"}, TokenVerifierFunc(
+		func(ctx context.Context, token string) (*TokenClaims, error) {
+			return nil, ErrInvalidToken
+		},
+	))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name              string
+		protectedResource *ProtectedResource
+		challenge         string
+	}{
+		{"without protected resource", nil, "Bearer"},
+		{"with protected resource", protectedResource, `Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, ok := authenticateHTTP(authenticator, tt.protectedResource, w, httpAuthRequest("X-API-Key", "secret-key").HTTPRequest)
+			assert.False(t, ok)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.challenge, w.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, "Unauthorized\n", w.Body.String())
+		})
+	}
+}
+
+func TestStreamableHTTP_Authenticator(t *testing.T) {
+	hooks := &Hooks{}
+	principals := make(chan *Principal, 10)
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		principals <- PrincipalFromContext(ctx)
+	})
+	mcpServer := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithToolFilter(func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+			if PrincipalFromContext(ctx).HasRole("admin") {
+				return tools
+			}
+			return nil
+		}),
+	)
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(PrincipalFromContext(ctx).ID), nil
+	})
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer, WithHTTPAuthenticator(
+		&APIKeyAuthenticator{Keys: map[string]*Principal{"key-a": alice, "key-b": bob}},
+	)))
+	defer server.Close()
+
+	post := func(key, sessionID, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	initBody, err := json.Marshal(initRequest)
+	require.NoError(t, err)
+
+	resp := post("", "", string(initBody))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = post("key-a", "", string(initBody))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+	assert.Equal(t, alice, <-principals)
+
+	t.Run("principal reaches handlers and filters", func(t *testing.T) {
+		resp := post("key-a", sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		defer resp.Body.Close()
+		var response jsonRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Len(t, response.Result["tools"], 1)
+
+		resp = post("key-a", sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"whoami"}}`)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "alice", response.Result["content"].([]any)[0].(map[string]any)["text"])
+	})
+
+	t.Run("sessions are bound to their principal", func(t *testing.T) {
+		resp := post("key-b", sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// bob can use a session of its own, but does not see the tools
+		resp = post("key-b", "", string(initBody))
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = post("key-b", resp.Header.Get(headerKeySessionID), `{"jsonrpc":"2.0","id":5,"method":"tools/list"}`)
+		defer resp.Body.Close()
+		var response jsonRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Empty(t, response.Result["tools"])
+	})
+
+	t.Run("sessions without state are denied", func(t *testing.T) {
+		resp := post("key-a", "unknown-session", `{"jsonrpc":"2.0","id":6,"method":"tools/list"}`)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestStreamableHTTP_AuthenticatorResumption(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("notify"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_ = ServerFromContext(ctx).SendNotificationToClient(ctx, "test/progress", map[string]any{"step": 1})
+		return mcp.NewToolResultText("done"), nil
+	})
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer,
+		WithEventStore(NewInMemoryEventStore(0)),
+		WithHTTPAuthenticator(&APIKeyAuthenticator{Keys: map[string]*Principal{"key-a": alice, "key-b": bob}}),
+	))
+	defer server.Close()
+
+	send := func(ctx context.Context, method, key, sessionID, lastEventID, body string) *http.Response {
+		req, err := http.NewRequestWithContext(ctx, method, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key)
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		if lastEventID != "" {
+			req.Header.Set(headerKeyLastEventID, lastEventID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	initBody, err := json.Marshal(initRequest)
+	require.NoError(t, err)
+	resp := send(context.Background(), http.MethodPost, "key-a", "", "", string(initBody))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	t.Run("GET stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := send(ctx, http.MethodGet, "key-a", sessionID, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Eventually(t, func() bool {
+			return mcpServer.SendNotificationToSpecificClient(sessionID, "test/notification", nil) == nil
+		}, time.Second, 10*time.Millisecond)
+		event := readStreamEvent(t, bufio.NewReader(resp.Body))
+		cancel()
+		resp.Body.Close()
+
+		// the session is taken from the event ID without a session header
+		resp = send(context.Background(), http.MethodGet, "key-b", "", event.id, "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = send(context.Background(), http.MethodGet, "key-a", "", event.id, "")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("POST stream", func(t *testing.T) {
+		resp := send(context.Background(), http.MethodPost, "key-a", sessionID, "", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"notify"}}`)
+		event := readStreamEvent(t, bufio.NewReader(resp.Body))
+		resp.Body.Close()
+
+		resp = send(context.Background(), http.MethodGet, "key-b", "", event.id, "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestSSEServer_Authenticator(t *testing.T) {
+	hooks := &Hooks{}
+	registered := make(chan *Principal, 1)
+	hooks.AddOnRegisterSession(func(ctx context.Context, session ClientSession) {
+		registered <- session.(SessionWithPrincipal).GetPrincipal()
+	})
+	testServer := NewTestServer(NewMCPServer("test", "1.0.0", WithHooks(hooks)), WithSSEAuthenticator(
+		&BearerTokenAuthenticator{Tokens: map[string]*Principal{"token-a": alice, "token-b": bob}},
+	))
+	defer testServer.Close()
+
+	request := func(method, url, token string) *http.Response {
+		req, err := http.NewRequest(method, url, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := request(http.MethodGet, testServer.URL+"/sse", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	sseResp := request(http.MethodGet, testServer.URL+"/sse", "token-a")
+	defer sseResp.Body.Close()
+	require.Equal(t, http.StatusOK, sseResp.StatusCode)
+	assert.Equal(t, alice, <-registered)
+
+	endpointEvent, err := readSSEEvent(sseResp)
+	require.NoError(t, err)
+	messageURL := strings.TrimSpace(strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0])
+
+	resp = request(http.MethodPost, messageURL, "token-b")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = request(http.MethodPost, messageURL, "token-a")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestStdioServer_Authenticator(t *testing.T) {
+	hooks := &Hooks{}
+	registered := make(chan *Principal, 1)
+	hooks.AddOnRegisterSession(func(ctx context.Context, session ClientSession) {
+		registered <- PrincipalFromContext(ctx)
+	})
+	stdioServer := NewStdioServer(NewMCPServer("test", "1.0.0", WithHooks(hooks)))
+
+	WithStdioAuthenticator(&EnvAuthenticator{Prefix: "TEST_PRINCIPAL_"})(stdioServer)
+	err := stdioServer.Listen(context.Background(), strings.NewReader(""), &strings.Builder{})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	assert.Empty(t, registered)
+
+	t.Setenv("TEST_PRINCIPAL_ID", "ci-bot")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = stdioServer.Listen(ctx, strings.NewReader(""), &strings.Builder{})
+	principal := <-registered
+	require.NotNil(t, principal)
+	assert.Equal(t, "ci-bot", principal.ID)
+	assert.Equal(t, principal, stdioSessionInstance.GetPrincipal())
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultMaxBatchSize is the maximum number of messages of a batch.
+	defaultMaxBatchSize = 100
+	// defaultBatchConcurrency is the maximum number of requests of a batch
+	// handled at the same time.
+	defaultBatchConcurrency = 8
+)
+
+// WithMaxBatchSize sets the maximum number of messages of a JSON-RPC batch,
+// 100 by default. Larger batches are rejected with an invalid request error
+// without handling any of their messages.
+func WithMaxBatchSize(size int) ServerOption {
+	return func(s *MCPServer) {
+		s.maxBatchSize = size
+	}
+}
+
+// WithBatchConcurrency sets the maximum number of requests of a JSON-RPC batch
+// handled at the same time, 8 by default. The other requests wait for one of
+// them to complete.
+func WithBatchConcurrency(concurrency int) ServerOption {
+	return func(s *MCPServer) {
+		s.batchConcurrency = concurrency
+	}
+}
+
+// isBatch reports whether message is a JSON-RPC batch, that is an array of
+// messages.
+func isBatch(message json.RawMessage) bool {
+	trimmed := bytes.TrimLeft(message, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// handleBatch processes a JSON-RPC batch. Its requests are handled
+// concurrently, up to the batch concurrency, and their responses returned as a mcp.JSONRPCBatchResponse in
+// the order of the requests. Notifications, and responses to requests sent to
+// the client, get no response. If the batch contains nothing else, nil is
+// returned. Batches are rejected on the sessions using protocol version
+// 2025-06-18 or later, which removed them.
+func (s *MCPServer) handleBatch(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	if version := ProtocolVersionFromContext(ctx); !supportsBatches(version) {
+		return createErrorResponse(nil, mcp.INVALID_REQUEST,
+			fmt.Sprintf("Batches are not supported by protocol version %s", version))
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(message, &messages); err != nil {
+		return createErrorResponse(nil, mcp.PARSE_ERROR, "Failed to parse batch")
+	}
+	if len(messages) == 0 {
+		return createErrorResponse(nil, mcp.INVALID_REQUEST, "Empty batch")
+	}
+	if len(messages) > s.maxBatchSize {
+		return createErrorResponse(nil, mcp.INVALID_REQUEST,
+			fmt.Sprintf("Batch too large: %d messages, at most %d are allowed", len(messages), s.maxBatchSize))
+	}
+
+	responses := make([]mcp.JSONRPCMessage, len(messages))
+	semaphore := make(chan struct{}, max(s.batchConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, m := range messages {
+		var baseMessage struct {
+			Method mcp.MCPMethod   `json:"method"`
+			ID     any             `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if isBatch(m) || json.Unmarshal(m, &baseMessage) != nil {
+			responses[i] = createErrorResponse(nil, mcp.INVALID_REQUEST, "Invalid batch element")
+			continue
+		}
+		switch {
+		case baseMessage.Method == "" && (baseMessage.Result != nil || baseMessage.Error != nil):
+			// Responses are routed by the transports before reaching the server
+			continue
+		case baseMessage.Method == mcp.MethodInitialize:
+			responses[i] = createErrorResponse(
+				baseMessage.ID,
+				mcp.INVALID_REQUEST,
+				"Initialize request must not be part of a batch",
+			)
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, m json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			responses[i] = s.HandleMessage(ctx, m)
+		}(i, m)
+	}
+	wg.Wait()
+
+	batch := make(mcp.JSONRPCBatchResponse, 0, len(messages))
+	for _, response := range responses {
+		if response != nil {
+			batch = append(batch, response)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return batch
+}
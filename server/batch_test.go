@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_HandleBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name: "requests and notifications",
+			message: `[
+				{"jsonrpc":"2.0","id":1,"method":"ping"},
+				{"jsonrpc":"2.0","method":"notifications/initialized"},
+				{"jsonrpc":"2.0","id":"two","method":"tools/list"}
+			]`,
+			expected: `[
+				{"jsonrpc":"2.0","id":1,"result":{}},
+				{"jsonrpc":"2.0","id":"two","result":{"tools":[]}}
+			]`,
+		},
+		{
+			name:     "only notifications",
+			message:  `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+			expected: `null`,
+		},
+		{
+			name:     "empty batch",
+			message:  `[]`,
+			expected: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Empty batch"}}`,
+		},
+		{
+			name:     "invalid batch",
+			message:  `[{"jsonrpc":"2.0","id":1,"method":"ping"},`,
+			expected: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Failed to parse batch"}}`,
+		},
+		{
+			name:    "invalid elements",
+			message: `[1, [], {"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+			expected: `[
+				{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid batch element"}},
+				{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid batch element"}},
+				{"jsonrpc":"2.0","id":1,"result":{}}
+			]`,
+		},
+		{
+			name:     "initialize is not allowed",
+			message:  `[{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}]`,
+			expected: `[{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"Initialize request must not be part of a batch"}}]`,
+		},
+		{
+			name:     "responses to the server are skipped",
+			message:  `[{"jsonrpc":"2.0","id":7,"result":{}},{"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+			expected: `[{"jsonrpc":"2.0","id":1,"result":{}}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(true))
+			response := server.HandleMessage(context.Background(), []byte(tt.message))
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestMCPServer_HandleBatch_Concurrent(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+
+	// Each call waits for the other one, which deadlocks unless the
+	// requests of the batch run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	server.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started.Done()
+		started.Wait()
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	done := make(chan mcp.JSONRPCMessage)
+	go func() {
+		done <- server.HandleMessage(context.Background(), []byte(`[
+			{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}},
+			{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait"}}
+		]`))
+	}()
+
+	select {
+	case response := <-done:
+		batch, ok := response.(mcp.JSONRPCBatchResponse)
+		require.True(t, ok)
+		require.Len(t, batch, 2)
+		assert.Equal(t, mcp.NewRequestId(float64(1)), batch[0].(mcp.JSONRPCResponse).ID)
+		assert.Equal(t, mcp.NewRequestId(float64(2)), batch[1].(mcp.JSONRPCResponse).ID)
+	case <-time.After(time.Second):
+		t.Fatal("batch requests were not handled concurrently")
+	}
+}
+
+func TestMCPServer_HandleBatch_Limits(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	server := NewMCPServer("test-server", "1.0.0", WithMaxBatchSize(5), WithBatchConcurrency(2))
+	server.AddTool(mcp.NewTool("count"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	batch := func(size int) string {
+		messages := make([]string, size)
+		for i := range messages {
+			messages[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"count"}}`, i+1)
+		}
+		return "[" + strings.Join(messages, ",") + "]"
+	}
+
+	t.Run("oversized batch", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(batch(6)))
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch too large: 6 messages, at most 5 are allowed"}}`, string(data))
+		assert.Zero(t, maxRunning.Load(), "no message of the batch is handled")
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(batch(5)))
+		require.Len(t, response, 5)
+		assert.Equal(t, int32(2), maxRunning.Load())
+	})
+}
+
+func TestMCPServer_HandleBatch_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"", `[{"jsonrpc":"2.0","id":1,"result":{}}]`},
+		{mcp.ProtocolVersion20250326, `[{"jsonrpc":"2.0","id":1,"result":{}}]`},
+		{mcp.ProtocolVersion20250618, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batches are not supported by protocol version 2025-06-18"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0")
+			session := NewInProcessSession("session-1", nil)
+			session.SetProtocolVersion(tt.version)
+			ctx := server.WithContext(context.Background(), session)
+
+			response := server.HandleMessage(ctx, []byte(`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`))
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestStreamableHTTP_Batch(t *testing.T) {
+	mcpServer := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(true))
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer))
+	defer server.Close()
+
+	post := func(sessionID, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	resp = post(sessionID, `[
+		{"jsonrpc":"2.0","id":2,"method":"tools/list"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":3,"method":"prompts/list"}
+	]`)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var responses []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&responses))
+	require.Len(t, responses, 2)
+	assert.Equal(t, float64(2), responses[0]["id"])
+	assert.Equal(t, float64(3), responses[1]["id"])
+
+	resp = post(sessionID, `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	t.Run("rejected from protocol version 2025-06-18", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`[{"jsonrpc":"2.0","id":4,"method":"ping"}]`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		req.Header.Set(headerKeyProtocolVersion, mcp.ProtocolVersion20250618)
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, float64(mcp.INVALID_REQUEST), response["error"].(map[string]any)["code"])
+	})
+}
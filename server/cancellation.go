@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// inFlightRequest is a request whose handler is still running.
+type inFlightRequest struct {
+	cancel context.CancelCauseFunc
+}
+
+// inFlightRequestStore keeps track of the requests currently being handled for
+// each session, so that they can be cancelled by the client or when the
+// session goes away.
+type inFlightRequestStore struct {
+	mu       sync.Mutex
+	requests map[string]map[string]*inFlightRequest // sessionID -> request ID -> request
+}
+
+func newInFlightRequestStore() *inFlightRequestStore {
+	return &inFlightRequestStore{
+		requests: make(map[string]map[string]*inFlightRequest),
+	}
+}
+
+func (s *inFlightRequestStore) add(sessionID, requestID string, request *inFlightRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests, ok := s.requests[sessionID]
+	if !ok {
+		requests = make(map[string]*inFlightRequest)
+		s.requests[sessionID] = requests
+	}
+	requests[requestID] = request
+}
+
+// remove drops the request, unless the ID has since been reused by another one.
+func (s *inFlightRequestStore) remove(sessionID, requestID string, request *inFlightRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests, ok := s.requests[sessionID]
+	if !ok || requests[requestID] != request {
+		return
+	}
+	delete(requests, requestID)
+	if len(requests) == 0 {
+		delete(s.requests, sessionID)
+	}
+}
+
+// cancel cancels the request with the given ID, reporting whether it was found.
+func (s *inFlightRequestStore) cancel(sessionID, requestID string) bool {
+	s.mu.Lock()
+	request, ok := s.requests[sessionID][requestID]
+	s.mu.Unlock()
+	if ok {
+		request.cancel(ErrRequestCancelled)
+	}
+	return ok
+}
+
+// cancelSession cancels all the requests of the given session.
+func (s *inFlightRequestStore) cancelSession(sessionID string) {
+	s.mu.Lock()
+	requests := s.requests[sessionID]
+	delete(s.requests, sessionID)
+	s.mu.Unlock()
+	for _, request := range requests {
+		request.cancel(ErrRequestCancelled)
+	}
+}
+
+// trackRequest registers the request as in flight for the session in ctx and
+// returns a context that is cancelled with ErrRequestCancelled when the client
+// cancels the request or the session is torn down. The returned function must
+// be called once the request has been handled; it reports whether the request
+// was cancelled, in which case no response should be sent.
+//
+// Requests without a session are not tracked, since their IDs are not
+// guaranteed to be unique across clients.
+func (s *MCPServer) trackRequest(ctx context.Context, id any) (context.Context, func() bool) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == "" {
+		return ctx, func() bool { return false }
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	sessionID, requestID := session.SessionID(), mcp.NewRequestId(id).String()
+	request := &inFlightRequest{cancel: cancel}
+	s.inFlightRequests.add(sessionID, requestID, request)
+
+	return ctx, func() bool {
+		s.inFlightRequests.remove(sessionID, requestID, request)
+		cancelled := errors.Is(context.Cause(ctx), ErrRequestCancelled)
+		cancel(nil)
+		return cancelled
+	}
+}
+
+// handleCancelledNotification cancels the in-flight request referenced by a
+// notifications/cancelled. Unknown or already completed requests are ignored.
+func (s *MCPServer) handleCancelledNotification(
+	ctx context.Context,
+	notification mcp.JSONRPCNotification,
+) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+	requestID, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok || requestID == nil {
+		return
+	}
+	s.inFlightRequests.cancel(session.SessionID(), mcp.NewRequestId(requestID).String())
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockingToolServer returns a server with a tool that blocks until its
+// context is done, reporting the cancellation cause on the returned channel.
+func newBlockingToolServer(started chan<- struct{}) (*MCPServer, <-chan error) {
+	causes := make(chan error, 1)
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return mcp.NewToolResultText("done"), nil
+	})
+	return server, causes
+}
+
+func TestMCPServer_CancelledNotification(t *testing.T) {
+	started := make(chan struct{})
+	server, causes := newBlockingToolServer(started)
+	session := fakeSession{sessionID: "s1", initialized: true}
+	ctx := server.WithContext(context.Background(), session)
+
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"block"}}`,
+		))
+	}()
+	<-started
+
+	// A notification for another request leaves the call running
+	server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":8}}`,
+	))
+	select {
+	case <-responses:
+		t.Fatal("request should still be running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user abort"}}`,
+	))
+
+	select {
+	case cause := <-causes:
+		assert.ErrorIs(t, cause, ErrRequestCancelled)
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+	select {
+	case response := <-responses:
+		assert.Nil(t, response, "cancelled requests must not get a response")
+	case <-time.After(time.Second):
+		t.Fatal("HandleMessage did not return")
+	}
+}
+
+func TestMCPServer_UnregisterSessionCancelsRequests(t *testing.T) {
+	started := make(chan struct{})
+	server, causes := newBlockingToolServer(started)
+	session := fakeSession{
+		sessionID:           "s1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := server.WithContext(context.Background(), session)
+
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":"call-1","method":"tools/call","params":{"name":"block"}}`,
+		))
+	}()
+	<-started
+
+	server.UnregisterSession(context.Background(), session.SessionID())
+
+	select {
+	case cause := <-causes:
+		assert.ErrorIs(t, cause, ErrRequestCancelled)
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+	assert.Nil(t, <-responses)
+}
+
+func TestMCPServer_CompletedRequestIsNotCancelled(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	session := fakeSession{sessionID: "s1", initialized: true}
+	ctx := server.WithContext(context.Background(), session)
+
+	response := server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`,
+	))
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+
+	// Cancelling a finished request is a no-op
+	assert.False(t, server.inFlightRequests.cancel("s1", mcp.NewRequestId(float64(1)).String()))
+}
+
+func TestStdioServer_CancelledNotification(t *testing.T) {
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, false))
+	server.AddResource(mcp.NewResource("file:///slow", "slow"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = NewStdioServer(server).Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer stdinWriter.Close()
+
+	responses := make(chan map[string]any, 10)
+	go func() {
+		scanner := bufio.NewScanner(stdoutReader)
+		for scanner.Scan() {
+			var response map[string]any
+			if json.Unmarshal(scanner.Bytes(), &response) == nil {
+				responses <- response
+			}
+		}
+	}()
+	// send fails the test if the server does not read the message
+	send := func(message string) {
+		written := make(chan error, 1)
+		go func() {
+			_, err := stdinWriter.Write([]byte(message + "\n"))
+			written <- err
+		}()
+		select {
+		case err := <-written:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("message was not read")
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"file:///slow"}}`)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("resource handler was not called")
+	}
+
+	// The notification is read while the resource is being read
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case cause := <-causes:
+		assert.ErrorIs(t, cause, ErrRequestCancelled)
+	case <-time.After(time.Second):
+		t.Fatal("resource handler context was not cancelled")
+	}
+
+	// The cancelled request gets no response, the next one does
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	select {
+	case response := <-responses:
+		assert.Equal(t, float64(2), response["id"])
+	case <-time.After(time.Second):
+		t.Fatal("no response to ping")
+	}
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithClientRequestTimeout bounds the time the server waits for the client to
+// answer a request sent with SendRequestToClient or the helpers built on it.
+// A zero timeout, the default, waits until the context of the caller is done.
+func WithClientRequestTimeout(timeout time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.clientRequestTimeout = timeout
+	}
+}
+
+// SendRequestToClient sends a request to the client of the current session and
+// returns the raw result of its response. It is the building block of
+// RequestSampling, RequestRoots, RequestElicitation and PingClient, and can be
+// used for any other request the client supports.
+//
+// The request is bounded by the timeout set with WithClientRequestTimeout, if
+// any. If ctx is done before the client answers, the client is sent a
+// notifications/cancelled for the request. An error response of the client is
+// returned as a *ClientRequestError.
+func (s *MCPServer) SendRequestToClient(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	return s.sendRequestToSession(ctx, session, method, params)
+}
+
+func (s *MCPServer) sendRequestToSession(
+	ctx context.Context,
+	session ClientSession,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	requester, ok := session.(SessionWithRequests)
+	if !ok {
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+	if s.clientRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.clientRequestTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	if s.tracer == nil {
+		result, err := requester.SendRequest(ctx, method, params)
+		s.logClientRequest(ctx, session, method, start, err)
+		return result, err
+	}
+
+	// Send the trace context of the request along with it
+	ctx, params, span, err := s.startClientRequestSpan(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+	result, err := requester.SendRequest(ctx, method, params)
+	if err != nil {
+		span.RecordError(err)
+	}
+	s.logClientRequest(ctx, session, method, start, err)
+	return result, err
+}
+
+// PingClient sends a ping to the client of the current session and waits for
+// its answer.
+func (s *MCPServer) PingClient(ctx context.Context) error {
+	_, err := s.SendRequestToClient(ctx, mcp.MethodPing, nil)
+	return err
+}
+
+// clientRequest is a JSON-RPC request sent by the server to the client.
+type clientRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// clientResponse is the outcome of a request sent to the client.
+type clientResponse struct {
+	result json.RawMessage
+	err    error
+}
+
+// pendingClientRequest is a request waiting for the client's response.
+type pendingClientRequest struct {
+	method   mcp.MCPMethod
+	response chan clientResponse
+}
+
+// clientRequestTracker allocates the IDs of the requests a session sends to
+// its client, and routes the responses of the client back to the callers
+// waiting for them.
+type clientRequestTracker struct {
+	lastID  atomic.Int64
+	mu      sync.Mutex
+	pending map[int64]*pendingClientRequest
+}
+
+func newClientRequestTracker() *clientRequestTracker {
+	return &clientRequestTracker{
+		pending: make(map[int64]*pendingClientRequest),
+	}
+}
+
+// nextID returns a new request ID. Every request sent to the client,
+// including keep-alive pings, must use an ID from the same tracker so that
+// responses are never routed to the wrong caller.
+func (t *clientRequestTracker) nextID() int64 {
+	return t.lastID.Add(1)
+}
+
+// do sends a request to the client through send, then waits for the response
+// or for the context to be done. In the latter case, a cancellation of the
+// request is queued on notifications, if not nil.
+func (t *clientRequestTracker) do(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+	send func(request clientRequest) error,
+	notifications chan<- mcp.JSONRPCNotification,
+) (json.RawMessage, error) {
+	id := t.nextID()
+	pending := &pendingClientRequest{
+		method:   method,
+		response: make(chan clientResponse, 1),
+	}
+	t.mu.Lock()
+	t.pending[id] = pending
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	request := clientRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Method:  string(method),
+		Params:  params,
+	}
+	if err := send(request); err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		if notifications != nil {
+			select {
+			case notifications <- newCancelledNotification(id, ctx.Err()):
+			default:
+			}
+		}
+		return nil, fmt.Errorf("%s request: %w", method, ctx.Err())
+	case response := <-pending.response:
+		return response.result, response.err
+	}
+}
+
+// newCancelledNotification tells the client that the server is no longer
+// waiting for the response to the request with the given ID.
+func newCancelledNotification(id int64, reason error) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": id,
+					"reason":    reason.Error(),
+				},
+			},
+		},
+	}
+}
+
+// deliver routes a message received from the client to the pending request it
+// answers. It reports whether the message was such a response; anything else
+// is left for the caller to handle.
+func (t *clientRequestTracker) deliver(message json.RawMessage) bool {
+	// A batch is consumed only if all of its messages are responses
+	if isBatch(message) {
+		var messages []json.RawMessage
+		if err := json.Unmarshal(message, &messages); err != nil || len(messages) == 0 {
+			return false
+		}
+		delivered := true
+		for _, m := range messages {
+			if !t.deliver(m) {
+				delivered = false
+			}
+		}
+		return delivered
+	}
+
+	var response struct {
+		ID     json.Number     `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result,omitempty"`
+		Error  *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data,omitempty"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(message, &response); err != nil {
+		return false
+	}
+	if response.Method != "" || (response.Result == nil && response.Error == nil) {
+		return false
+	}
+	id, err := response.ID.Int64()
+	if err != nil {
+		return false
+	}
+
+	t.mu.Lock()
+	pending, ok := t.pending[id]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	var result clientResponse
+	if response.Error != nil {
+		result.err = &ClientRequestError{
+			Method:  pending.method,
+			Code:    response.Error.Code,
+			Message: response.Error.Message,
+			Data:    response.Error.Data,
+		}
+	} else {
+		result.result = response.Result
+	}
+
+	// The channel is buffered for a single response, extra ones are dropped
+	select {
+	case pending.response <- result:
+	default:
+	}
+	return true
+}
+
+// abort fails all the pending requests with err, for example when the
+// connection to the client has been lost.
+func (t *clientRequestTracker) abort(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pending := range t.pending {
+		select {
+		case pending.response <- clientResponse{err: err}:
+		default:
+		}
+	}
+}
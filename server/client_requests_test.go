@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRequestSession is a session whose client never answers requests.
+type blockingRequestSession struct {
+	fakeSession
+	tracker *clientRequestTracker
+}
+
+func (b *blockingRequestSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	return b.tracker.do(ctx, method, params, func(request clientRequest) error { return nil }, b.notificationChannel)
+}
+
+func TestMCPServer_SendRequestToClient(t *testing.T) {
+	t.Run("session lookup", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+
+		_, err := server.SendRequestToClient(context.Background(), mcp.MethodPing, nil)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+
+		ctx := server.WithContext(context.Background(), fakeSession{sessionID: "s1"})
+		_, err = server.SendRequestToClient(ctx, mcp.MethodPing, nil)
+		assert.ErrorIs(t, err, ErrSessionDoesNotSupportRequests)
+	})
+
+	t.Run("ping and sampling", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &fakeRequestSession{
+			fakeSession: fakeSession{sessionID: "s1", initialized: true},
+			respond: func(method mcp.MCPMethod, params any) (any, error) {
+				switch method {
+				case mcp.MethodPing:
+					return map[string]any{}, nil
+				case mcp.MethodSamplingCreateMessage:
+					return map[string]any{
+						"role":    "assistant",
+						"content": map[string]any{"type": "text", "text": "Hi there"},
+						"model":   "test-model",
+					}, nil
+				}
+				return nil, fmt.Errorf("unexpected method %s", method)
+			},
+		}
+		ctx := server.WithContext(context.Background(), session)
+
+		require.NoError(t, server.PingClient(ctx))
+
+		result, err := server.RequestSampling(ctx, mcp.CreateMessageRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, mcp.NewTextContent("Hi there"), result.Content)
+		assert.Equal(t, "test-model", result.Model)
+		assert.Equal(t, []mcp.MCPMethod{mcp.MethodPing, mcp.MethodSamplingCreateMessage}, session.requests())
+	})
+
+	t.Run("timeout cancels the request on the client", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithClientRequestTimeout(10*time.Millisecond))
+		session := &blockingRequestSession{
+			fakeSession: fakeSession{
+				sessionID:           "s1",
+				notificationChannel: make(chan mcp.JSONRPCNotification, 1),
+			},
+			tracker: newClientRequestTracker(),
+		}
+		ctx := server.WithContext(context.Background(), session)
+
+		err := server.PingClient(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		select {
+		case notification := <-session.notificationChannel:
+			assert.Equal(t, mcp.MethodNotificationCancelled, notification.Method)
+			assert.Equal(t, int64(1), notification.Params.AdditionalFields["requestId"])
+		default:
+			t.Fatal("no cancellation was sent")
+		}
+	})
+}
+
+func TestClientRequestTracker_ErrorResponse(t *testing.T) {
+	tracker := newClientRequestTracker()
+	_, err := tracker.do(context.Background(), mcp.MethodSamplingCreateMessage, nil, func(request clientRequest) error {
+		go tracker.deliver(json.RawMessage(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%d,"error":{"code":-1,"message":"user rejected","data":{"reason":"busy"}}}`, request.ID)))
+		return nil
+	}, nil)
+
+	var requestErr *ClientRequestError
+	require.ErrorAs(t, err, &requestErr)
+	assert.Equal(t, mcp.MethodSamplingCreateMessage, requestErr.Method)
+	assert.Equal(t, -1, requestErr.Code)
+	assert.Equal(t, "user rejected", requestErr.Message)
+	assert.JSONEq(t, `{"reason":"busy"}`, string(requestErr.Data))
+}
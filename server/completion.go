@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletionValues is the maximum number of values a completion result may
+// carry, as mandated by the specification.
+const maxCompletionValues = 100
+
+// ArgumentCompleterFunc returns completion candidates for a single prompt or
+// resource template argument. The argument name and the partial value typed by
+// the user are available in request.Params.Argument.
+type ArgumentCompleterFunc func(ctx context.Context, request mcp.CompleteRequest) ([]string, error)
+
+// CompletionOption configures the argument completers attached to a prompt or
+// resource template.
+type CompletionOption func(map[string]ArgumentCompleterFunc)
+
+// WithArgumentCompleter attaches a completer for the named argument of a prompt,
+// or the named variable of a resource template.
+func WithArgumentCompleter(argument string, completer ArgumentCompleterFunc) CompletionOption {
+	return func(completers map[string]ArgumentCompleterFunc) {
+		completers[argument] = completer
+	}
+}
+
+// WithCompletions enables the completions capability for the server, even if
+// no argument completer has been registered yet.
+func WithCompletions() ServerOption {
+	return func(s *MCPServer) {
+		s.capabilities.completions = mcp.ToBoolPtr(true)
+	}
+}
+
+func (s *MCPServer) implicitlyRegisterCompletionCapabilities() {
+	s.implicitlyRegisterCapabilities(
+		func() bool { return s.capabilities.completions != nil },
+		func() { s.capabilities.completions = mcp.ToBoolPtr(true) },
+	)
+}
+
+// buildCompleters applies the completion options and returns the resulting
+// completers, or nil if no option was given.
+func buildCompleters(opts []CompletionOption) map[string]ArgumentCompleterFunc {
+	if len(opts) == 0 {
+		return nil
+	}
+	completers := make(map[string]ArgumentCompleterFunc, len(opts))
+	for _, opt := range opts {
+		opt(completers)
+	}
+	return completers
+}
+
+// completionReference is the union of mcp.PromptReference and
+// mcp.ResourceReference used to decode the ref of a completion request.
+type completionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+func parseCompletionReference(ref any) (completionReference, error) {
+	var reference completionReference
+	switch r := ref.(type) {
+	case mcp.PromptReference:
+		return completionReference{Type: r.Type, Name: r.Name}, nil
+	case mcp.ResourceReference:
+		return completionReference{Type: r.Type, URI: r.URI}, nil
+	}
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return reference, err
+	}
+	if err := json.Unmarshal(data, &reference); err != nil {
+		return reference, err
+	}
+	return reference, nil
+}
+
+func (s *MCPServer) handleComplete(
+	ctx context.Context,
+	id any,
+	request mcp.CompleteRequest,
+) (*mcp.CompleteResult, *requestError) {
+	ref, err := parseCompletionReference(request.Params.Ref)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("invalid completion reference: %w", err),
+		}
+	}
+
+	// completions are allowed along with the prompt or resource they are for
+	var completer ArgumentCompleterFunc
+	var policyRequest PolicyRequest
+	switch ref.Type {
+	case mcp.RefTypePrompt:
+		var prompt mcp.Prompt
+		var exists bool
+		if session, ok := ClientSessionFromContext(ctx).(SessionWithPrompts); ok {
+			var entry ServerPrompt
+			if entry, exists = session.GetSessionPrompts()[ref.Name]; exists {
+				prompt = entry.Prompt
+				completer = entry.Completers[request.Params.Argument.Name]
+			}
+		}
+		if !exists {
+			s.promptsMu.RLock()
+			prompt, exists = s.prompts[ref.Name]
+			completer = s.promptCompleters[ref.Name][request.Params.Argument.Name]
+			s.promptsMu.RUnlock()
+		}
+		if !exists || !s.promptAllowed(ctx, prompt) {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("prompt '%s' not found: %w", ref.Name, ErrPromptNotFound),
+			}
+		}
+		policyRequest = PolicyRequest{Method: mcp.MethodPromptsGet, Name: ref.Name}
+	case mcp.RefTypeResource:
+		var template mcp.ResourceTemplate
+		var exists bool
+		if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+			var entry ServerResourceTemplate
+			if entry, exists = session.GetSessionResourceTemplates()[ref.URI]; exists {
+				template = entry.Template
+				completer = entry.Completers[request.Params.Argument.Name]
+			}
+		}
+		if !exists {
+			s.resourcesMu.RLock()
+			var entry resourceTemplateEntry
+			if entry, exists = s.resourceTemplates[ref.URI]; exists {
+				template = entry.template
+				completer = entry.completers[request.Params.Argument.Name]
+			}
+			s.resourcesMu.RUnlock()
+		}
+		if !exists || !s.resourceTemplateAllowed(ctx, template) {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("resource template '%s' not found: %w", ref.URI, ErrResourceNotFound),
+			}
+		}
+		policyRequest = PolicyRequest{Method: mcp.MethodResourcesRead, Name: ref.URI}
+	default:
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("unknown completion reference type '%s'", ref.Type),
+		}
+	}
+	if err := s.authorize(ctx, policyRequest); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  err,
+		}
+	}
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Values = []string{}
+	if completer == nil {
+		return result, nil
+	}
+
+	values, err := completer(ctx, request)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  err,
+		}
+	}
+
+	if len(values) > maxCompletionValues {
+		result.Completion.Total = len(values)
+		result.Completion.HasMore = true
+		values = values[:maxCompletionValues]
+	}
+	if values != nil {
+		result.Completion.Values = values
+	}
+	return result, nil
+}
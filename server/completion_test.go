@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_Completion(t *testing.T) {
+	languages := []string{"go", "golang", "python", "rust"}
+	prefixCompleter := func(candidates []string) ArgumentCompleterFunc {
+		return func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+			var values []string
+			for _, c := range candidates {
+				if strings.HasPrefix(c, request.Params.Argument.Value) {
+					values = append(values, c)
+				}
+			}
+			return values, nil
+		}
+	}
+
+	newServer := func() *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddPrompt(
+			mcp.NewPrompt("code_review", mcp.WithArgument("language")),
+			func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return &mcp.GetPromptResult{}, nil
+			},
+			WithArgumentCompleter("language", prefixCompleter(languages)),
+		)
+		server.AddResourceTemplate(
+			mcp.NewResourceTemplate("repo://{owner}/{name}", "Repository"),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return nil, nil
+			},
+			WithArgumentCompleter("owner", prefixCompleter([]string{"mark3labs", "modelcontextprotocol"})),
+			WithArgumentCompleter("name", func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+				return nil, errors.New("lookup failed")
+			}),
+		)
+		return server
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		validate func(t *testing.T, response mcp.JSONRPCMessage)
+	}{
+		{
+			name: "prompt argument",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/prompt","name":"code_review"},
+				"argument":{"name":"language","value":"go"}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok)
+				result, ok := resp.Result.(mcp.CompleteResult)
+				require.True(t, ok)
+				assert.Equal(t, []string{"go", "golang"}, result.Completion.Values)
+				assert.False(t, result.Completion.HasMore)
+			},
+		},
+		{
+			name: "resource template variable",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/resource","uri":"repo://{owner}/{name}"},
+				"argument":{"name":"owner","value":"mo"}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok)
+				result, ok := resp.Result.(mcp.CompleteResult)
+				require.True(t, ok)
+				assert.Equal(t, []string{"modelcontextprotocol"}, result.Completion.Values)
+			},
+		},
+		{
+			name: "argument without completer",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/prompt","name":"code_review"},
+				"argument":{"name":"style","value":""}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok)
+				result, ok := resp.Result.(mcp.CompleteResult)
+				require.True(t, ok)
+				assert.Empty(t, result.Completion.Values)
+
+				data, err := json.Marshal(result)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"completion":{"values":[]}}`, string(data))
+			},
+		},
+		{
+			name: "unknown prompt",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/prompt","name":"missing"},
+				"argument":{"name":"language","value":""}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				errResp, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, mcp.INVALID_PARAMS, errResp.Error.Code)
+			},
+		},
+		{
+			name: "unknown reference type",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/unknown"},
+				"argument":{"name":"language","value":""}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				errResp, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, mcp.INVALID_PARAMS, errResp.Error.Code)
+			},
+		},
+		{
+			name: "completer error",
+			message: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+				"ref":{"type":"ref/resource","uri":"repo://{owner}/{name}"},
+				"argument":{"name":"name","value":""}}}`,
+			validate: func(t *testing.T, response mcp.JSONRPCMessage) {
+				errResp, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, mcp.INTERNAL_ERROR, errResp.Error.Code)
+				assert.Equal(t, "lookup failed", errResp.Error.Message)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer()
+			response := server.HandleMessage(context.Background(), []byte(tt.message))
+			tt.validate(t, response)
+		})
+	}
+}
+
+func TestMCPServer_CompletionTruncatesValues(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddPrompt(
+		mcp.NewPrompt("numbers", mcp.WithArgument("n")),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		},
+		WithArgumentCompleter("n", func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+			values := make([]string, 150)
+			for i := range values {
+				values[i] = fmt.Sprintf("%d", i)
+			}
+			return values, nil
+		}),
+	)
+
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{
+		"ref":{"type":"ref/prompt","name":"numbers"},
+		"argument":{"name":"n","value":""}}}`))
+
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := resp.Result.(mcp.CompleteResult)
+	require.True(t, ok)
+	assert.Len(t, result.Completion.Values, 100)
+	assert.Equal(t, 150, result.Completion.Total)
+	assert.True(t, result.Completion.HasMore)
+}
+
+func TestMCPServer_CompletionCapability(t *testing.T) {
+	initialize := []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	complete := []byte(`{"jsonrpc":"2.0","id":2,"method":"completion/complete","params":{
+		"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"a","value":""}}}`)
+
+	t.Run("not advertised without completers", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddPrompt(mcp.NewPrompt("p"), nil)
+
+		resp := server.HandleMessage(context.Background(), initialize).(mcp.JSONRPCResponse)
+		assert.Nil(t, resp.Result.(mcp.InitializeResult).Capabilities.Completions)
+
+		errResp, ok := server.HandleMessage(context.Background(), complete).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.METHOD_NOT_FOUND, errResp.Error.Code)
+	})
+
+	t.Run("explicitly enabled", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithCompletions())
+		server.AddPrompt(mcp.NewPrompt("p"), nil)
+
+		resp := server.HandleMessage(context.Background(), initialize).(mcp.JSONRPCResponse)
+		assert.NotNil(t, resp.Result.(mcp.InitializeResult).Capabilities.Completions)
+
+		_, ok := server.HandleMessage(context.Background(), complete).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+
+	t.Run("implicitly enabled by a completer", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddPrompt(mcp.NewPrompt("p"), nil, WithArgumentCompleter("a",
+			func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+				return []string{"x"}, nil
+			}))
+
+		resp := server.HandleMessage(context.Background(), initialize).(mcp.JSONRPCResponse)
+		assert.NotNil(t, resp.Result.(mcp.InitializeResult).Capabilities.Completions)
+	})
+}
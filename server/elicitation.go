@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RequestElicitation asks the user, through the client of the current session,
+// to provide the data described by requestedSchema. The client must have
+// declared the elicitation capability during initialization, otherwise
+// ErrClientCapabilityNotSupported is returned.
+//
+// The returned result tells whether the user accepted, declined or cancelled
+// the request. On accept, its content holds the submitted data, which can be
+// decoded with BindContent.
+func (s *MCPServer) RequestElicitation(
+	ctx context.Context,
+	message string,
+	requestedSchema any,
+) (*mcp.ElicitationResult, error) {
+	session, ok := ClientSessionFromContext(ctx).(SessionWithClientCapabilities)
+	if ok && session.GetClientCapabilities().Elicitation == nil {
+		return nil, fmt.Errorf("%w: elicitation", ErrClientCapabilityNotSupported)
+	}
+	params := mcp.ElicitationParams{
+		Message:         message,
+		RequestedSchema: requestedSchema,
+	}
+	response, err := s.SendRequestToClient(ctx, mcp.MethodElicitationCreate, params)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.ElicitationResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal elicitation response: %w", err)
+	}
+
+	switch result.Action {
+	case mcp.ElicitationResponseActionAccept,
+		mcp.ElicitationResponseActionDecline,
+		mcp.ElicitationResponseActionCancel:
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("invalid elicitation action: %q", result.Action)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_RequestElicitation(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"environment": map[string]any{"type": "string"},
+			"replicas":    map[string]any{"type": "integer"},
+		},
+		"required": []string{"environment"},
+	}
+
+	tests := []struct {
+		name        string
+		session     ClientSession
+		expected    *mcp.ElicitationResult
+		expectedErr string
+	}{
+		{
+			name: "accept",
+			session: &fakeRequestSession{
+				fakeSession: fakeSession{sessionID: "s1"},
+				respond: func(method mcp.MCPMethod, params any) (any, error) {
+					if method != mcp.MethodElicitationCreate {
+						return nil, assert.AnError
+					}
+					request := params.(mcp.ElicitationParams)
+					if request.Message != "Confirm the deployment" || request.RequestedSchema == nil {
+						return nil, assert.AnError
+					}
+					return map[string]any{
+						"action":  "accept",
+						"content": map[string]any{"environment": "staging", "replicas": 3},
+					}, nil
+				},
+			},
+			expected: &mcp.ElicitationResult{
+				Action:  mcp.ElicitationResponseActionAccept,
+				Content: map[string]any{"environment": "staging", "replicas": float64(3)},
+			},
+		},
+		{
+			name: "decline",
+			session: &fakeRequestSession{
+				fakeSession: fakeSession{sessionID: "s1"},
+				respond: func(method mcp.MCPMethod, params any) (any, error) {
+					return map[string]any{"action": "decline"}, nil
+				},
+			},
+			expected: &mcp.ElicitationResult{Action: mcp.ElicitationResponseActionDecline},
+		},
+		{
+			name: "invalid action",
+			session: &fakeRequestSession{
+				fakeSession: fakeSession{sessionID: "s1"},
+				respond: func(method mcp.MCPMethod, params any) (any, error) {
+					return map[string]any{"action": "maybe"}, nil
+				},
+			},
+			expectedErr: `invalid elicitation action: "maybe"`,
+		},
+		{
+			name:        "session without request support",
+			session:     fakeSession{sessionID: "s1"},
+			expectedErr: ErrSessionDoesNotSupportRequests.Error(),
+		},
+		{
+			name: "client without the elicitation capability",
+			session: func() ClientSession {
+				session := NewInProcessSession("s1", nil)
+				session.SetClientCapabilities(mcp.ClientCapabilities{Sampling: &struct{}{}})
+				return session
+			}(),
+			expectedErr: "client does not support the capability: elicitation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0")
+			ctx := server.WithContext(context.Background(), tt.session)
+
+			result, err := server.RequestElicitation(ctx, "Confirm the deployment", schema)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
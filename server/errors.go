@@ -1,23 +1,43 @@
 package server
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 var (
 	// Common server errors
-	ErrUnsupported      = errors.New("not supported")
-	ErrResourceNotFound = errors.New("resource not found")
-	ErrPromptNotFound   = errors.New("prompt not found")
-	ErrToolNotFound     = errors.New("tool not found")
+	ErrUnsupported          = errors.New("not supported")
+	ErrResourceNotFound     = errors.New("resource not found")
+	ErrPromptNotFound       = errors.New("prompt not found")
+	ErrToolNotFound         = errors.New("tool not found")
+	ErrInvalidToolOutput    = errors.New("tool output does not match its output schema")
+	ErrInvalidToolArguments = errors.New("tool arguments do not match the input schema")
 
 	// Session-related errors
-	ErrSessionNotFound              = errors.New("session not found")
-	ErrSessionExists                = errors.New("session already exists")
-	ErrSessionNotInitialized        = errors.New("session not properly initialized")
-	ErrSessionDoesNotSupportTools   = errors.New("session does not support per-session tools")
-	ErrSessionDoesNotSupportLogging = errors.New("session does not support setting logging level")
+	ErrSessionNotFound                = errors.New("session not found")
+	ErrSessionExists                  = errors.New("session already exists")
+	ErrSessionNotInitialized          = errors.New("session not properly initialized")
+	ErrSessionDoesNotSupportTools     = errors.New("session does not support per-session tools")
+	ErrSessionDoesNotSupportLogging   = errors.New("session does not support setting logging level")
+	ErrSessionDoesNotSupportRequests  = errors.New("session does not support server-to-client requests")
+	ErrSessionDoesNotSupportResources = errors.New("session does not support per-session resources")
+	ErrSessionDoesNotSupportPrompts   = errors.New("session does not support per-session prompts")
+	ErrClientCapabilityNotSupported   = errors.New("client does not support the capability")
+
+	// Request-related errors
+	ErrRequestCancelled = errors.New("request cancelled")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrToolTimeout      = errors.New("tool call timed out")
+
+	// Authentication and authorization errors
+	ErrUnauthenticated   = errors.New("unauthenticated")
+	ErrInvalidToken      = errors.New("invalid access token")
+	ErrInsufficientScope = errors.New("insufficient scope")
+	ErrPolicyDenied      = errors.New("denied by policy")
 
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
@@ -32,3 +52,16 @@ type ErrDynamicPathConfig struct {
 func (e *ErrDynamicPathConfig) Error() string {
 	return fmt.Sprintf("%s cannot be used with WithDynamicBasePath. Use dynamic path logic in your router.", e.Method)
 }
+
+// ClientRequestError is returned when the client answers a request sent by the
+// server with an error
+type ClientRequestError struct {
+	Method  mcp.MCPMethod
+	Code    int
+	Message string
+	Data    json.RawMessage
+}
+
+func (e *ClientRequestError) Error() string {
+	return fmt.Sprintf("%s request failed: %s", e.Method, e.Message)
+}
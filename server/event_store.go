@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EventStore stores the messages sent on the SSE streams of a
+// StreamableHTTPServer, so that a client reconnecting with the Last-Event-ID
+// header receives the messages it missed.
+type EventStore interface {
+	// StoreEvent stores a message sent on the stream and returns the ID of the
+	// event carrying it.
+	StoreEvent(streamID string, message json.RawMessage) (eventID string, err error)
+	// StreamIDForEvent returns the stream an event was sent on.
+	// Returns err!=nil if the event ID is invalid.
+	StreamIDForEvent(eventID string) (streamID string, err error)
+	// ReplayEventsAfter calls send, in order, for every event stored on the
+	// stream of lastEventID after that event.
+	ReplayEventsAfter(lastEventID string, send func(eventID string, message json.RawMessage) error) error
+}
+
+// defaultMaxEvents is the number of events InMemoryEventStore keeps by default.
+const defaultMaxEvents = 1000
+
+// InMemoryEventStore is an EventStore keeping the most recent events of all
+// the streams in a ring buffer. Once evicted, an event can no longer be
+// replayed. Event IDs are made of the stream ID and a sequence number, which
+// increases with every event stored.
+type InMemoryEventStore struct {
+	mu       sync.Mutex
+	events   []storedEvent
+	next     int // the slot of the next event
+	sequence uint64
+}
+
+type storedEvent struct {
+	streamID string
+	sequence uint64
+	message  json.RawMessage
+}
+
+// NewInMemoryEventStore creates an event store keeping the last maxEvents
+// events. A non-positive maxEvents keeps the last 1000 events.
+func NewInMemoryEventStore(maxEvents int) *InMemoryEventStore {
+	if maxEvents <= 0 {
+		maxEvents = defaultMaxEvents
+	}
+	return &InMemoryEventStore{
+		events: make([]storedEvent, 0, maxEvents),
+	}
+}
+
+// StoreEvent implements EventStore.
+func (s *InMemoryEventStore) StoreEvent(streamID string, message json.RawMessage) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequence++
+	event := storedEvent{streamID: streamID, sequence: s.sequence, message: message}
+	if len(s.events) < cap(s.events) {
+		s.events = append(s.events, event)
+	} else {
+		s.events[s.next] = event
+	}
+	s.next = (s.next + 1) % cap(s.events)
+	return fmt.Sprintf("%s_%d", streamID, event.sequence), nil
+}
+
+// StreamIDForEvent implements EventStore.
+func (s *InMemoryEventStore) StreamIDForEvent(eventID string) (string, error) {
+	streamID, _, err := parseEventID(eventID)
+	return streamID, err
+}
+
+// ReplayEventsAfter implements EventStore.
+func (s *InMemoryEventStore) ReplayEventsAfter(
+	lastEventID string,
+	send func(eventID string, message json.RawMessage) error,
+) error {
+	streamID, after, err := parseEventID(lastEventID)
+	if err != nil {
+		return err
+	}
+
+	// collect the events first, so that send is not called with the lock held
+	s.mu.Lock()
+	var events []storedEvent
+	for i := range s.events {
+		// the oldest event is in the next slot once the buffer is full
+		event := s.events[(s.next+i)%len(s.events)]
+		if event.streamID == streamID && event.sequence > after {
+			events = append(events, event)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, event := range events {
+		if err := send(fmt.Sprintf("%s_%d", event.streamID, event.sequence), event.message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ EventStore = (*InMemoryEventStore)(nil)
+
+func parseEventID(eventID string) (streamID string, sequence uint64, err error) {
+	i := strings.LastIndex(eventID, "_")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid event id: %s", eventID)
+	}
+	sequence, err = strconv.ParseUint(eventID[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid event id: %s", eventID)
+	}
+	return eventID[:i], sequence, nil
+}
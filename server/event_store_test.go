@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryEventStore(t *testing.T) {
+	store := NewInMemoryEventStore(3)
+
+	first, err := store.StoreEvent("a", json.RawMessage(`1`))
+	require.NoError(t, err)
+	_, err = store.StoreEvent("b", json.RawMessage(`2`))
+	require.NoError(t, err)
+	_, err = store.StoreEvent("a", json.RawMessage(`3`))
+	require.NoError(t, err)
+
+	streamID, err := store.StreamIDForEvent(first)
+	require.NoError(t, err)
+	assert.Equal(t, "a", streamID)
+
+	replay := func(lastEventID string) []string {
+		var messages []string
+		require.NoError(t, store.ReplayEventsAfter(lastEventID, func(eventID string, message json.RawMessage) error {
+			messages = append(messages, string(message))
+			return nil
+		}))
+		return messages
+	}
+	assert.Equal(t, []string{"3"}, replay(first))
+
+	// the oldest events are evicted once the buffer is full
+	_, err = store.StoreEvent("a", json.RawMessage(`4`))
+	require.NoError(t, err)
+	_, err = store.StoreEvent("a", json.RawMessage(`5`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4", "5"}, replay(first))
+
+	_, err = store.StreamIDForEvent("invalid")
+	assert.Error(t, err)
+	assert.Error(t, store.ReplayEventsAfter("a_x", func(string, json.RawMessage) error { return nil }))
+}
+
+// sseEvent is an event read from an SSE stream.
+type sseEvent struct {
+	id   string
+	data string
+}
+
+func readStreamEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if event.data != "" {
+				return event
+			}
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamableHTTP_Resumability(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	release := make(chan struct{})
+	mcpServer.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_ = ServerFromContext(ctx).SendNotificationToClient(ctx, "test/progress", map[string]any{"step": 1})
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer, WithEventStore(NewInMemoryEventStore(0))))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	send := func(ctx context.Context, method string, lastEventID string, body string) *http.Response {
+		req, err := http.NewRequestWithContext(ctx, method, server.URL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		if lastEventID != "" {
+			req.Header.Set(headerKeyLastEventID, lastEventID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("GET stream replays the notifications sent while disconnected", func(t *testing.T) {
+		notify := func(value string) {
+			require.Eventually(t, func() bool {
+				return mcpServer.SendNotificationToSpecificClient(sessionID, "test/notification", map[string]any{"value": value}) == nil
+			}, time.Second, 10*time.Millisecond)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := send(ctx, http.MethodGet, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		notify("before")
+		first := readStreamEvent(t, bufio.NewReader(resp.Body))
+		assert.Contains(t, first.data, "before")
+		require.NotEmpty(t, first.id)
+		cancel()
+		resp.Body.Close()
+
+		// the session outlives the connection, storing what is sent meanwhile
+		notify("missed")
+
+		resp = send(context.Background(), http.MethodGet, first.id, "")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		reader := bufio.NewReader(resp.Body)
+		missed := readStreamEvent(t, reader)
+		assert.Contains(t, missed.data, "missed")
+
+		notify("after")
+		assert.Contains(t, readStreamEvent(t, reader).data, "after")
+	})
+
+	t.Run("POST stream replays the response", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := send(ctx, http.MethodPost, "", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow"}}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		progress := readStreamEvent(t, bufio.NewReader(resp.Body))
+		assert.Contains(t, progress.data, "test/progress")
+		cancel()
+		resp.Body.Close()
+
+		// the request keeps being handled after the disconnection
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+		}()
+
+		resp = send(context.Background(), http.MethodGet, progress.id, "")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var response jsonRPCResponse
+		require.NoError(t, json.Unmarshal([]byte(readStreamEvent(t, bufio.NewReader(resp.Body)).data), &response))
+		assert.Equal(t, 2, response.ID)
+		assert.Equal(t, "done", response.Result["content"].([]any)[0].(map[string]any)["text"])
+	})
+
+	t.Run("invalid Last-Event-ID", func(t *testing.T) {
+		resp := send(context.Background(), http.MethodGet, "invalid", "")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = send(context.Background(), http.MethodGet, "mcp-session-other_1", "")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
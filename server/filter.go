@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResourceFilterFunc is a function that filters resources based on context, typically using session information.
+type ResourceFilterFunc func(ctx context.Context, resources []mcp.Resource) []mcp.Resource
+
+// ResourceTemplateFilterFunc is a function that filters resource templates based on context.
+type ResourceTemplateFilterFunc func(ctx context.Context, templates []mcp.ResourceTemplate) []mcp.ResourceTemplate
+
+// PromptFilterFunc is a function that filters prompts based on context, typically using session information.
+type PromptFilterFunc func(ctx context.Context, prompts []mcp.Prompt) []mcp.Prompt
+
+// WithResourceFilter adds a filter function that will be applied to resources before they are returned in resources/list
+func WithResourceFilter(resourceFilter ResourceFilterFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.filtersMu.Lock()
+		s.resourceFilters = append(s.resourceFilters, resourceFilter)
+		s.filtersMu.Unlock()
+	}
+}
+
+// WithResourceTemplateFilter adds a filter function that will be applied to
+// resource templates before they are returned in resources/templates/list
+func WithResourceTemplateFilter(templateFilter ResourceTemplateFilterFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.filtersMu.Lock()
+		s.templateFilters = append(s.templateFilters, templateFilter)
+		s.filtersMu.Unlock()
+	}
+}
+
+// WithPromptFilter adds a filter function that will be applied to prompts before they are returned in prompts/list
+func WithPromptFilter(promptFilter PromptFilterFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.filtersMu.Lock()
+		s.promptFilters = append(s.promptFilters, promptFilter)
+		s.filtersMu.Unlock()
+	}
+}
+
+// WithFilterEnforcement makes the tool, resource, resource template and prompt
+// filters authoritative: tools/call, resources/read, prompts/get and
+// completion/complete then fail as if the item did not exist when the filters
+// would hide it from the lists. The filters are given a list holding only the
+// requested item.
+func WithFilterEnforcement() ServerOption {
+	return func(s *MCPServer) {
+		s.enforceFilters = true
+	}
+}
+
+// applyFilters passes items through the filters in order
+func applyFilters[T any, F ~func(context.Context, []T) []T](ctx context.Context, filters []F, items []T) []T {
+	for _, filter := range filters {
+		items = filter(ctx, items)
+	}
+	return items
+}
+
+// filterAllows reports whether the filters keep item, identified by key. It
+// always does when filters are not enforced.
+func filterAllows[T any, F ~func(context.Context, []T) []T](
+	ctx context.Context,
+	s *MCPServer,
+	filters []F,
+	item T,
+	key func(T) string,
+) bool {
+	if !s.enforceFilters || len(filters) == 0 {
+		return true
+	}
+	for _, kept := range applyFilters(ctx, filters, []T{item}) {
+		if key(kept) == key(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MCPServer) filterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	s.filtersMu.RLock()
+	filters := s.toolFilters
+	s.filtersMu.RUnlock()
+	return hideDenied(ctx, s, applyFilters(ctx, filters, tools), func(tool *mcp.Tool) PolicyRequest {
+		return PolicyRequest{Method: mcp.MethodToolsCall, Name: tool.Name, Tool: tool}
+	})
+}
+
+func (s *MCPServer) filterResources(ctx context.Context, resources []mcp.Resource) []mcp.Resource {
+	s.filtersMu.RLock()
+	filters := s.resourceFilters
+	s.filtersMu.RUnlock()
+	return hideDenied(ctx, s, applyFilters(ctx, filters, resources), func(resource *mcp.Resource) PolicyRequest {
+		return PolicyRequest{Method: mcp.MethodResourcesRead, Name: resource.URI}
+	})
+}
+
+func (s *MCPServer) filterResourceTemplates(ctx context.Context, templates []mcp.ResourceTemplate) []mcp.ResourceTemplate {
+	s.filtersMu.RLock()
+	filters := s.templateFilters
+	s.filtersMu.RUnlock()
+	return hideDenied(ctx, s, applyFilters(ctx, filters, templates), func(template *mcp.ResourceTemplate) PolicyRequest {
+		return PolicyRequest{Method: mcp.MethodResourcesRead, Name: template.URITemplate.Raw()}
+	})
+}
+
+func (s *MCPServer) filterPrompts(ctx context.Context, prompts []mcp.Prompt) []mcp.Prompt {
+	s.filtersMu.RLock()
+	filters := s.promptFilters
+	s.filtersMu.RUnlock()
+	return hideDenied(ctx, s, applyFilters(ctx, filters, prompts), func(prompt *mcp.Prompt) PolicyRequest {
+		return PolicyRequest{Method: mcp.MethodPromptsGet, Name: prompt.Name}
+	})
+}
+
+func (s *MCPServer) toolAllowed(ctx context.Context, tool mcp.Tool) bool {
+	s.filtersMu.RLock()
+	filters := s.toolFilters
+	s.filtersMu.RUnlock()
+	return filterAllows(ctx, s, filters, tool, func(t mcp.Tool) string { return t.Name })
+}
+
+func (s *MCPServer) resourceAllowed(ctx context.Context, resource mcp.Resource) bool {
+	s.filtersMu.RLock()
+	filters := s.resourceFilters
+	s.filtersMu.RUnlock()
+	return filterAllows(ctx, s, filters, resource, func(r mcp.Resource) string { return r.URI })
+}
+
+func (s *MCPServer) resourceTemplateAllowed(ctx context.Context, template mcp.ResourceTemplate) bool {
+	s.filtersMu.RLock()
+	filters := s.templateFilters
+	s.filtersMu.RUnlock()
+	return filterAllows(ctx, s, filters, template, func(t mcp.ResourceTemplate) string { return t.URITemplate.Raw() })
+}
+
+func (s *MCPServer) promptAllowed(ctx context.Context, prompt mcp.Prompt) bool {
+	s.filtersMu.RLock()
+	filters := s.promptFilters
+	s.filtersMu.RUnlock()
+	return filterAllows(ctx, s, filters, prompt, func(p mcp.Prompt) string { return p.Name })
+}
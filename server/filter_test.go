@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roleKey struct{}
+
+// adminOnly keeps the items whose key starts with "admin" for admins only.
+func adminOnly[T any](key func(T) string) func(ctx context.Context, items []T) []T {
+	return func(ctx context.Context, items []T) []T {
+		if ctx.Value(roleKey{}) == "admin" {
+			return items
+		}
+		var kept []T
+		for _, item := range items {
+			if !strings.HasPrefix(key(item), "admin") {
+				kept = append(kept, item)
+			}
+		}
+		return kept
+	}
+}
+
+func newFilteredServer(opts ...ServerOption) *MCPServer {
+	opts = append(opts,
+		WithToolFilter(adminOnly(func(t mcp.Tool) string { return t.Name })),
+		WithResourceFilter(adminOnly(func(r mcp.Resource) string { return r.Name })),
+		WithResourceTemplateFilter(adminOnly(func(t mcp.ResourceTemplate) string { return t.Name })),
+		WithPromptFilter(adminOnly(func(p mcp.Prompt) string { return p.Name })),
+	)
+	server := NewMCPServer("test-server", "1.0.0", opts...)
+	for _, name := range []string{"admin-tool", "tool"} {
+		server.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	server.AddResource(mcp.NewResource("file:///admin.txt", "admin-resource"), textResource("admin"))
+	server.AddResource(mcp.NewResource("file:///public.txt", "resource"), textResource("public"))
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///admin/{id}", "admin-template"), textResource("admin"),
+		WithArgumentCompleter("id", func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+			return []string{"1"}, nil
+		}))
+	server.AddPrompt(mcp.NewPrompt("admin-prompt"), textPrompt("admin"))
+	server.AddPrompt(mcp.NewPrompt("prompt"), textPrompt("public"))
+	return server
+}
+
+func TestMCPServer_ListFilters(t *testing.T) {
+	server := newFilteredServer()
+	userCtx := context.WithValue(context.Background(), roleKey{}, "user")
+	adminCtx := context.WithValue(context.Background(), roleKey{}, "admin")
+
+	tests := []struct {
+		method string
+		names  func(result any) []string
+		user   []string
+		admin  []string
+	}{
+		{
+			method: "tools/list",
+			names: func(result any) []string {
+				var names []string
+				for _, tool := range result.(mcp.ListToolsResult).Tools {
+					names = append(names, tool.Name)
+				}
+				return names
+			},
+			user:  []string{"tool"},
+			admin: []string{"admin-tool", "tool"},
+		},
+		{
+			method: "resources/list",
+			names: func(result any) []string {
+				var names []string
+				for _, resource := range result.(mcp.ListResourcesResult).Resources {
+					names = append(names, resource.Name)
+				}
+				return names
+			},
+			user:  []string{"resource"},
+			admin: []string{"admin-resource", "resource"},
+		},
+		{
+			method: "resources/templates/list",
+			names: func(result any) []string {
+				var names []string
+				for _, template := range result.(mcp.ListResourceTemplatesResult).ResourceTemplates {
+					names = append(names, template.Name)
+				}
+				return names
+			},
+			user:  nil,
+			admin: []string{"admin-template"},
+		},
+		{
+			method: "prompts/list",
+			names: func(result any) []string {
+				var names []string
+				for _, prompt := range result.(mcp.ListPromptsResult).Prompts {
+					names = append(names, prompt.Name)
+				}
+				return names
+			},
+			user:  []string{"prompt"},
+			admin: []string{"admin-prompt", "prompt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			message := []byte(`{"jsonrpc":"2.0","id":1,"method":"` + tt.method + `"}`)
+			response, ok := server.HandleMessage(userCtx, message).(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			assert.Equal(t, tt.user, tt.names(response.Result))
+			response, ok = server.HandleMessage(adminCtx, message).(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			assert.Equal(t, tt.admin, tt.names(response.Result))
+		})
+	}
+}
+
+func TestMCPServer_FilterEnforcement(t *testing.T) {
+	userCtx := context.WithValue(context.Background(), roleKey{}, "user")
+	adminCtx := context.WithValue(context.Background(), roleKey{}, "admin")
+	messages := map[string]struct {
+		message string
+		code    int
+	}{
+		"tools/call":          {`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"admin-tool"}}`, mcp.INVALID_PARAMS},
+		"resources/read":      {`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file:///admin.txt"}}`, mcp.RESOURCE_NOT_FOUND},
+		"template read":       {`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"file:///admin/1"}}`, mcp.RESOURCE_NOT_FOUND},
+		"prompts/get":         {`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"admin-prompt"}}`, mcp.INVALID_PARAMS},
+		"prompt completion":   {`{"jsonrpc":"2.0","id":5,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"admin-prompt"},"argument":{"name":"a","value":""}}}`, mcp.INVALID_PARAMS},
+		"template completion": {`{"jsonrpc":"2.0","id":6,"method":"completion/complete","params":{"ref":{"type":"ref/resource","uri":"file:///admin/{id}"},"argument":{"name":"id","value":""}}}`, mcp.INVALID_PARAMS},
+	}
+
+	t.Run("filters only apply to lists by default", func(t *testing.T) {
+		server := newFilteredServer()
+		for name, tt := range messages {
+			_, ok := server.HandleMessage(userCtx, []byte(tt.message)).(mcp.JSONRPCResponse)
+			assert.True(t, ok, name)
+		}
+	})
+
+	t.Run("enforced filters reject hidden items", func(t *testing.T) {
+		server := newFilteredServer(WithFilterEnforcement())
+		for name, tt := range messages {
+			errorResponse, ok := server.HandleMessage(userCtx, []byte(tt.message)).(mcp.JSONRPCError)
+			require.True(t, ok, name)
+			assert.Equal(t, tt.code, errorResponse.Error.Code, name)
+
+			_, ok = server.HandleMessage(adminCtx, []byte(tt.message)).(mcp.JSONRPCResponse)
+			assert.True(t, ok, name)
+		}
+
+		// visible items are unaffected
+		_, ok := server.HandleMessage(userCtx, []byte(
+			`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"tool"}}`)).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+}
@@ -14,6 +14,14 @@ type OnRegisterSessionHookFunc func(ctx context.Context, session ClientSession)
 // OnUnregisterSessionHookFunc is a hook that will be called when a session is being unregistered.
 type OnUnregisterSessionHookFunc func(ctx context.Context, session ClientSession)
 
+// OnRootsChangedHookFunc is a hook that will be called when the roots of a
+// session have been refreshed and differ from the previously known ones.
+type OnRootsChangedHookFunc func(ctx context.Context, session ClientSession, roots []mcp.Root)
+
+// OnRateLimitHookFunc is a hook that will be called whenever a request is
+// counted against a rate limit, with the resulting state of the limit.
+type OnRateLimitHookFunc func(ctx context.Context, event RateLimitEvent)
+
 // BeforeAnyHookFunc is a function that is called after the request is
 // parsed but before the method is called.
 type BeforeAnyHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any)
@@ -53,6 +61,8 @@ type OnSuccessHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, m
 //	    log.Printf("Prompt not found: %v", err)
 //	  case errors.Is(err, ErrToolNotFound):
 //	    log.Printf("Tool not found: %v", err)
+//	  case errors.Is(err, ErrPolicyDenied):
+//	    log.Printf("Denied by policy: %v", err)
 //	  }
 //	})
 type OnErrorHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error)
@@ -79,6 +89,12 @@ type OnAfterListResourceTemplatesFunc func(ctx context.Context, id any, message
 type OnBeforeReadResourceFunc func(ctx context.Context, id any, message *mcp.ReadResourceRequest)
 type OnAfterReadResourceFunc func(ctx context.Context, id any, message *mcp.ReadResourceRequest, result *mcp.ReadResourceResult)
 
+type OnBeforeSubscribeFunc func(ctx context.Context, id any, message *mcp.SubscribeRequest)
+type OnAfterSubscribeFunc func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult)
+
+type OnBeforeUnsubscribeFunc func(ctx context.Context, id any, message *mcp.UnsubscribeRequest)
+type OnAfterUnsubscribeFunc func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult)
+
 type OnBeforeListPromptsFunc func(ctx context.Context, id any, message *mcp.ListPromptsRequest)
 type OnAfterListPromptsFunc func(ctx context.Context, id any, message *mcp.ListPromptsRequest, result *mcp.ListPromptsResult)
 
@@ -91,9 +107,14 @@ type OnAfterListToolsFunc func(ctx context.Context, id any, message *mcp.ListToo
 type OnBeforeCallToolFunc func(ctx context.Context, id any, message *mcp.CallToolRequest)
 type OnAfterCallToolFunc func(ctx context.Context, id any, message *mcp.CallToolRequest, result *mcp.CallToolResult)
 
+type OnBeforeCompleteFunc func(ctx context.Context, id any, message *mcp.CompleteRequest)
+type OnAfterCompleteFunc func(ctx context.Context, id any, message *mcp.CompleteRequest, result *mcp.CompleteResult)
+
 type Hooks struct {
 	OnRegisterSession             []OnRegisterSessionHookFunc
 	OnUnregisterSession           []OnUnregisterSessionHookFunc
+	OnRootsChanged                []OnRootsChangedHookFunc
+	OnRateLimit                   []OnRateLimitHookFunc
 	OnBeforeAny                   []BeforeAnyHookFunc
 	OnSuccess                     []OnSuccessHookFunc
 	OnError                       []OnErrorHookFunc
@@ -110,6 +131,10 @@ type Hooks struct {
 	OnAfterListResourceTemplates  []OnAfterListResourceTemplatesFunc
 	OnBeforeReadResource          []OnBeforeReadResourceFunc
 	OnAfterReadResource           []OnAfterReadResourceFunc
+	OnBeforeSubscribe             []OnBeforeSubscribeFunc
+	OnAfterSubscribe              []OnAfterSubscribeFunc
+	OnBeforeUnsubscribe           []OnBeforeUnsubscribeFunc
+	OnAfterUnsubscribe            []OnAfterUnsubscribeFunc
 	OnBeforeListPrompts           []OnBeforeListPromptsFunc
 	OnAfterListPrompts            []OnAfterListPromptsFunc
 	OnBeforeGetPrompt             []OnBeforeGetPromptFunc
@@ -118,6 +143,8 @@ type Hooks struct {
 	OnAfterListTools              []OnAfterListToolsFunc
 	OnBeforeCallTool              []OnBeforeCallToolFunc
 	OnAfterCallTool               []OnAfterCallToolFunc
+	OnBeforeComplete              []OnBeforeCompleteFunc
+	OnAfterComplete               []OnAfterCompleteFunc
 }
 
 func (c *Hooks) AddBeforeAny(hook BeforeAnyHookFunc) {
@@ -209,6 +236,7 @@ func (c *Hooks) onSuccess(ctx context.Context, id any, method mcp.MCPMethod, mes
 // - ErrResourceNotFound: When a resource is not found
 // - ErrPromptNotFound: When a prompt is not found
 // - ErrToolNotFound: When a tool is not found
+// - ErrPolicyDenied: When a policy denies the request
 func (c *Hooks) onError(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
 	if c == nil {
 		return
@@ -244,6 +272,32 @@ func (c *Hooks) UnregisterSession(ctx context.Context, session ClientSession) {
 	}
 }
 
+func (c *Hooks) AddOnRootsChanged(hook OnRootsChangedHookFunc) {
+	c.OnRootsChanged = append(c.OnRootsChanged, hook)
+}
+
+func (c *Hooks) rootsChanged(ctx context.Context, session ClientSession, roots []mcp.Root) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnRootsChanged {
+		hook(ctx, session, roots)
+	}
+}
+
+func (c *Hooks) AddOnRateLimit(hook OnRateLimitHookFunc) {
+	c.OnRateLimit = append(c.OnRateLimit, hook)
+}
+
+func (c *Hooks) rateLimit(ctx context.Context, event RateLimitEvent) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnRateLimit {
+		hook(ctx, event)
+	}
+}
+
 func (c *Hooks) AddOnRequestInitialization(hook OnRequestInitializationFunc) {
 	c.OnRequestInitialization = append(c.OnRequestInitialization, hook)
 }
@@ -422,6 +476,60 @@ func (c *Hooks) afterReadResource(ctx context.Context, id any, message *mcp.Read
 		hook(ctx, id, message, result)
 	}
 }
+func (c *Hooks) AddBeforeSubscribe(hook OnBeforeSubscribeFunc) {
+	c.OnBeforeSubscribe = append(c.OnBeforeSubscribe, hook)
+}
+
+func (c *Hooks) AddAfterSubscribe(hook OnAfterSubscribeFunc) {
+	c.OnAfterSubscribe = append(c.OnAfterSubscribe, hook)
+}
+
+func (c *Hooks) beforeSubscribe(ctx context.Context, id any, message *mcp.SubscribeRequest) {
+	c.beforeAny(ctx, id, mcp.MethodResourcesSubscribe, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeSubscribe {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterSubscribe(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+	c.onSuccess(ctx, id, mcp.MethodResourcesSubscribe, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterSubscribe {
+		hook(ctx, id, message, result)
+	}
+}
+func (c *Hooks) AddBeforeUnsubscribe(hook OnBeforeUnsubscribeFunc) {
+	c.OnBeforeUnsubscribe = append(c.OnBeforeUnsubscribe, hook)
+}
+
+func (c *Hooks) AddAfterUnsubscribe(hook OnAfterUnsubscribeFunc) {
+	c.OnAfterUnsubscribe = append(c.OnAfterUnsubscribe, hook)
+}
+
+func (c *Hooks) beforeUnsubscribe(ctx context.Context, id any, message *mcp.UnsubscribeRequest) {
+	c.beforeAny(ctx, id, mcp.MethodResourcesUnsubscribe, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeUnsubscribe {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterUnsubscribe(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+	c.onSuccess(ctx, id, mcp.MethodResourcesUnsubscribe, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterUnsubscribe {
+		hook(ctx, id, message, result)
+	}
+}
 func (c *Hooks) AddBeforeListPrompts(hook OnBeforeListPromptsFunc) {
 	c.OnBeforeListPrompts = append(c.OnBeforeListPrompts, hook)
 }
@@ -530,3 +638,30 @@ func (c *Hooks) afterCallTool(ctx context.Context, id any, message *mcp.CallTool
 		hook(ctx, id, message, result)
 	}
 }
+func (c *Hooks) AddBeforeComplete(hook OnBeforeCompleteFunc) {
+	c.OnBeforeComplete = append(c.OnBeforeComplete, hook)
+}
+
+func (c *Hooks) AddAfterComplete(hook OnAfterCompleteFunc) {
+	c.OnAfterComplete = append(c.OnAfterComplete, hook)
+}
+
+func (c *Hooks) beforeComplete(ctx context.Context, id any, message *mcp.CompleteRequest) {
+	c.beforeAny(ctx, id, mcp.MethodCompletionComplete, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeComplete {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterComplete(ctx context.Context, id any, message *mcp.CompleteRequest, result *mcp.CompleteResult) {
+	c.onSuccess(ctx, id, mcp.MethodCompletionComplete, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterComplete {
+		hook(ctx, id, message, result)
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -15,14 +16,25 @@ type SamplingHandler interface {
 	CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
 }
 
+// InProcessRequestHandler handles a request sent by the server to an
+// in-process client, returning the raw JSON result.
+type InProcessRequestHandler func(ctx context.Context, method string, params any) (json.RawMessage, error)
+
 type InProcessSession struct {
-	sessionID       string
-	notifications   chan mcp.JSONRPCNotification
-	initialized     atomic.Bool
-	loggingLevel    atomic.Value
-	clientInfo      atomic.Value
-	samplingHandler SamplingHandler
-	mu              sync.RWMutex
+	sessionID          string
+	notifications      chan mcp.JSONRPCNotification
+	initialized        atomic.Bool
+	loggingLevel       atomic.Value
+	clientInfo         atomic.Value
+	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value
+	principal          atomic.Pointer[Principal]
+	resources          sessionItems[ServerResource]
+	resourceTemplates  sessionItems[ServerResourceTemplate]
+	prompts            sessionItems[ServerPrompt]
+	samplingHandler    SamplingHandler
+	requestHandler     InProcessRequestHandler
+	mu                 sync.RWMutex
 }
 
 func NewInProcessSession(sessionID string, samplingHandler SamplingHandler) *InProcessSession {
@@ -41,6 +53,12 @@ func (s *InProcessSession) NotificationChannel() chan<- mcp.JSONRPCNotification
 	return s.notifications
 }
 
+// Notifications returns the channel on which the server delivers notifications
+// for this session. It is drained by the in-process transport.
+func (s *InProcessSession) Notifications() <-chan mcp.JSONRPCNotification {
+	return s.notifications
+}
+
 func (s *InProcessSession) Initialize() {
 	s.loggingLevel.Store(mcp.LoggingLevelError)
 	s.initialized.Store(true)
@@ -63,6 +81,37 @@ func (s *InProcessSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *InProcessSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if capabilities, ok := s.clientCapabilities.Load().(mcp.ClientCapabilities); ok {
+		return capabilities
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *InProcessSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(capabilities)
+}
+
+func (s *InProcessSession) GetPrincipal() *Principal {
+	return s.principal.Load()
+}
+
+// SetPrincipal sets the principal the in-process client acts on behalf of.
+func (s *InProcessSession) SetPrincipal(principal *Principal) {
+	s.principal.Store(principal)
+}
+
+func (s *InProcessSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *InProcessSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *InProcessSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -80,6 +129,9 @@ func (s *InProcessSession) RequestSampling(ctx context.Context, request mcp.Crea
 	handler := s.samplingHandler
 	s.mu.RUnlock()
 
+	if handler == nil {
+		handler = InProcessSamplingHandlerFromContext(ctx)
+	}
 	if handler == nil {
 		return nil, fmt.Errorf("no sampling handler available")
 	}
@@ -87,15 +139,66 @@ func (s *InProcessSession) RequestSampling(ctx context.Context, request mcp.Crea
 	return handler.CreateMessage(ctx, request)
 }
 
+// SetRequestHandler sets the handler of the requests sent by the server to the
+// client, such as roots/list.
+func (s *InProcessSession) SetRequestHandler(handler InProcessRequestHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestHandler = handler
+}
+
+// SendRequest passes a request to the request handler of the in-process
+// client and returns its result.
+func (s *InProcessSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	s.mu.RLock()
+	handler := s.requestHandler
+	s.mu.RUnlock()
+
+	if handler == nil {
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+	return handler(ctx, string(method), params)
+}
+
 // GenerateInProcessSessionID generates a unique session ID for inprocess clients
 func GenerateInProcessSessionID() string {
 	return fmt.Sprintf("inprocess-%d", time.Now().UnixNano())
 }
 
 // Ensure interface compliance
+func (s *InProcessSession) GetSessionResources() map[string]ServerResource {
+	return s.resources.get()
+}
+
+func (s *InProcessSession) SetSessionResources(resources map[string]ServerResource) {
+	s.resources.set(resources)
+}
+
+func (s *InProcessSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.resourceTemplates.get()
+}
+
+func (s *InProcessSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.resourceTemplates.set(templates)
+}
+
+func (s *InProcessSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.prompts.get()
+}
+
+func (s *InProcessSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.prompts.set(prompts)
+}
+
 var (
-	_ ClientSession         = (*InProcessSession)(nil)
-	_ SessionWithLogging    = (*InProcessSession)(nil)
-	_ SessionWithClientInfo = (*InProcessSession)(nil)
-	_ SessionWithSampling   = (*InProcessSession)(nil)
+	_ ClientSession                 = (*InProcessSession)(nil)
+	_ SessionWithLogging            = (*InProcessSession)(nil)
+	_ SessionWithClientInfo         = (*InProcessSession)(nil)
+	_ SessionWithClientCapabilities = (*InProcessSession)(nil)
+	_ SessionWithProtocolVersion    = (*InProcessSession)(nil)
+	_ SessionWithSampling           = (*InProcessSession)(nil)
+	_ SessionWithRequests           = (*InProcessSession)(nil)
+	_ SessionWithResources          = (*InProcessSession)(nil)
+	_ SessionWithPrompts            = (*InProcessSession)(nil)
+	_ SessionWithPrincipal          = (*InProcessSession)(nil)
 )
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenIntrospector returns the introspection response of an access token,
+// as defined by RFC 7662. It is typically backed by the introspection
+// endpoint of the authorization server, or by a cache in front of it.
+type TokenIntrospector interface {
+	IntrospectToken(ctx context.Context, token string) (map[string]any, error)
+}
+
+// IntrospectionVerifier is a TokenVerifier accepting the tokens a
+// TokenIntrospector reports as active.
+type IntrospectionVerifier struct {
+	introspector TokenIntrospector
+	validation   tokenValidation
+}
+
+// NewIntrospectionVerifier creates a verifier of the tokens introspected with
+// introspector.
+func NewIntrospectionVerifier(introspector TokenIntrospector, opts ...TokenValidationOption) *IntrospectionVerifier {
+	return &IntrospectionVerifier{
+		introspector: introspector,
+		validation:   newTokenValidation(opts),
+	}
+}
+
+// VerifyToken implements TokenVerifier.
+func (v *IntrospectionVerifier) VerifyToken(ctx context.Context, token string) (*TokenClaims, error) {
+	response, err := v.introspector.IntrospectToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: introspection failed: %w", ErrInvalidToken, err)
+	}
+	if active, _ := response["active"].(bool); !active {
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+	return v.validation.validate(response)
+}
+
+var _ TokenVerifier = (*IntrospectionVerifier)(nil)
+
+// HTTPTokenIntrospector is a TokenIntrospector calling the introspection
+// endpoint of an authorization server, authenticated with the client
+// credentials of the MCP server if set.
+type HTTPTokenIntrospector struct {
+	Endpoint     string
+	ClientID     string
+	ClientSecret string
+	// HTTPClient is the client used to call the endpoint, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// IntrospectToken implements TokenIntrospector.
+func (i *HTTPTokenIntrospector) IntrospectToken(ctx context.Context, token string) (map[string]any, error) {
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+	}
+
+	client := i.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send introspection request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var response map[string]any
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return response, nil
+}
+
+var _ TokenIntrospector = (*HTTPTokenIntrospector)(nil)
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// JSONWebKeySet is a set of keys verifying the signatures of JWT access
+// tokens, parsed from a JWK Set document (RFC 7517).
+type JSONWebKeySet struct {
+	keys []jsonWebKey
+}
+
+type jsonWebKey struct {
+	id        string
+	algorithm string
+	use       string
+	key       any // *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey or []byte
+}
+
+// rawJSONWebKey holds the members of a JWK used to build the key.
+type rawJSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	K         string `json:"k"`
+}
+
+// ParseJSONWebKeySet parses a JWK Set document. RSA, EC (P-256, P-384 and
+// P-521), Ed25519 and symmetric keys are supported, keys of other types are
+// ignored as RFC 7517 mandates.
+func ParseJSONWebKeySet(data []byte) (*JSONWebKeySet, error) {
+	var document struct {
+		Keys []rawJSONWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWK set: %w", err)
+	}
+
+	set := &JSONWebKeySet{}
+	for i, raw := range document.Keys {
+		key, err := raw.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %d: %w", i, err)
+		}
+		if key == nil {
+			continue
+		}
+		set.keys = append(set.keys, jsonWebKey{
+			id:        raw.KeyID,
+			algorithm: raw.Algorithm,
+			use:       raw.Use,
+			key:       key,
+		})
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("invalid JWK set: no supported key")
+	}
+	return set, nil
+}
+
+// publicKey returns the key of the JWK, or nil if its type is not supported.
+func (k rawJSONWebKey) publicKey() (any, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Curve {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid coordinates length")
+		}
+		// ecdh validates that the point is on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid symmetric key")
+		}
+		return secret, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// JWTVerifier is a TokenVerifier for JWT access tokens signed with the keys
+// of a JSONWebKeySet. Tokens must carry an exp claim.
+type JWTVerifier struct {
+	keys       *JSONWebKeySet
+	validation tokenValidation
+}
+
+// NewJWTVerifier creates a verifier of the tokens signed with keys.
+func NewJWTVerifier(keys *JSONWebKeySet, opts ...TokenValidationOption) *JWTVerifier {
+	return &JWTVerifier{
+		keys:       keys,
+		validation: newTokenValidation(opts),
+	}
+}
+
+// VerifyToken implements TokenVerifier.
+func (v *JWTVerifier) VerifyToken(ctx context.Context, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT signature", ErrInvalidToken)
+	}
+	if err := v.keys.verify(header.Algorithm, header.KeyID, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var claims map[string]any
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT claims", ErrInvalidToken)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	return v.validation.validate(claims)
+}
+
+var _ TokenVerifier = (*JWTVerifier)(nil)
+
+func decodeJWTSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// jwtAlgorithms are the supported signature algorithms and their hash. EdDSA
+// hashes the signing input itself.
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// ecdsaCurves are the curves of the ECDSA algorithms
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verify checks the signature with the keys usable for the algorithm, only
+// the ones of the given ID if set.
+func (s *JSONWebKeySet) verify(algorithm string, keyID string, signingInput []byte, signature []byte) error {
+	hash, ok := jwtAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	tried := false
+	for _, key := range s.keys {
+		if keyID != "" && key.id != keyID {
+			continue
+		}
+		if (key.algorithm != "" && key.algorithm != algorithm) || (key.use != "" && key.use != "sig") {
+			continue
+		}
+		valid, usable := verifySignature(algorithm, hash, key.key, signingInput, signature)
+		if valid {
+			return nil
+		}
+		tried = tried || usable
+	}
+	if !tried {
+		return fmt.Errorf("no key for algorithm %q", algorithm)
+	}
+	return errors.New("invalid signature")
+}
+
+// verifySignature reports whether the signature is valid, and whether the key
+// can be used with the algorithm at all.
+func verifySignature(algorithm string, hash crypto.Hash, key any, signingInput []byte, signature []byte) (valid bool, usable bool) {
+	if algorithm == "EdDSA" {
+		publicKey, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(publicKey, signingInput, signature), ok
+	}
+
+	digest := hash.New()
+	digest.Write(signingInput)
+	hashed := digest.Sum(nil)
+
+	switch algorithm[:2] {
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(publicKey, hash, hashed, signature) == nil, ok
+	case "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPSS(publicKey, hash, hashed, signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		}) == nil, ok
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve != ecdsaCurves[algorithm] {
+			return false, false
+		}
+		// the signature is the concatenation of r and s, see RFC 7518 section 3.4
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false, true
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(publicKey, hashed, r, s), true
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return false, false
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signingInput)
+		return hmac.Equal(mac.Sum(nil), signature), true
+	}
+	return false, false
+}
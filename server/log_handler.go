@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// The slog levels of the MCP logging levels without a slog counterpart. The
+// levels of the records are mapped to the MCP logging level they reach:
+// below slog.LevelInfo is debug, below LevelNotice is info, below
+// slog.LevelWarn is notice, and so on up to emergency.
+const (
+	LevelNotice    slog.Level = 2
+	LevelCritical  slog.Level = 12
+	LevelAlert     slog.Level = 16
+	LevelEmergency slog.Level = 20
+)
+
+// loggingLevel maps a slog level to an MCP logging level
+func loggingLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return mcp.LoggingLevelDebug
+	case level < LevelNotice:
+		return mcp.LoggingLevelInfo
+	case level < slog.LevelWarn:
+		return mcp.LoggingLevelNotice
+	case level < slog.LevelError:
+		return mcp.LoggingLevelWarning
+	case level < LevelCritical:
+		return mcp.LoggingLevelError
+	case level < LevelAlert:
+		return mcp.LoggingLevelCritical
+	case level < LevelEmergency:
+		return mcp.LoggingLevelAlert
+	default:
+		return mcp.LoggingLevelEmergency
+	}
+}
+
+// ClientLogHandler is a slog.Handler sending the records logged with the
+// context of a request to the client of the request, as notifications/message
+// notifications. The records below the level the client set with
+// logging/setLevel, error by default, are not sent. The message and the
+// attributes of a record are sent as the data of the notification, with the
+// groups as nested objects:
+//
+//	s := server.NewMCPServer("example", "1.0.0", server.WithLogging())
+//	slog.SetDefault(slog.New(server.NewClientLogHandler(s)))
+//
+//	// in a tool handler
+//	slog.InfoContext(ctx, "searching", "query", query)
+//
+// The records logged without a client session in their context, or whose
+// session does not support logging, are only passed to the local handler, if
+// any.
+type ClientLogHandler struct {
+	server *MCPServer
+	name   string
+	local  slog.Handler
+	// attrs are the attributes of WithAttrs, under the groups they were given in
+	attrs []groupAttrs
+	// groups are the groups of WithGroup
+	groups []string
+}
+
+var _ slog.Handler = (*ClientLogHandler)(nil)
+
+type groupAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+// ClientLogHandlerOption configures a ClientLogHandler.
+type ClientLogHandlerOption func(*ClientLogHandler)
+
+// WithClientLoggerName sets the logger name of the notifications.
+func WithClientLoggerName(name string) ClientLogHandlerOption {
+	return func(h *ClientLogHandler) {
+		h.name = name
+	}
+}
+
+// WithLocalLogHandler passes the records to handler too, whether or not they
+// are sent to the client. The handler must not be the ClientLogHandler, which
+// is easy to miss when teeing to the handler of slog.Default() before replacing
+// it: take the handler before calling slog.SetDefault.
+func WithLocalLogHandler(handler slog.Handler) ClientLogHandlerOption {
+	return func(h *ClientLogHandler) {
+		h.local = handler
+	}
+}
+
+// NewClientLogHandler returns a handler sending the records to the clients of
+// server.
+func NewClientLogHandler(server *MCPServer, opts ...ClientLogHandlerOption) *ClientLogHandler {
+	h := &ClientLogHandler{server: server}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Enabled reports whether the local handler logs the level, or the client of
+// the session of ctx asked for it.
+func (h *ClientLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.local != nil && h.local.Enabled(ctx, level) {
+		return true
+	}
+	_, ok := h.sessionFor(ctx, level)
+	return ok
+}
+
+// sessionFor returns the session of ctx if its client asked for the level
+func (h *ClientLogHandler) sessionFor(ctx context.Context, level slog.Level) (SessionWithLogging, bool) {
+	session, ok := ClientSessionFromContext(ctx).(SessionWithLogging)
+	if !ok || !session.Initialized() || !loggingLevel(level).ShouldSendTo(session.GetLogLevel()) {
+		return nil, false
+	}
+	return session, true
+}
+
+// Handle passes the record to the local handler and sends it to the client of
+// the session of ctx.
+func (h *ClientLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var localErr error
+	if h.local != nil && h.local.Enabled(ctx, record.Level) {
+		localErr = h.local.Handle(ctx, record.Clone())
+	}
+	if _, ok := h.sessionFor(ctx, record.Level); !ok {
+		return localErr
+	}
+
+	data := map[string]any{"message": record.Message}
+	for _, attrs := range h.attrs {
+		addAttrs(data, attrs.groups, attrs.attrs)
+	}
+	recordAttrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		recordAttrs = append(recordAttrs, attr)
+		return true
+	})
+	addAttrs(data, h.groups, recordAttrs)
+
+	notification := mcp.NewLoggingMessageNotification(loggingLevel(record.Level), h.name, data)
+	if err := h.server.SendLogMessageToClient(ctx, notification); err != nil {
+		return errors.Join(localErr, fmt.Errorf("failed to send log message to client: %w", err))
+	}
+	return localErr
+}
+
+// WithAttrs returns a handler adding attrs to the records.
+func (h *ClientLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append(slices.Clip(h.attrs), groupAttrs{groups: h.groups, attrs: attrs})
+	if h.local != nil {
+		h2.local = h.local.WithAttrs(attrs)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler adding the attributes of the records to the
+// group name.
+func (h *ClientLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	if h.local != nil {
+		h2.local = h.local.WithGroup(name)
+	}
+	return &h2
+}
+
+// addAttrs adds attrs to the nested object of data of the groups
+func addAttrs(data map[string]any, groups []string, attrs []slog.Attr) {
+	if len(attrs) == 0 {
+		return
+	}
+	for _, group := range groups {
+		nested, ok := data[group].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			data[group] = nested
+		}
+		data = nested
+	}
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		if attr.Value.Kind() == slog.KindGroup {
+			if attr.Key == "" {
+				addAttrs(data, nil, attr.Value.Group())
+			} else {
+				addAttrs(data, []string{attr.Key}, attr.Value.Group())
+			}
+			continue
+		}
+		data[attr.Key] = attrValue(attr.Value)
+	}
+}
+
+// attrValue returns the JSON value of an attribute
+func attrValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindString:
+		return value.String()
+	case slog.KindInt64:
+		return value.Int64()
+	case slog.KindUint64:
+		return value.Uint64()
+	case slog.KindFloat64:
+		return value.Float64()
+	case slog.KindBool:
+		return value.Bool()
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	}
+	switch v := value.Any().(type) {
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	if _, err := json.Marshal(value.Any()); err != nil {
+		return value.String()
+	}
+	return value.Any()
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingLevel(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected mcp.LoggingLevel
+	}{
+		{slog.LevelDebug, mcp.LoggingLevelDebug},
+		{slog.LevelInfo, mcp.LoggingLevelInfo},
+		{LevelNotice, mcp.LoggingLevelNotice},
+		{slog.LevelWarn, mcp.LoggingLevelWarning},
+		{slog.LevelError, mcp.LoggingLevelError},
+		{slog.LevelError + 1, mcp.LoggingLevelError},
+		{LevelCritical, mcp.LoggingLevelCritical},
+		{LevelAlert, mcp.LoggingLevelAlert},
+		{LevelEmergency, mcp.LoggingLevelEmergency},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, loggingLevel(tt.level), tt.level.String())
+	}
+}
+
+func TestClientLogHandler(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithLogging())
+	session := NewInProcessSession("session-1", nil)
+	session.Initialize()
+	session.SetLogLevel(mcp.LoggingLevelInfo)
+	ctx := server.WithContext(context.Background(), session)
+
+	var local bytes.Buffer
+	logger := slog.New(NewClientLogHandler(server,
+		WithClientLoggerName("tools"),
+		WithLocalLogHandler(slog.NewTextHandler(&local, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	))
+
+	t.Run("sends the records to the client", func(t *testing.T) {
+		logger.With("tool", "search").WithGroup("request").InfoContext(ctx, "searching",
+			"query", "mcp",
+			"limit", 10,
+			"timeout", time.Second,
+			"error", errors.New("slow"),
+			slog.Group("page", "number", 2),
+		)
+
+		notification := <-session.notifications
+		assert.Equal(t, "notifications/message", notification.Method)
+		assert.Equal(t, mcp.LoggingLevelInfo, notification.Params.AdditionalFields["level"])
+		assert.Equal(t, "tools", notification.Params.AdditionalFields["logger"])
+		assert.Equal(t, map[string]any{
+			"message": "searching",
+			"tool":    "search",
+			"request": map[string]any{
+				"query":   "mcp",
+				"limit":   int64(10),
+				"timeout": "1s",
+				"error":   "slow",
+				"page":    map[string]any{"number": int64(2)},
+			},
+		}, notification.Params.AdditionalFields["data"])
+		assert.Contains(t, local.String(), `msg=searching tool=search request.query=mcp`)
+	})
+
+	t.Run("respects the level of the session", func(t *testing.T) {
+		local.Reset()
+		logger.DebugContext(ctx, "details")
+		assert.Empty(t, session.notifications)
+		assert.Contains(t, local.String(), "msg=details")
+
+		logger.Log(ctx, LevelCritical, "failing")
+		notification := <-session.notifications
+		assert.Equal(t, mcp.LoggingLevelCritical, notification.Params.AdditionalFields["level"])
+	})
+
+	t.Run("logs locally without a session", func(t *testing.T) {
+		local.Reset()
+		logger.Error("no session")
+		assert.Empty(t, session.notifications)
+		assert.Contains(t, local.String(), `msg="no session"`)
+	})
+}
+
+func TestClientLogHandler_Enabled(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithLogging())
+	handler := NewClientLogHandler(server)
+	session := NewInProcessSession("session-1", nil)
+	ctx := server.WithContext(context.Background(), session)
+
+	assert.False(t, handler.Enabled(ctx, slog.LevelError), "session not initialized")
+	session.Initialize()
+	assert.True(t, handler.Enabled(ctx, slog.LevelError))
+	assert.False(t, handler.Enabled(ctx, slog.LevelWarn))
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelError))
+}
+
+func TestClientLogHandler_ToolHandler(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithLogging())
+	logger := slog.New(NewClientLogHandler(server))
+	server.AddTool(mcp.NewTool("search"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger.WarnContext(ctx, "no results")
+		return mcp.NewToolResultText("none"), nil
+	})
+
+	session := NewInProcessSession("session-1", nil)
+	session.Initialize()
+	session.SetLogLevel(mcp.LoggingLevelWarning)
+	ctx := server.WithContext(context.Background(), session)
+	response := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	require.Len(t, session.notifications, 1)
+	notification := <-session.notifications
+	assert.Equal(t, mcp.LoggingLevelWarning, notification.Params.AdditionalFields["level"])
+	assert.Equal(t, map[string]any{"message": "no results"}, notification.Params.AdditionalFields["data"])
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithServerLogger sets the logger of the server, slog.Default() by default.
+// The requests and notifications the server handles, and the requests it
+// sends to the client, are logged at debug level with their session ID,
+// request ID, method and tool. The stdio, SSE and streamable HTTP servers of
+// the server log with it too, unless they are given a logger of their own.
+func WithServerLogger(logger *slog.Logger) ServerOption {
+	return func(s *MCPServer) {
+		s.logger = logger
+	}
+}
+
+// sessionLogAttrs returns the log attributes of the session of ctx, if any
+func sessionLogAttrs(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	if session := ClientSessionFromContext(ctx); session != nil {
+		attrs = append(attrs, slog.String("sessionID", session.SessionID()))
+	}
+	return attrs
+}
+
+// logRequest logs a request handled by the server with its final response
+func (s *MCPServer) logRequest(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	params json.RawMessage,
+	start time.Time,
+	response mcp.JSONRPCMessage,
+) {
+	if !s.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := sessionLogAttrs(ctx, []slog.Attr{
+		slog.Any("requestID", id),
+		slog.String("method", string(method)),
+	})
+	switch name, uri := requestTarget(params); method {
+	case mcp.MethodToolsCall:
+		attrs = append(attrs, slog.String("tool", name))
+	case mcp.MethodPromptsGet:
+		attrs = append(attrs, slog.String("prompt", name))
+	case mcp.MethodResourcesRead:
+		attrs = append(attrs, slog.String("uri", uri))
+	}
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+	switch response := response.(type) {
+	case nil:
+		s.logger.LogAttrs(ctx, slog.LevelDebug, "request cancelled", attrs...)
+	case mcp.JSONRPCError:
+		attrs = append(attrs, slog.Int("errorCode", response.Error.Code), slog.String("error", response.Error.Message))
+		s.logger.LogAttrs(ctx, slog.LevelDebug, "request failed", attrs...)
+	default:
+		s.logger.LogAttrs(ctx, slog.LevelDebug, "request handled", attrs...)
+	}
+}
+
+// logNotification logs a notification received from the client
+func (s *MCPServer) logNotification(ctx context.Context, method string) {
+	if s.logger.Enabled(ctx, slog.LevelDebug) {
+		attrs := sessionLogAttrs(ctx, []slog.Attr{slog.String("method", method)})
+		s.logger.LogAttrs(ctx, slog.LevelDebug, "notification received", attrs...)
+	}
+}
+
+// logClientRequest logs a request sent to the client with its outcome
+func (s *MCPServer) logClientRequest(ctx context.Context, session ClientSession, method mcp.MCPMethod, start time.Time, err error) {
+	if !s.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("sessionID", session.SessionID()),
+		slog.String("method", string(method)),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		s.logger.LogAttrs(ctx, slog.LevelDebug, "request to client failed", attrs...)
+		return
+	}
+	s.logger.LogAttrs(ctx, slog.LevelDebug, "request to client answered", attrs...)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey || attr.Key == "duration" {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+	server := NewMCPServer("test", "1.0.0", WithServerLogger(logger))
+	server.AddTool(mcp.NewTool("search"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("found"), nil
+	})
+
+	ctx := server.WithContext(context.Background(), NewInProcessSession("session-1", nil))
+	messages := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+	}
+	for _, message := range messages {
+		server.HandleMessage(ctx, []byte(message))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, `level=DEBUG msg="request handled" requestID=1 method=tools/call sessionID=session-1 tool=search`, lines[0])
+	assert.Equal(t, `level=DEBUG msg="request failed" requestID=2 method=tools/call sessionID=session-1 tool=missing errorCode=-32602 error="tool 'missing' not found: tool not found"`, lines[1])
+	assert.Equal(t, `level=DEBUG msg="notification received" method=notifications/initialized sessionID=session-1`, lines[2])
+}
+
+func TestMCPServer_LoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	server := NewMCPServer("test", "1.0.0", WithServerLogger(logger))
+	server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// The transports of the sessions, as passed to the Metrics.
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+	TransportInProcess      = "in-process"
+	TransportOther          = "other"
+)
+
+// RequestMetric is a request handled by the server.
+type RequestMetric struct {
+	Method mcp.MCPMethod
+	// Tool is the name of the called tool, empty for the other methods.
+	Tool string
+	// Transport is the transport of the session of the request, empty if it
+	// was handled without a session.
+	Transport string
+	Duration  time.Duration
+	// ErrorCode is the JSON-RPC code of the error response, 0 if the request
+	// succeeded.
+	ErrorCode int
+}
+
+// Metrics records the activity of a server, for a monitoring backend such as
+// PrometheusMetrics. Its methods are called concurrently, and must not block.
+type Metrics interface {
+	// RecordRequest records a request answered by the server. Cancelled
+	// requests, which get no response, are not recorded.
+	RecordRequest(request RequestMetric)
+	// SessionStarted and SessionEnded record the registration and the
+	// unregistration of a session.
+	SessionStarted(transport string)
+	SessionEnded(transport string)
+	// NotificationQueued records a notification queued for a session, with
+	// the number of notifications in its queue once it is added.
+	NotificationQueued(transport string, depth int)
+	// NotificationDropped records a notification dropped because the queue of
+	// its session was full.
+	NotificationDropped(transport string)
+}
+
+// WithMetrics records the requests, sessions and notifications of the server
+// with metrics.
+func WithMetrics(metrics Metrics) ServerOption {
+	return func(s *MCPServer) {
+		s.metrics = metrics
+	}
+}
+
+// sessionTransport returns the transport of a session, TransportOther for
+// the sessions of custom transports
+func sessionTransport(session ClientSession) string {
+	switch session.(type) {
+	case *stdioSession:
+		return TransportStdio
+	case *sseSession:
+		return TransportSSE
+	case *streamableHttpSession:
+		return TransportStreamableHTTP
+	case *InProcessSession:
+		return TransportInProcess
+	default:
+		return TransportOther
+	}
+}
+
+// recordRequest records a request with its final response
+func (s *MCPServer) recordRequest(
+	session ClientSession,
+	method mcp.MCPMethod,
+	params json.RawMessage,
+	start time.Time,
+	response mcp.JSONRPCMessage,
+) {
+	if s.metrics == nil || response == nil {
+		return
+	}
+	request := RequestMetric{
+		Method:   method,
+		Duration: time.Since(start),
+	}
+	if method == mcp.MethodToolsCall {
+		request.Tool, _ = requestTarget(params)
+	}
+	if session != nil {
+		request.Transport = sessionTransport(session)
+	}
+	if errorResponse, ok := response.(mcp.JSONRPCError); ok {
+		request.ErrorCode = errorResponse.Error.Code
+	}
+	s.metrics.RecordRequest(request)
+}
+
+// notificationQueued records a notification queued for session
+func (s *MCPServer) notificationQueued(session ClientSession) {
+	if s.metrics != nil {
+		s.metrics.NotificationQueued(sessionTransport(session), len(session.NotificationChannel()))
+	}
+}
+
+// notificationDropped records a notification dropped for session
+func (s *MCPServer) notificationDropped(session ClientSession) {
+	if s.metrics != nil {
+		s.metrics.NotificationDropped(sessionTransport(session))
+	}
+}
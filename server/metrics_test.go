@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics_Exposition(t *testing.T) {
+	metrics := NewPrometheusMetrics(1, 0.1)
+	metrics.RecordRequest(RequestMetric{Method: mcp.MethodToolsCall, Tool: `say "hi"`, Duration: 50 * time.Millisecond})
+	metrics.RecordRequest(RequestMetric{Method: mcp.MethodToolsCall, Tool: `say "hi"`, Duration: 2 * time.Second, ErrorCode: mcp.INTERNAL_ERROR})
+	metrics.RecordRequest(RequestMetric{Method: mcp.MethodPing, Duration: 500 * time.Millisecond})
+	metrics.SessionStarted(TransportSSE)
+	metrics.SessionStarted(TransportSSE)
+	metrics.SessionEnded(TransportSSE)
+	metrics.NotificationQueued(TransportSSE, 3)
+	metrics.NotificationDropped(TransportSSE)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP mcp_server_requests_total Requests handled by the server.
+# TYPE mcp_server_requests_total counter
+mcp_server_requests_total{method="ping",tool=""} 1
+mcp_server_requests_total{method="tools/call",tool="say \"hi\""} 2
+# HELP mcp_server_request_errors_total Requests answered with an error, by JSON-RPC error code.
+# TYPE mcp_server_request_errors_total counter
+mcp_server_request_errors_total{method="tools/call",tool="say \"hi\"",code="-32603"} 1
+# HELP mcp_server_request_duration_seconds Duration of the requests handled by the server.
+# TYPE mcp_server_request_duration_seconds histogram
+mcp_server_request_duration_seconds_bucket{method="ping",tool="",le="0.1"} 0
+mcp_server_request_duration_seconds_bucket{method="ping",tool="",le="1"} 1
+mcp_server_request_duration_seconds_bucket{method="ping",tool="",le="+Inf"} 1
+mcp_server_request_duration_seconds_sum{method="ping",tool=""} 0.5
+mcp_server_request_duration_seconds_count{method="ping",tool=""} 1
+mcp_server_request_duration_seconds_bucket{method="tools/call",tool="say \"hi\"",le="0.1"} 1
+mcp_server_request_duration_seconds_bucket{method="tools/call",tool="say \"hi\"",le="1"} 1
+mcp_server_request_duration_seconds_bucket{method="tools/call",tool="say \"hi\"",le="+Inf"} 2
+mcp_server_request_duration_seconds_sum{method="tools/call",tool="say \"hi\""} 2.05
+mcp_server_request_duration_seconds_count{method="tools/call",tool="say \"hi\""} 2
+# HELP mcp_server_active_sessions Sessions registered with the server.
+# TYPE mcp_server_active_sessions gauge
+mcp_server_active_sessions{transport="sse"} 1
+# HELP mcp_server_notification_queue_depth Notifications in the queue of a session as a notification is queued.
+# TYPE mcp_server_notification_queue_depth histogram
+mcp_server_notification_queue_depth_bucket{transport="sse",le="0"} 0
+mcp_server_notification_queue_depth_bucket{transport="sse",le="1"} 0
+mcp_server_notification_queue_depth_bucket{transport="sse",le="5"} 1
+mcp_server_notification_queue_depth_bucket{transport="sse",le="10"} 1
+mcp_server_notification_queue_depth_bucket{transport="sse",le="25"} 1
+mcp_server_notification_queue_depth_bucket{transport="sse",le="50"} 1
+mcp_server_notification_queue_depth_bucket{transport="sse",le="100"} 1
+mcp_server_notification_queue_depth_bucket{transport="sse",le="+Inf"} 1
+mcp_server_notification_queue_depth_sum{transport="sse"} 3
+mcp_server_notification_queue_depth_count{transport="sse"} 1
+# HELP mcp_server_notifications_dropped_total Notifications dropped because the queue of their session was full.
+# TYPE mcp_server_notifications_dropped_total counter
+mcp_server_notifications_dropped_total{transport="sse"} 1
+`, recorder.Body.String())
+}
+
+// recordingMetrics keeps the calls to its methods
+type recordingMetrics struct {
+	requests []RequestMetric
+	sessions map[string]int
+	queued   []int
+	dropped  int
+}
+
+func (m *recordingMetrics) RecordRequest(request RequestMetric) {
+	m.requests = append(m.requests, request)
+}
+
+func (m *recordingMetrics) SessionStarted(transport string) {
+	m.sessions[transport]++
+}
+
+func (m *recordingMetrics) SessionEnded(transport string) {
+	m.sessions[transport]--
+}
+
+func (m *recordingMetrics) NotificationQueued(transport string, depth int) {
+	m.queued = append(m.queued, depth)
+}
+
+func (m *recordingMetrics) NotificationDropped(transport string) {
+	m.dropped++
+}
+
+func TestMCPServer_Metrics(t *testing.T) {
+	metrics := &recordingMetrics{sessions: make(map[string]int)}
+	server := NewMCPServer("test", "1.0.0", WithMetrics(metrics))
+	server.AddTool(mcp.NewTool("search"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("found"), nil
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		ctx := server.WithContext(context.Background(), NewInProcessSession("session-1", nil))
+		messages := []string{
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}}`,
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing"}}`,
+			`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		}
+		for _, message := range messages {
+			server.HandleMessage(ctx, []byte(message))
+		}
+		require.Len(t, metrics.requests, 3)
+		assert.Equal(t, mcp.MethodToolsCall, metrics.requests[0].Method)
+		assert.Equal(t, "search", metrics.requests[0].Tool)
+		assert.Equal(t, TransportInProcess, metrics.requests[0].Transport)
+		assert.Zero(t, metrics.requests[0].ErrorCode)
+		assert.Equal(t, "missing", metrics.requests[1].Tool)
+		assert.Equal(t, mcp.INVALID_PARAMS, metrics.requests[1].ErrorCode)
+		assert.Equal(t, mcp.MethodPing, metrics.requests[2].Method)
+		assert.Empty(t, metrics.requests[2].Tool)
+	})
+
+	t.Run("sessions and notifications", func(t *testing.T) {
+		session := fakeSession{
+			sessionID:           "session-2",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 1),
+			initialized:         true,
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		assert.Equal(t, map[string]int{TransportOther: 1}, metrics.sessions)
+
+		server.SendNotificationToAllClients("notifications/test", nil)
+		server.SendNotificationToAllClients("notifications/test", nil)
+		assert.Equal(t, []int{1}, metrics.queued)
+		assert.Equal(t, 1, metrics.dropped)
+
+		server.UnregisterSession(context.Background(), "session-2")
+		assert.Equal(t, map[string]int{TransportOther: 0}, metrics.sessions)
+	})
+}
+
+func TestPrometheusMetrics_Server(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	server := NewMCPServer("test", "1.0.0", WithMetrics(metrics))
+	server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"unknown/method"}`))
+
+	var b strings.Builder
+	_, err := metrics.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), `mcp_server_request_errors_total{method="unknown/method",tool="",code="-32601"} 1`)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+	assert.Equal(t, b.String(), string(body))
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_PromptAndResourceMiddlewares(t *testing.T) {
+	var calls []string
+	server := NewMCPServer("test-server", "1.0.0",
+		WithPromptHandlerMiddleware(func(next PromptHandlerFunc) PromptHandlerFunc {
+			return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				calls = append(calls, "first:"+request.Params.Name)
+				return next(ctx, request)
+			}
+		}),
+		WithPromptHandlerMiddleware(func(next PromptHandlerFunc) PromptHandlerFunc {
+			return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				calls = append(calls, "second:"+request.Params.Name)
+				return next(ctx, request)
+			}
+		}),
+		WithResourceHandlerMiddleware(func(next ResourceHandlerFunc) ResourceHandlerFunc {
+			return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				calls = append(calls, "resource:"+request.Params.URI)
+				return next(ctx, request)
+			}
+		}),
+	)
+	server.AddPrompt(mcp.NewPrompt("greeting"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		calls = append(calls, "handler")
+		return &mcp.GetPromptResult{}, nil
+	})
+	readResource := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "content"}}, nil
+	}
+	server.AddResource(mcp.NewResource("file:///static.txt", "static"), readResource)
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///users/{id}", "user"), readResource)
+
+	messages := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"greeting"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file:///static.txt"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"file:///users/42"}}`,
+	}
+	for _, message := range messages {
+		_, ok := server.HandleMessage(context.Background(), []byte(message)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+	}
+
+	assert.Equal(t, []string{
+		"first:greeting",
+		"second:greeting",
+		"handler",
+		"resource:file:///static.txt",
+		"resource:file:///users/42",
+	}, calls)
+}
+
+func TestMCPServer_WithRecovery_PromptsAndResources(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithRecovery())
+	server.AddPrompt(mcp.NewPrompt("panic-prompt"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		panic("test panic")
+	})
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///users/{id}", "user"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			panic("test panic")
+		})
+
+	tests := []struct {
+		message         string
+		expectedMessage string
+	}{
+		{
+			message:         `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"panic-prompt"}}`,
+			expectedMessage: "panic recovered in panic-prompt prompt handler: test panic",
+		},
+		{
+			message:         `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file:///users/42"}}`,
+			expectedMessage: "panic recovered in file:///users/42 resource handler: test panic",
+		},
+	}
+	for _, tt := range tests {
+		errorResponse, ok := server.HandleMessage(context.Background(), []byte(tt.message)).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INTERNAL_ERROR, errorResponse.Error.Code)
+		assert.Equal(t, tt.expectedMessage, errorResponse.Error.Message)
+	}
+}
+
+func TestMCPServer_MethodHandlerMiddleware(t *testing.T) {
+	var seen []mcp.MCPMethod
+	server := NewMCPServer("test-server", "1.0.0",
+		WithMethodHandlerMiddleware(func(next MethodHandlerFunc) MethodHandlerFunc {
+			return func(ctx context.Context, id any, method mcp.MCPMethod, params json.RawMessage) mcp.JSONRPCMessage {
+				seen = append(seen, method)
+				if method == mcp.MethodToolsCall {
+					// route every call to the echo tool
+					params = json.RawMessage(`{"name":"echo","arguments":{"text":"rewritten"}}`)
+				}
+				return next(ctx, id, method, params)
+			}
+		}),
+		WithMethodHandlerMiddleware(func(next MethodHandlerFunc) MethodHandlerFunc {
+			return func(ctx context.Context, id any, method mcp.MCPMethod, params json.RawMessage) mcp.JSONRPCMessage {
+				if method == mcp.MethodResourcesList {
+					return createErrorResponse(id, mcp.INVALID_REQUEST, "forbidden")
+				}
+				return next(ctx, id, method, params)
+			}
+		}),
+	)
+	server.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("text", "")), nil
+	})
+
+	response, ok := server.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"other"}}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, mcp.NewRequestId(float64(1)), response.ID)
+	assert.Equal(t, mcp.NewTextContent("rewritten"), response.Result.(mcp.CallToolResult).Content[0])
+
+	errorResponse, ok := server.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, "forbidden", errorResponse.Error.Message)
+
+	// notifications are not passed to the middlewares, the requests of a batch are
+	server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	server.HandleMessage(context.Background(), []byte(`[{"jsonrpc":"2.0","id":3,"method":"ping"}]`))
+
+	assert.Equal(t, []mcp.MCPMethod{mcp.MethodToolsCall, mcp.MethodResourcesList, mcp.MethodPing}, seen)
+}
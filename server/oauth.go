@@ -0,0 +1,454 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultProtectedResourceMetadataPath is the path the OAuth 2.0 protected
+// resource metadata is served at by default.
+const DefaultProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata of an
+// MCP server, as defined by RFC 9728. Clients use it to discover the
+// authorization servers issuing tokens for the server.
+type ProtectedResourceMetadata struct {
+	// Resource is the canonical URI of the protected MCP server, including
+	// its path, which access tokens must be issued for. It is required.
+	Resource                          string   `json:"resource"`
+	AuthorizationServers              []string `json:"authorization_servers,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported            []string `json:"bearer_methods_supported,omitempty"`
+	ResourceName                      string   `json:"resource_name,omitempty"`
+	ResourceDocumentation             string   `json:"resource_documentation,omitempty"`
+	ResourcePolicyURI                 string   `json:"resource_policy_uri,omitempty"`
+	ResourceTOSURI                    string   `json:"resource_tos_uri,omitempty"`
+	ResourceSigningAlgValuesSupported []string `json:"resource_signing_alg_values_supported,omitempty"`
+}
+
+// TokenClaims are the claims of a verified access token.
+type TokenClaims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+	// Claims holds every claim of the token, including the ones above.
+	Claims map[string]any
+}
+
+// HasScope reports whether the token was granted the scope.
+func (c *TokenClaims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// TokenVerifier verifies the bearer tokens sent to a protected MCP server.
+type TokenVerifier interface {
+	// VerifyToken returns the claims of a valid token. Errors wrapping
+	// ErrInsufficientScope are reported as such to the client, any other
+	// error as an invalid token.
+	VerifyToken(ctx context.Context, token string) (*TokenClaims, error)
+}
+
+// TokenVerifierFunc is an adapter to use a function as a TokenVerifier.
+type TokenVerifierFunc func(ctx context.Context, token string) (*TokenClaims, error)
+
+// VerifyToken implements TokenVerifier.
+func (f TokenVerifierFunc) VerifyToken(ctx context.Context, token string) (*TokenClaims, error) {
+	return f(ctx, token)
+}
+
+// tokenValidation holds the checks made on the claims of a token
+type tokenValidation struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// TokenValidationOption configures the checks a token verifier makes on the
+// claims of a token.
+type TokenValidationOption func(*tokenValidation)
+
+// WithTokenIssuer requires the tokens to be issued by the issuer.
+func WithTokenIssuer(issuer string) TokenValidationOption {
+	return func(v *tokenValidation) {
+		v.issuer = issuer
+	}
+}
+
+// WithTokenAudience requires the tokens to be issued for the audience. A
+// ProtectedResource already requires the tokens to be issued for its resource,
+// whatever the verifier.
+func WithTokenAudience(audience string) TokenValidationOption {
+	return func(v *tokenValidation) {
+		v.audience = audience
+	}
+}
+
+// WithTokenLeeway sets the clock skew tolerated when checking the expiration
+// and not-before times of the tokens.
+func WithTokenLeeway(leeway time.Duration) TokenValidationOption {
+	return func(v *tokenValidation) {
+		v.leeway = leeway
+	}
+}
+
+func newTokenValidation(opts []TokenValidationOption) tokenValidation {
+	v := tokenValidation{now: time.Now}
+	for _, opt := range opts {
+		opt(&v)
+	}
+	return v
+}
+
+// validate checks the registered claims of a token and returns its claims.
+func (v tokenValidation) validate(claims map[string]any) (*TokenClaims, error) {
+	result := &TokenClaims{
+		Subject:  claimString(claims, "sub"),
+		Issuer:   claimString(claims, "iss"),
+		Audience: claimStrings(claims, "aud"),
+		ClientID: claimString(claims, "client_id"),
+		Claims:   claims,
+	}
+	if result.ClientID == "" {
+		result.ClientID = claimString(claims, "azp")
+	}
+	if scope := claimString(claims, "scope"); scope != "" {
+		result.Scopes = strings.Fields(scope)
+	} else if scp, ok := claims["scp"].(string); ok {
+		result.Scopes = strings.Fields(scp)
+	} else {
+		result.Scopes = claimStrings(claims, "scp")
+	}
+
+	now := v.now()
+	if exp, ok := claimTime(claims, "exp"); ok {
+		result.ExpiresAt = exp
+		if !now.Before(exp.Add(v.leeway)) {
+			return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+		}
+	}
+	if nbf, ok := claimTime(claims, "nbf"); ok && now.Add(v.leeway).Before(nbf) {
+		return nil, fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+	if v.issuer != "" && result.Issuer != v.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, result.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(result.Audience, v.audience) {
+		return nil, fmt.Errorf("%w: token not issued for %s", ErrInvalidToken, v.audience)
+	}
+	return result, nil
+}
+
+func claimString(claims map[string]any, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings returns a claim which is a string or an array of strings
+func claimStrings(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// claimTime returns a NumericDate claim, a number of seconds since the epoch
+func claimTime(claims map[string]any, name string) (time.Time, bool) {
+	var seconds float64
+	switch value := claims[name].(type) {
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case float64:
+		seconds = value
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// tokenClaimsKey is the context key for storing the verified token claims
+type tokenClaimsKey struct{}
+
+// TokenClaimsFromContext returns the claims of the access token the request
+// was authorized with, or nil if the server is not a protected resource.
+func TokenClaimsFromContext(ctx context.Context) *TokenClaims {
+	if claims, ok := ctx.Value(tokenClaimsKey{}).(*TokenClaims); ok {
+		return claims
+	}
+	return nil
+}
+
+// ProtectedResource makes an SSEServer or a StreamableHTTPServer an OAuth 2.1
+// resource server: it serves the protected resource metadata and requires a
+// valid bearer token on every other request. The claims of the token are
+// available to the handlers through TokenClaimsFromContext.
+//
+// As required by the MCP authorization specification, the tokens must be
+// issued for the resource of the metadata (RFC 8707), unless
+// WithAnyTokenAudience is given.
+type ProtectedResource struct {
+	metadata       ProtectedResourceMetadata
+	verifier       TokenVerifier
+	metadataPath   string
+	requiredScopes []string
+	realm          string
+	anyAudience    bool
+}
+
+// ProtectedResourceOption configures a ProtectedResource.
+type ProtectedResourceOption func(*ProtectedResource)
+
+// WithRequiredScopes requires the access tokens to be granted all the scopes.
+func WithRequiredScopes(scopes ...string) ProtectedResourceOption {
+	return func(p *ProtectedResource) {
+		p.requiredScopes = append(p.requiredScopes, scopes...)
+	}
+}
+
+// WithMetadataPath sets the path the metadata is served at, which defaults to
+// DefaultProtectedResourceMetadataPath.
+func WithMetadataPath(path string) ProtectedResourceOption {
+	return func(p *ProtectedResource) {
+		p.metadataPath = path
+	}
+}
+
+// WithAnyTokenAudience accepts the tokens whatever their audience, such as the
+// tokens issued for other resources by the same authorization server. Only use
+// it with authorization servers which cannot bind tokens to a resource, and
+// check the audience in the verifier instead.
+func WithAnyTokenAudience() ProtectedResourceOption {
+	return func(p *ProtectedResource) {
+		p.anyAudience = true
+	}
+}
+
+// WithRealm sets the realm of the WWW-Authenticate challenges.
+func WithRealm(realm string) ProtectedResourceOption {
+	return func(p *ProtectedResource) {
+		p.realm = realm
+	}
+}
+
+// NewProtectedResource creates a protected resource verifying the bearer
+// tokens with the verifier. The resource of the metadata must be the absolute
+// URI of the MCP server, such as https://mcp.example.com/mcp; it is never
+// derived from the requests, whose Host header is chosen by the client.
+func NewProtectedResource(
+	metadata ProtectedResourceMetadata,
+	verifier TokenVerifier,
+	opts ...ProtectedResourceOption,
+) (*ProtectedResource, error) {
+	resource, err := canonicalResourceURI(metadata.Resource)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Resource = resource
+	p := &ProtectedResource{
+		metadata:     metadata,
+		verifier:     verifier,
+		metadataPath: DefaultProtectedResourceMetadataPath,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if len(p.metadata.BearerMethodsSupported) == 0 {
+		p.metadata.BearerMethodsSupported = []string{"header"}
+	}
+	if len(p.metadata.ScopesSupported) == 0 && len(p.requiredScopes) > 0 {
+		p.metadata.ScopesSupported = p.requiredScopes
+	}
+	return p, nil
+}
+
+// MetadataPath returns the path the metadata is served at.
+func (p *ProtectedResource) MetadataPath() string {
+	return p.metadataPath
+}
+
+// MetadataHandler returns an http.Handler serving the protected resource
+// metadata. It is served by the SSE and streamable-http servers themselves
+// when they handle the metadata path, and can be mounted on a custom mux
+// otherwise.
+func (p *ProtectedResource) MetadataHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the metadata is public and fetched by browser-based clients too
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Allow", "GET, OPTIONS")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		_ = json.NewEncoder(w).Encode(p.metadata)
+	})
+}
+
+// Middleware returns a handler requiring a valid bearer token before calling
+// next, with the claims of the token in the request context. Use it to
+// protect the handlers of a custom mux, such as SSEServer.SSEHandler.
+func (p *ProtectedResource) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, ok := p.authorize(w, r)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize verifies the bearer token of the request. It returns the request
+// with the token claims in its context, or writes a challenge and returns
+// false if the request is not authorized.
+func (p *ProtectedResource) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	token, err := bearerToken(r)
+	if err != nil {
+		p.challenge(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return nil, false
+	}
+	if token == "" {
+		// no error code when the request lacks authentication, see RFC 6750 section 3.1
+		p.challenge(w, http.StatusUnauthorized, "", "")
+		return nil, false
+	}
+
+	claims, err := p.verifier.VerifyToken(r.Context(), token)
+	if err == nil && !p.anyAudience && !p.issuedFor(claims.Audience) {
+		err = fmt.Errorf("%w: token not issued for %s", ErrInvalidToken, p.metadata.Resource)
+	}
+	if err == nil {
+		for _, scope := range p.requiredScopes {
+			if !claims.HasScope(scope) {
+				err = fmt.Errorf("%w: %s is required", ErrInsufficientScope, scope)
+				break
+			}
+		}
+	}
+	if err != nil {
+		// the errors of the verifiers are not disclosed to the client
+		if errors.Is(err, ErrInsufficientScope) {
+			p.challenge(w, http.StatusForbidden, "insufficient_scope", "The access token lacks a required scope")
+		} else {
+			p.challenge(w, http.StatusUnauthorized, "invalid_token", "The access token is invalid")
+		}
+		return nil, false
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), tokenClaimsKey{}, claims)), true
+}
+
+// challenge writes an RFC 6750 error response, pointing the client to the
+// protected resource metadata as described by RFC 9728.
+func (p *ProtectedResource) challenge(w http.ResponseWriter, status int, code string, description string) {
+	params := make([]string, 0, 5)
+	if p.realm != "" {
+		params = append(params, authParam("realm", p.realm))
+	}
+	if code != "" {
+		params = append(params, authParam("error", code))
+	}
+	if description != "" {
+		params = append(params, authParam("error_description", description))
+	}
+	if code == "insufficient_scope" && len(p.requiredScopes) > 0 {
+		params = append(params, authParam("scope", strings.Join(p.requiredScopes, " ")))
+	}
+	params = append(params, authParam("resource_metadata", p.metadataURL()))
+
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
+
+// issuedFor reports whether the audience of a token contains the resource
+func (p *ProtectedResource) issuedFor(audience []string) bool {
+	return slices.ContainsFunc(audience, func(aud string) bool {
+		canonical, err := canonicalResourceURI(aud)
+		return err == nil && canonical == p.metadata.Resource
+	})
+}
+
+// metadataURL returns the absolute URL of the metadata, on the origin of the
+// resource.
+func (p *ProtectedResource) metadataURL() string {
+	resource, _ := url.Parse(p.metadata.Resource)
+	return resource.Scheme + "://" + resource.Host + p.metadataPath
+}
+
+// canonicalResourceURI returns the canonical form of a resource URI, as
+// described by RFC 8707 and the MCP authorization specification: an absolute
+// URI without fragment, with a lowercase scheme and host, and without the
+// trailing slash of its path.
+func canonicalResourceURI(resource string) (string, error) {
+	if resource == "" {
+		return "", errors.New("the protected resource metadata has no resource")
+	}
+	u, err := url.Parse(resource)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource %q: %w", resource, err)
+	}
+	if u.Scheme == "" || u.Host == "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("invalid resource %q: must be an absolute URI without fragment", resource)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// bearerToken returns the token of the Authorization header, or "" if the
+// request is not authenticated with a bearer token.
+func bearerToken(r *http.Request) (string, error) {
+	values := r.Header.Values("Authorization")
+	if len(values) == 0 {
+		return "", nil
+	}
+	if len(values) > 1 {
+		return "", errors.New("multiple Authorization headers")
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", nil
+	}
+	token = strings.TrimSpace(token)
+	if !found || token == "" {
+		return "", errors.New("empty bearer token")
+	}
+	return token, nil
+}
+
+func authParam(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return name + `="` + value + `"`
+}
@@ -0,0 +1,440 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signJWT signs the claims with the signer, which returns the signature of
+// the signing input.
+func signJWT(t *testing.T, header map[string]any, claims map[string]any, sign func(input []byte) []byte) string {
+	t.Helper()
+	headerJSON, err := json.Marshal(header)
+	require.NoError(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	require.NoError(t, err)
+	input := b64(headerJSON) + "." + b64(claimsJSON)
+	return input + "." + b64(sign([]byte(input)))
+}
+
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+	secret  []byte
+	set     *JSONWebKeySet
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys := &testKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey, secret: []byte("test-secret")}
+
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","use":"sig","n":%q,"e":%q},
+		{"kty":"EC","kid":"ec","crv":"P-256","x":%q,"y":%q},
+		{"kty":"OKP","kid":"ed","crv":"Ed25519","x":%q},
+		{"kty":"oct","kid":"hmac","alg":"HS256","k":%q},
+		{"kty":"unknown","kid":"ignored"}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))),
+		b64(edKey.Public().(ed25519.PublicKey)),
+		b64(keys.secret),
+	)
+	keys.set, err = ParseJSONWebKeySet([]byte(jwks))
+	require.NoError(t, err)
+	return keys
+}
+
+func (k *testKeys) token(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+	kid := map[string]string{"RS256": "rsa", "PS256": "rsa", "ES256": "ec", "EdDSA": "ed", "HS256": "hmac"}[alg]
+	return signJWT(t, map[string]any{"alg": alg, "kid": kid, "typ": "at+jwt"}, claims, func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		switch alg {
+		case "RS256":
+			signature, err := rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+			require.NoError(t, err)
+			return signature
+		case "PS256":
+			signature, err := rsa.SignPSS(rand.Reader, k.rsa, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+			require.NoError(t, err)
+			return signature
+		case "ES256":
+			r, s, err := ecdsa.Sign(rand.Reader, k.ecdsa, digest[:])
+			require.NoError(t, err)
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		case "EdDSA":
+			return ed25519.Sign(k.ed25519, input)
+		case "HS256":
+			mac := hmac.New(sha256.New, k.secret)
+			mac.Write(input)
+			return mac.Sum(nil)
+		}
+		return []byte("signature")
+	})
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":       "https://auth.example.com",
+		"sub":       "user-1",
+		"aud":       []string{"https://mcp.example.com"},
+		"client_id": "client-1",
+		"scope":     "mcp:read mcp:write",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := NewJWTVerifier(keys.set,
+		WithTokenIssuer("https://auth.example.com"),
+		WithTokenAudience("https://mcp.example.com"),
+	)
+
+	for _, alg := range []string{"RS256", "PS256", "ES256", "EdDSA", "HS256"} {
+		t.Run(alg, func(t *testing.T) {
+			claims, err := verifier.VerifyToken(context.Background(), keys.token(t, alg, validClaims()))
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "client-1", claims.ClientID)
+			assert.Equal(t, []string{"mcp:read", "mcp:write"}, claims.Scopes)
+			assert.True(t, claims.HasScope("mcp:write"))
+			assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt, time.Minute)
+		})
+	}
+
+	invalid := map[string]string{
+		"expired": keys.token(t, "RS256", func() map[string]any {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return claims
+		}()),
+		"not yet valid": keys.token(t, "RS256", func() map[string]any {
+			claims := validClaims()
+			claims["nbf"] = time.Now().Add(time.Hour).Unix()
+			return claims
+		}()),
+		"missing exp": keys.token(t, "RS256", func() map[string]any {
+			claims := validClaims()
+			delete(claims, "exp")
+			return claims
+		}()),
+		"wrong issuer": keys.token(t, "RS256", func() map[string]any {
+			claims := validClaims()
+			claims["iss"] = "https://evil.example.com"
+			return claims
+		}()),
+		"wrong audience": keys.token(t, "RS256", func() map[string]any {
+			claims := validClaims()
+			claims["aud"] = "https://other.example.com"
+			return claims
+		}()),
+		"unsupported algorithm": keys.token(t, "none", validClaims()),
+		"tampered": func() string {
+			token := keys.token(t, "ES256", validClaims())
+			parts := strings.Split(token, ".")
+			claims := validClaims()
+			claims["sub"] = "admin"
+			data, _ := json.Marshal(claims)
+			return parts[0] + "." + b64(data) + "." + parts[2]
+		}(),
+		// the HMAC key is only usable with HS256
+		"key algorithm mismatch": signJWT(t, map[string]any{"alg": "HS384", "kid": "hmac"}, validClaims(), func([]byte) []byte { return []byte("x") }),
+		"malformed":              "not-a-jwt",
+	}
+	for name, token := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.VerifyToken(context.Background(), token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestParseJSONWebKeySet(t *testing.T) {
+	for name, jwks := range map[string]string{
+		"invalid JSON":     `{`,
+		"no supported key": `{"keys":[{"kty":"unknown"}]}`,
+		"invalid RSA key":  `{"keys":[{"kty":"RSA","n":"","e":"AQAB"}]}`,
+		"point off curve":  `{"keys":[{"kty":"EC","crv":"P-256","x":"` + b64(make([]byte, 32)) + `","y":"` + b64(make([]byte, 32)) + `"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseJSONWebKeySet([]byte(jwks))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "mcp-server" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("token") != "active-token" {
+			fmt.Fprint(w, `{"active":false}`)
+			return
+		}
+		fmt.Fprintf(w, `{"active":true,"sub":"user-1","scope":"mcp:read","aud":"https://mcp.example.com","exp":%d}`,
+			time.Now().Add(time.Hour).Unix())
+	}))
+	defer endpoint.Close()
+
+	introspector := &HTTPTokenIntrospector{Endpoint: endpoint.URL, ClientID: "mcp-server", ClientSecret: "secret"}
+	verifier := NewIntrospectionVerifier(introspector, WithTokenAudience("https://mcp.example.com"))
+
+	claims, err := verifier.VerifyToken(context.Background(), "active-token")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"mcp:read"}, claims.Scopes)
+
+	_, err = verifier.VerifyToken(context.Background(), "revoked-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	introspector.ClientSecret = "wrong"
+	_, err = verifier.VerifyToken(context.Background(), "active-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestStreamableHTTP_ProtectedResource(t *testing.T) {
+	keys := newTestKeys(t)
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(TokenClaimsFromContext(ctx).Subject), nil
+	})
+	protectedResource, err := NewProtectedResource(
+		ProtectedResourceMetadata{
+			Resource:             "https://mcp.example.com/mcp",
+			AuthorizationServers: []string{"https://auth.example.com"},
+		},
+		NewJWTVerifier(keys.set),
+		WithRequiredScopes("mcp:read"),
+	)
+	require.NoError(t, err)
+	// resourceClaims returns valid claims of a token issued for the resource
+	resourceClaims := func() map[string]any {
+		claims := validClaims()
+		claims["aud"] = "https://mcp.example.com/mcp"
+		return claims
+	}
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer, WithHTTPProtectedResource(protectedResource)))
+	defer server.Close()
+
+	t.Run("serves the metadata", func(t *testing.T) {
+		resp, err := http.Get(server.URL + DefaultProtectedResourceMetadataPath)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var metadata map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+		assert.Equal(t, "https://mcp.example.com/mcp", metadata["resource"])
+		assert.Equal(t, []any{"https://auth.example.com"}, metadata["authorization_servers"])
+		assert.Equal(t, []any{"mcp:read"}, metadata["scopes_supported"])
+	})
+
+	initBody, err := json.Marshal(initRequest)
+	require.NoError(t, err)
+	var sessionID string
+	post := func(token string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	challenges := []struct {
+		name      string
+		token     string
+		status    int
+		challenge string
+	}{
+		{
+			name:      "missing token",
+			status:    http.StatusUnauthorized,
+			challenge: `Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`,
+		},
+		{
+			name:      "invalid token",
+			token:     "invalid",
+			status:    http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="The access token is invalid", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`,
+		},
+		{
+			name:      "token for another resource",
+			token:     keys.token(t, "RS256", validClaims()),
+			status:    http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="The access token is invalid", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`,
+		},
+		{
+			name: "insufficient scope",
+			token: keys.token(t, "ES256", func() map[string]any {
+				claims := resourceClaims()
+				claims["scope"] = "mcp:write"
+				return claims
+			}()),
+			status:    http.StatusForbidden,
+			challenge: `Bearer error="insufficient_scope", error_description="The access token lacks a required scope", scope="mcp:read", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`,
+		},
+	}
+	for _, tt := range challenges {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(tt.token, string(initBody))
+			defer resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.challenge, resp.Header.Get("WWW-Authenticate"))
+		})
+	}
+
+	t.Run("claims reach the tool handlers", func(t *testing.T) {
+		token := keys.token(t, "RS256", resourceClaims())
+		resp := post(token, string(initBody))
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		sessionID = resp.Header.Get(headerKeySessionID)
+
+		resp = post(token, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var response jsonRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "user-1", response.Result["content"].([]any)[0].(map[string]any)["text"])
+	})
+}
+
+func TestSSEServer_ProtectedResource(t *testing.T) {
+	protectedResource, err := NewProtectedResource(ProtectedResourceMetadata{Resource: "https://mcp.example.com/sse"}, TokenVerifierFunc(
+		func(ctx context.Context, token string) (*TokenClaims, error) {
+			if token != "valid" {
+				return nil, ErrInvalidToken
+			}
+			return &TokenClaims{Subject: "user-1", Audience: []string{"https://mcp.example.com/sse"}}, nil
+		}), WithRealm("mcp"))
+	require.NoError(t, err)
+	testServer := NewTestServer(NewMCPServer("test", "1.0.0"), WithSSEProtectedResource(protectedResource))
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + DefaultProtectedResourceMetadataPath)
+	require.NoError(t, err)
+	var metadata ProtectedResourceMetadata
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+	resp.Body.Close()
+	assert.Equal(t, "https://mcp.example.com/sse", metadata.Resource)
+
+	resp, err = http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Bearer realm="mcp", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`,
+		resp.Header.Get("WWW-Authenticate"))
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/message?sessionId=unknown", strings.NewReader("{}"))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer invalid")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, testServer.URL+"/sse", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer valid")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNewProtectedResource(t *testing.T) {
+	verifier := TokenVerifierFunc(func(ctx context.Context, token string) (*TokenClaims, error) {
+		return nil, ErrInvalidToken
+	})
+	for _, resource := range []string{"", "/mcp", "mcp.example.com/mcp", "https://mcp.example.com/mcp#tools"} {
+		_, err := NewProtectedResource(ProtectedResourceMetadata{Resource: resource}, verifier)
+		assert.Error(t, err, resource)
+	}
+
+	protectedResource, err := NewProtectedResource(ProtectedResourceMetadata{Resource: "HTTPS://MCP.example.com/mcp/"}, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, "https://mcp.example.com/mcp", protectedResource.metadata.Resource)
+}
+
+func TestProtectedResource_TokenAudience(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	metadata := ProtectedResourceMetadata{Resource: "https://mcp.example.com/mcp"}
+
+	tests := []struct {
+		name     string
+		audience string
+		opts     []ProtectedResourceOption
+		status   int
+	}{
+		{"issued for the resource", "https://mcp.example.com/mcp", nil, http.StatusNoContent},
+		{"issued for the canonical resource", "https://MCP.example.com/mcp/", nil, http.StatusNoContent},
+		{"issued for the origin of the resource", "https://mcp.example.com", nil, http.StatusUnauthorized},
+		{"issued for the host of the request", "https://other.example.com/mcp", nil, http.StatusUnauthorized},
+		{"any audience", "https://other.example.com/mcp", []ProtectedResourceOption{WithAnyTokenAudience()}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := TokenVerifierFunc(func(ctx context.Context, token string) (*TokenClaims, error) {
+				return &TokenClaims{Subject: "user-1", Audience: []string{tt.audience}}, nil
+			})
+			protectedResource, err := NewProtectedResource(metadata, verifier, tt.opts...)
+			require.NoError(t, err)
+
+			// the Host header is chosen by the client, and never trusted
+			req := httptest.NewRequest(http.MethodPost, "https://other.example.com/mcp", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			protectedResource.Middleware(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package server
+
+import (
+	"fmt"
+	"net"
+)
+
+// unixPeerCredentials returns the credentials of the process connected to the
+// unix socket.
+func unixPeerCredentials(conn *net.UnixConn) (PeerCredentials, error) {
+	return PeerCredentials{}, fmt.Errorf("peer credentials of unix sockets: %w", ErrUnsupported)
+}
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PolicyEffect is the decision of a policy rule matching a request.
+type PolicyEffect string
+
+const (
+	PolicyAllow PolicyEffect = "allow"
+	PolicyDeny  PolicyEffect = "deny"
+)
+
+// PolicyRequest is a request a Policy decides on.
+type PolicyRequest struct {
+	// Method is mcp.MethodToolsCall, mcp.MethodResourcesRead or mcp.MethodPromptsGet.
+	// The resources/subscribe and completion/complete requests are decided as
+	// a resources/read or prompts/get of the resource or prompt they refer to.
+	Method mcp.MCPMethod
+	// Name is the name of the tool or prompt, or the URI of the resource. For
+	// resource templates, it is the URI template: reading a resource through a
+	// template is decided for both its URI and the URI template.
+	Name string
+	// Tool is the called tool, nil for the other methods.
+	Tool *mcp.Tool
+}
+
+// ContextValueFunc returns a value of the request context for the Context
+// conditions of the policy rules, or "" if it has none.
+type ContextValueFunc func(ctx context.Context) string
+
+// PolicyRule applies its effect to the requests matching all its conditions.
+// Empty conditions match every request, and the tool conditions only match
+// tools/call requests. Patterns are globs where * matches any sequence of
+// characters and ? any single character.
+type PolicyRule struct {
+	// Name identifies the rule in the denial errors.
+	Name   string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Effect PolicyEffect `json:"effect" yaml:"effect"`
+	// Methods are the methods the rule applies to.
+	Methods []mcp.MCPMethod `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// Names are patterns of the tool and prompt names, and resource URIs.
+	Names []string `json:"names,omitempty" yaml:"names,omitempty"`
+	// ReadOnly, Destructive and OpenWorld match the annotation hints of the
+	// called tool, defaulting as in the specification when a hint is unset.
+	ReadOnly    *bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	Destructive *bool `json:"destructive,omitempty" yaml:"destructive,omitempty"`
+	OpenWorld   *bool `json:"openWorld,omitempty" yaml:"openWorld,omitempty"`
+	// Clients are patterns of the client name sent on initialize.
+	Clients []string `json:"clients,omitempty" yaml:"clients,omitempty"`
+	// Principals are patterns of the ID of the authenticated principal.
+	Principals []string `json:"principals,omitempty" yaml:"principals,omitempty"`
+	// Roles match principals having any of the roles.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	// Params maps session parameters to the patterns of their value.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	// Context maps the names of Policy.ContextValues to the patterns of their value.
+	Context map[string]string `json:"context,omitempty" yaml:"context,omitempty"`
+}
+
+// Policy decides whether tools/call, resources/read and prompts/get requests
+// are allowed. The first rule matching a request decides, and the default
+// effect applies to the requests no rule matches. The tools, resources,
+// resource templates and prompts a policy denies are also hidden from the
+// lists, and cannot be subscribed to or completed.
+type Policy struct {
+	// ReadOnly denies every tool not annotated as read-only, whatever the rules.
+	ReadOnly bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	// DefaultEffect is the effect for the requests no rule matches, allow if empty.
+	DefaultEffect PolicyEffect `json:"defaultEffect,omitempty" yaml:"defaultEffect,omitempty"`
+	Rules         []PolicyRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// ContextValues are the context values the rules can match, by name.
+	ContextValues map[string]ContextValueFunc `json:"-" yaml:"-"`
+}
+
+// ParsePolicy parses a policy from a JSON or YAML document, and validates it.
+// Unknown fields are rejected.
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("invalid policy: empty document")
+		}
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks the effects, methods and patterns of the policy.
+func (p *Policy) Validate() error {
+	if p.DefaultEffect != "" && p.DefaultEffect != PolicyAllow && p.DefaultEffect != PolicyDeny {
+		return fmt.Errorf("invalid policy: unknown default effect %q", p.DefaultEffect)
+	}
+	for i, rule := range p.Rules {
+		if rule.Effect != PolicyAllow && rule.Effect != PolicyDeny {
+			return fmt.Errorf("invalid policy: rule %s: unknown effect %q", rule.id(i), rule.Effect)
+		}
+		for _, method := range rule.Methods {
+			switch method {
+			case mcp.MethodToolsCall, mcp.MethodResourcesRead, mcp.MethodPromptsGet:
+			default:
+				return fmt.Errorf("invalid policy: rule %s: unsupported method %q", rule.id(i), method)
+			}
+		}
+		for name := range rule.Context {
+			if _, ok := p.ContextValues[name]; !ok && p.ContextValues != nil {
+				return fmt.Errorf("invalid policy: rule %s: unknown context value %q", rule.id(i), name)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns an error wrapping ErrPolicyDenied if the policy denies the
+// request made with ctx.
+func (p *Policy) Evaluate(ctx context.Context, request PolicyRequest) error {
+	if p.ReadOnly && request.Tool != nil && !hint(request.Tool.Annotations.ReadOnlyHint, false) {
+		return fmt.Errorf("%w: tool '%s' is not read-only", ErrPolicyDenied, request.Name)
+	}
+	for i, rule := range p.Rules {
+		if !rule.matches(ctx, p, request) {
+			continue
+		}
+		if rule.Effect == PolicyAllow {
+			return nil
+		}
+		return fmt.Errorf("%w: %s '%s' matches rule %s", ErrPolicyDenied, request.Method, request.Name, rule.id(i))
+	}
+	if p.DefaultEffect == PolicyDeny {
+		return fmt.Errorf("%w: no rule allows %s '%s'", ErrPolicyDenied, request.Method, request.Name)
+	}
+	return nil
+}
+
+// id returns the name of the rule, or its position if it has none
+func (r *PolicyRule) id(index int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+func (r *PolicyRule) matches(ctx context.Context, policy *Policy, request PolicyRequest) bool {
+	if len(r.Methods) > 0 && !slices.Contains(r.Methods, request.Method) {
+		return false
+	}
+	if len(r.Names) > 0 && !matchesAny(r.Names, request.Name) {
+		return false
+	}
+
+	if r.ReadOnly != nil || r.Destructive != nil || r.OpenWorld != nil {
+		if request.Tool == nil {
+			return false
+		}
+		annotations := request.Tool.Annotations
+		if r.ReadOnly != nil && *r.ReadOnly != hint(annotations.ReadOnlyHint, false) {
+			return false
+		}
+		if r.Destructive != nil && *r.Destructive != hint(annotations.DestructiveHint, true) {
+			return false
+		}
+		if r.OpenWorld != nil && *r.OpenWorld != hint(annotations.OpenWorldHint, true) {
+			return false
+		}
+	}
+
+	session := ClientSessionFromContext(ctx)
+	if len(r.Clients) > 0 {
+		sessionWithClientInfo, ok := session.(SessionWithClientInfo)
+		if !ok || !matchesAny(r.Clients, sessionWithClientInfo.GetClientInfo().Name) {
+			return false
+		}
+	}
+	if len(r.Principals) > 0 || len(r.Roles) > 0 {
+		principal := PrincipalFromContext(ctx)
+		if principal == nil {
+			return false
+		}
+		if len(r.Principals) > 0 && !matchesAny(r.Principals, principal.ID) {
+			return false
+		}
+		if len(r.Roles) > 0 && !slices.ContainsFunc(r.Roles, principal.HasRole) {
+			return false
+		}
+	}
+	if len(r.Params) > 0 {
+		sessionWithParams, ok := session.(SessionWithParams)
+		if !ok {
+			return false
+		}
+		params := sessionWithParams.Params()
+		for name, pattern := range r.Params {
+			if value, ok := params[name]; !ok || !matchGlob(pattern, value) {
+				return false
+			}
+		}
+	}
+	for name, pattern := range r.Context {
+		valueFunc, ok := policy.ContextValues[name]
+		if !ok || !matchGlob(pattern, valueFunc(ctx)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hint returns the value of an annotation hint, or its default if unset
+func hint(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether value matches pattern, where * matches any
+// sequence of characters, including none, and ? any single character.
+func matchGlob(pattern, value string) bool {
+	p, v := []rune(pattern), []rune(value)
+	// position of the last * in the pattern, and of the value it was tried at
+	star, retry := -1, 0
+	i, j := 0, 0
+	for j < len(v) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == v[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, retry = i, j
+			i++
+		case star >= 0:
+			// let the last * match one more character
+			retry++
+			i, j = star+1, retry
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+// WithPolicy adds a policy the tools/call, resources/read and prompts/get
+// requests must be allowed by. Denied requests fail with an error wrapping
+// ErrPolicyDenied, which is also passed to the OnError hooks, and the denied
+// tools, resources, resource templates and prompts are hidden from the lists.
+// The resources/subscribe and completion/complete requests are denied along
+// with the resource or prompt they refer to.
+func WithPolicy(policy *Policy) ServerOption {
+	return func(s *MCPServer) {
+		s.policies = append(s.policies, policy)
+	}
+}
+
+// WithReadOnlyMode hides and denies every tool that is not annotated as read-only.
+func WithReadOnlyMode() ServerOption {
+	return WithPolicy(&Policy{ReadOnly: true})
+}
+
+// authorize returns an error wrapping ErrPolicyDenied if a policy of the
+// server denies the request
+func (s *MCPServer) authorize(ctx context.Context, request PolicyRequest) error {
+	for _, policy := range s.policies {
+		if err := policy.Evaluate(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hideDenied removes the items the policies of the server deny from a list
+func hideDenied[T any](ctx context.Context, s *MCPServer, items []T, request func(*T) PolicyRequest) []T {
+	if len(s.policies) == 0 {
+		return items
+	}
+	allowed := make([]T, 0, len(items))
+	for i := range items {
+		if s.authorize(ctx, request(&items[i])) == nil {
+			allowed = append(allowed, items[i])
+		}
+	}
+	return allowed
+}
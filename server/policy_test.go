@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "file:///a/b.txt", true},
+		{"read_*", "read_file", true},
+		{"read_*", "write_file", false},
+		{"*_file", "delete_file", true},
+		{"file:///docs/*.md", "file:///docs/a/b.md", true},
+		{"file:///docs/*.md", "file:///docs/a.txt", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*a*b", "xaxxb", true},
+		{"*a*b", "xaxxbx", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.value), "%q %q", tt.pattern, tt.value)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	yamlPolicy := `
+defaultEffect: deny
+rules:
+  - name: no-destructive
+    effect: deny
+    methods: [tools/call]
+    destructive: true
+  - effect: allow
+    names: ["read_*", "file:///*"]
+    params:
+      tenant: acme
+`
+	jsonPolicy := `{
+  "defaultEffect": "deny",
+  "rules": [
+    {"name": "no-destructive", "effect": "deny", "methods": ["tools/call"], "destructive": true},
+    {"effect": "allow", "names": ["read_*", "file:///*"], "params": {"tenant": "acme"}}
+  ]
+}`
+	for name, document := range map[string]string{"yaml": yamlPolicy, "json": jsonPolicy} {
+		t.Run(name, func(t *testing.T) {
+			policy, err := ParsePolicy([]byte(document))
+			require.NoError(t, err)
+			destructive := true
+			assert.Equal(t, &Policy{
+				DefaultEffect: PolicyDeny,
+				Rules: []PolicyRule{
+					{Name: "no-destructive", Effect: PolicyDeny, Methods: []mcp.MCPMethod{mcp.MethodToolsCall}, Destructive: &destructive},
+					{Effect: PolicyAllow, Names: []string{"read_*", "file:///*"}, Params: map[string]string{"tenant": "acme"}},
+				},
+			}, policy)
+		})
+	}
+
+	invalid := map[string]string{
+		"empty document": "",
+		"unknown field":  `{"rules": [{"effect": "allow", "tool": "x"}]}`,
+		"unknown effect": `{"rules": [{"effect": "permit"}]}`,
+		"missing effect": `{"rules": [{"names": ["x"]}]}`,
+		"default effect": `{"defaultEffect": "maybe"}`,
+		"method":         `{"rules": [{"effect": "deny", "methods": ["tools/list"]}]}`,
+	}
+	for name, document := range invalid {
+		_, err := ParsePolicy([]byte(document))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	readOnlyTool := mcp.NewTool("read_file", mcp.WithReadOnlyHintAnnotation(true), mcp.WithDestructiveHintAnnotation(false))
+	writeTool := mcp.NewTool("write_file", mcp.WithDestructiveHintAnnotation(false))
+	deleteTool := mcp.NewTool("delete_file")
+	call := func(tool mcp.Tool) PolicyRequest {
+		return PolicyRequest{Method: mcp.MethodToolsCall, Name: tool.Name, Tool: &tool}
+	}
+	read := PolicyRequest{Method: mcp.MethodResourcesRead, Name: "file:///docs/a.md"}
+
+	t.Run("read-only mode", func(t *testing.T) {
+		policy := &Policy{ReadOnly: true, Rules: []PolicyRule{{Effect: PolicyAllow}}}
+		assert.NoError(t, policy.Evaluate(context.Background(), call(readOnlyTool)))
+		assert.ErrorIs(t, policy.Evaluate(context.Background(), call(writeTool)), ErrPolicyDenied)
+		assert.ErrorIs(t, policy.Evaluate(context.Background(), call(mcp.Tool{Name: "unannotated"})), ErrPolicyDenied)
+		assert.NoError(t, policy.Evaluate(context.Background(), read))
+	})
+
+	t.Run("first matching rule decides", func(t *testing.T) {
+		destructive := true
+		policy := &Policy{
+			DefaultEffect: PolicyDeny,
+			Rules: []PolicyRule{
+				{Name: "no-destructive", Effect: PolicyDeny, Destructive: &destructive},
+				{Effect: PolicyAllow, Names: []string{"*_file"}},
+			},
+		}
+		assert.NoError(t, policy.Evaluate(context.Background(), call(readOnlyTool)))
+		assert.NoError(t, policy.Evaluate(context.Background(), call(writeTool)))
+		err := policy.Evaluate(context.Background(), call(deleteTool))
+		assert.ErrorIs(t, err, ErrPolicyDenied)
+		assert.EqualError(t, err, `denied by policy: tools/call 'delete_file' matches rule "no-destructive"`)
+		// tool conditions do not match other requests, which fall back to the default
+		err = policy.Evaluate(context.Background(), read)
+		assert.EqualError(t, err, `denied by policy: no rule allows resources/read 'file:///docs/a.md'`)
+	})
+
+	t.Run("session and context conditions", func(t *testing.T) {
+		policy := &Policy{
+			DefaultEffect: PolicyDeny,
+			Rules: []PolicyRule{
+				{Effect: PolicyAllow, Clients: []string{"trusted-*"}},
+				{Effect: PolicyAllow, Params: map[string]string{"tenant": "acme"}},
+				{Effect: PolicyAllow, Roles: []string{"admin"}},
+				{Effect: PolicyAllow, Context: map[string]string{"tenant": "a*"}},
+			},
+			ContextValues: map[string]ContextValueFunc{
+				"tenant": func(ctx context.Context) string {
+					tenant, _ := ctx.Value(tenantKey{}).(string)
+					return tenant
+				},
+			},
+		}
+		server := NewMCPServer("test", "1.0.0")
+		denied := context.Background()
+		assert.ErrorIs(t, policy.Evaluate(denied, read), ErrPolicyDenied)
+
+		client := NewInProcessSession("client", nil)
+		client.SetClientInfo(mcp.Implementation{Name: "trusted-ide"})
+		assert.NoError(t, policy.Evaluate(server.WithContext(denied, client), read))
+		client.SetClientInfo(mcp.Implementation{Name: "untrusted"})
+		assert.ErrorIs(t, policy.Evaluate(server.WithContext(denied, client), read), ErrPolicyDenied)
+
+		session := &sessionTestClientWithParams{sessionID: "params", params: map[string]string{"tenant": "acme"}}
+		assert.NoError(t, policy.Evaluate(server.WithContext(denied, session), read))
+
+		assert.NoError(t, policy.Evaluate(withPrincipal(denied, alice), read))
+		assert.ErrorIs(t, policy.Evaluate(withPrincipal(denied, bob), read), ErrPolicyDenied)
+
+		assert.NoError(t, policy.Evaluate(context.WithValue(denied, tenantKey{}, "acme"), read))
+		assert.ErrorIs(t, policy.Evaluate(context.WithValue(denied, tenantKey{}, "globex"), read), ErrPolicyDenied)
+	})
+}
+
+func TestMCPServer_Policy(t *testing.T) {
+	var errs []error
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		errs = append(errs, err)
+	})
+	policy, err := ParsePolicy([]byte(`
+rules:
+  - effect: deny
+    names: ["admin-*", "file:///admin.*", "file:///admin/{id}"]
+`))
+	require.NoError(t, err)
+	server := newFilteredServer(WithHooks(hooks), WithPolicy(policy), WithReadOnlyMode(), WithResourceCapabilities(true, false))
+	server.AddTool(mcp.NewTool("read-tool", mcp.WithReadOnlyHintAnnotation(true)), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	// the filters let admins see everything
+	ctx := context.WithValue(context.Background(), roleKey{}, "admin")
+	ctx = server.WithContext(ctx, NewInProcessSession("session-1", nil))
+
+	t.Run("denied items are hidden", func(t *testing.T) {
+		response, ok := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		tools := response.Result.(mcp.ListToolsResult).Tools
+		require.Len(t, tools, 1)
+		assert.Equal(t, "read-tool", tools[0].Name)
+
+		response, ok = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		resources := response.Result.(mcp.ListResourcesResult).Resources
+		require.Len(t, resources, 1)
+		assert.Equal(t, "file:///public.txt", resources[0].URI)
+
+		response, ok = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		assert.Empty(t, response.Result.(mcp.ListResourceTemplatesResult).ResourceTemplates)
+
+		response, ok = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		prompts := response.Result.(mcp.ListPromptsResult).Prompts
+		require.Len(t, prompts, 1)
+		assert.Equal(t, "prompt", prompts[0].Name)
+	})
+
+	t.Run("denied requests fail", func(t *testing.T) {
+		errs = nil
+		messages := []string{
+			`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"tool"}}`,
+			`{"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"file:///admin.txt"}}`,
+			// the resources of a denied template are denied too
+			`{"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"file:///admin/1"}}`,
+			`{"jsonrpc":"2.0","id":6,"method":"prompts/get","params":{"name":"admin-prompt"}}`,
+			`{"jsonrpc":"2.0","id":6,"method":"resources/subscribe","params":{"uri":"file:///admin.txt"}}`,
+			`{"jsonrpc":"2.0","id":6,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"admin-prompt"},"argument":{"name":"id","value":""}}}`,
+			`{"jsonrpc":"2.0","id":6,"method":"completion/complete","params":{"ref":{"type":"ref/resource","uri":"file:///admin/{id}"},"argument":{"name":"id","value":""}}}`,
+		}
+		for _, message := range messages {
+			errorResponse, ok := server.HandleMessage(ctx, []byte(message)).(mcp.JSONRPCError)
+			require.True(t, ok, message)
+			assert.Equal(t, mcp.INVALID_REQUEST, errorResponse.Error.Code, message)
+		}
+		require.Len(t, errs, len(messages))
+		for _, err := range errs {
+			assert.True(t, errors.Is(err, ErrPolicyDenied), err)
+		}
+
+		_, ok := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"read-tool"}}`)).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		_, ok = server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":8,"method":"prompts/get","params":{"name":"prompt"}}`)).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		_, ok = server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":9,"method":"resources/subscribe","params":{"uri":"file:///public.txt"}}`)).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+
+	t.Run("unknown items are not found whether or not they are denied", func(t *testing.T) {
+		messages := map[string]int{
+			`{"jsonrpc":"2.0","id":10,"method":"resources/read","params":{"uri":"file:///admin.json"}}`:                                                                  mcp.RESOURCE_NOT_FOUND,
+			`{"jsonrpc":"2.0","id":11,"method":"prompts/get","params":{"name":"admin-missing"}}`:                                                                         mcp.INVALID_PARAMS,
+			`{"jsonrpc":"2.0","id":12,"method":"resources/subscribe","params":{"uri":"file:///admin.json"}}`:                                                             mcp.RESOURCE_NOT_FOUND,
+			`{"jsonrpc":"2.0","id":13,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"admin-missing"},"argument":{"name":"id","value":""}}}`: mcp.INVALID_PARAMS,
+		}
+		for message, code := range messages {
+			errorResponse, ok := server.HandleMessage(ctx, []byte(message)).(mcp.JSONRPCError)
+			require.True(t, ok, message)
+			assert.Equal(t, code, errorResponse.Error.Code, message)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultProgressInterval is the minimum delay between two progress
+// notifications sent for the same request.
+const defaultProgressInterval = 100 * time.Millisecond
+
+// WithProgressInterval sets the minimum delay between two progress
+// notifications sent by a ProgressReporter for the same request. Reports made
+// more often are dropped, except for the final one. A zero interval disables
+// rate limiting.
+func WithProgressInterval(interval time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.progressInterval = interval
+	}
+}
+
+// ProgressReporter sends notifications/progress for the request being handled,
+// using the progress token supplied by the client in the request's _meta. If
+// the client did not ask for progress, reports are silently dropped.
+type ProgressReporter struct {
+	ctx      context.Context
+	server   *MCPServer
+	session  ClientSession
+	token    mcp.ProgressToken
+	interval time.Duration
+
+	mu           sync.Mutex
+	lastSent     time.Time
+	lastProgress float64
+	sent         bool
+}
+
+type progressReporterKey struct{}
+
+// ProgressReporterFromContext returns the progress reporter of the request
+// being handled. It never returns nil: outside of a request, or when the client
+// did not supply a progress token, the returned reporter does nothing.
+func ProgressReporterFromContext(ctx context.Context) *ProgressReporter {
+	if reporter, ok := ctx.Value(progressReporterKey{}).(*ProgressReporter); ok {
+		return reporter
+	}
+	return &ProgressReporter{}
+}
+
+// withProgressReporter adds a progress reporter for the request in message to
+// the context, if the client supplied a progress token and a session is
+// available to deliver the notifications.
+func (s *MCPServer) withProgressReporter(ctx context.Context, message json.RawMessage) context.Context {
+	var request struct {
+		Params struct {
+			Meta *mcp.Meta `json:"_meta,omitempty"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return ctx
+	}
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return ctx
+	}
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return ctx
+	}
+	reporter := &ProgressReporter{
+		ctx:      ctx,
+		server:   s,
+		session:  session,
+		token:    request.Params.Meta.ProgressToken,
+		interval: s.progressInterval,
+	}
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// Token returns the progress token supplied by the client, or nil.
+func (r *ProgressReporter) Token() mcp.ProgressToken {
+	return r.token
+}
+
+// Report sends a progress notification to the client. total may be zero if it
+// is unknown, and message may be empty.
+//
+// Reports that do not increase progress are dropped, as are reports made
+// sooner than the server's progress interval after the previous one, unless
+// they complete the operation (progress >= total).
+func (r *ProgressReporter) Report(progress, total float64, message string) error {
+	if r.token == nil || r.session == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	final := total > 0 && progress >= total
+	if r.sent && (progress <= r.lastProgress ||
+		(!final && now.Sub(r.lastSent) < r.interval)) {
+		r.mu.Unlock()
+		return nil
+	}
+	r.sent = true
+	r.lastSent = now
+	r.lastProgress = progress
+	r.mu.Unlock()
+
+	params := map[string]any{
+		"progressToken": r.token,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationProgress,
+			Params: mcp.NotificationParams{
+				AdditionalFields: params,
+			},
+		},
+	}
+	return r.server.sendNotificationCore(r.ctx, r.session, notification)
+}
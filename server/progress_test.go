@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressReporter(t *testing.T) {
+	type report struct {
+		progress, total float64
+		message         string
+	}
+
+	tests := []struct {
+		name     string
+		options  []ServerOption
+		message  string
+		reports  []report
+		expected []map[string]any
+	}{
+		{
+			name:    "no progress token",
+			message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"index"}}`,
+			reports: []report{{1, 2, "half"}, {2, 2, "done"}},
+		},
+		{
+			name:    "progress token",
+			options: []ServerOption{WithProgressInterval(0)},
+			message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"index","_meta":{"progressToken":"tok"}}}`,
+			reports: []report{{1, 2, "half"}, {2, 2, ""}},
+			expected: []map[string]any{
+				{"progressToken": "tok", "progress": float64(1), "total": float64(2), "message": "half"},
+				{"progressToken": "tok", "progress": float64(2), "total": float64(2)},
+			},
+		},
+		{
+			name:    "rate limited except final report",
+			options: []ServerOption{WithProgressInterval(time.Hour)},
+			message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"index","_meta":{"progressToken":42}}}`,
+			reports: []report{{1, 4, ""}, {2, 4, ""}, {3, 4, ""}, {4, 4, ""}},
+			expected: []map[string]any{
+				{"progressToken": float64(42), "progress": float64(1), "total": float64(4)},
+				{"progressToken": float64(42), "progress": float64(4), "total": float64(4)},
+			},
+		},
+		{
+			name:    "progress must increase",
+			options: []ServerOption{WithProgressInterval(0)},
+			message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"index","_meta":{"progressToken":"tok"}}}`,
+			reports: []report{{2, 0, ""}, {1, 0, ""}, {2, 0, ""}, {3, 0, ""}},
+			expected: []map[string]any{
+				{"progressToken": "tok", "progress": float64(2)},
+				{"progressToken": "tok", "progress": float64(3)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0", tt.options...)
+			server.AddTool(mcp.NewTool("index"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				reporter := ProgressReporterFromContext(ctx)
+				for _, r := range tt.reports {
+					if err := reporter.Report(r.progress, r.total, r.message); err != nil {
+						return nil, err
+					}
+				}
+				return mcp.NewToolResultText("indexed"), nil
+			})
+
+			session := fakeSession{
+				sessionID:           "s1",
+				notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+				initialized:         true,
+			}
+			ctx := server.WithContext(context.Background(), session)
+			response := server.HandleMessage(ctx, []byte(tt.message))
+			_, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok)
+
+			close(session.notificationChannel)
+			var received []map[string]any
+			for notification := range session.notificationChannel {
+				assert.Equal(t, mcp.MethodNotificationProgress, notification.Method)
+				received = append(received, notification.Params.AdditionalFields)
+			}
+			assert.Equal(t, tt.expected, received)
+		})
+	}
+}
+
+func TestProgressReporterFromContext_NoRequest(t *testing.T) {
+	reporter := ProgressReporterFromContext(context.Background())
+	require.NotNil(t, reporter)
+	assert.Nil(t, reporter.Token())
+	assert.NoError(t, reporter.Report(1, 1, "done"))
+}
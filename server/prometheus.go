@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the buckets of
+// the request duration histograms.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// queueDepthBuckets are the upper bounds of the buckets of the notification
+// queue depth histograms
+var queueDepthBuckets = []float64{0, 1, 5, 10, 25, 50, 100}
+
+// PrometheusMetrics keeps the metrics of a server in memory, and serves them
+// in the Prometheus text exposition format as an http.Handler:
+//
+//	metrics := server.NewPrometheusMetrics()
+//	s := server.NewMCPServer("example", "1.0.0", server.WithMetrics(metrics))
+//	http.Handle("/metrics", metrics)
+//
+// It exposes the following metrics:
+//
+//   - mcp_server_requests_total, the requests by method and tool
+//   - mcp_server_request_errors_total, the failed requests by method, tool and JSON-RPC error code
+//   - mcp_server_request_duration_seconds, a histogram of the request durations by method and tool
+//   - mcp_server_active_sessions, the registered sessions by transport
+//   - mcp_server_notification_queue_depth, a histogram of the depth of the
+//     notification queues of the sessions as notifications are queued, by transport
+//   - mcp_server_notifications_dropped_total, the notifications dropped on full queues by transport
+type PrometheusMetrics struct {
+	durationBuckets []float64
+
+	mu         sync.Mutex
+	requests   map[requestLabels]*histogram
+	errors     map[requestErrorLabels]uint64
+	sessions   map[string]int64
+	queueDepth map[string]*histogram
+	dropped    map[string]uint64
+}
+
+var _ Metrics = (*PrometheusMetrics)(nil)
+
+type requestLabels struct {
+	method string
+	tool   string
+}
+
+type requestErrorLabels struct {
+	requestLabels
+	code int
+}
+
+// histogram counts observations in cumulative buckets
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// NewPrometheusMetrics returns empty metrics, whose request duration
+// histograms have the given buckets, DefaultDurationBuckets if none.
+func NewPrometheusMetrics(durationBuckets ...float64) *PrometheusMetrics {
+	if len(durationBuckets) == 0 {
+		durationBuckets = DefaultDurationBuckets
+	}
+	durationBuckets = slices.Clone(durationBuckets)
+	slices.Sort(durationBuckets)
+	return &PrometheusMetrics{
+		durationBuckets: durationBuckets,
+		requests:        make(map[requestLabels]*histogram),
+		errors:          make(map[requestErrorLabels]uint64),
+		sessions:        make(map[string]int64),
+		queueDepth:      make(map[string]*histogram),
+		dropped:         make(map[string]uint64),
+	}
+}
+
+// RecordRequest implements Metrics.
+func (m *PrometheusMetrics) RecordRequest(request RequestMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := requestLabels{method: string(request.Method), tool: request.Tool}
+	durations, ok := m.requests[labels]
+	if !ok {
+		durations = newHistogram(m.durationBuckets)
+		m.requests[labels] = durations
+	}
+	durations.observe(request.Duration.Seconds())
+	if request.ErrorCode != 0 {
+		m.errors[requestErrorLabels{requestLabels: labels, code: request.ErrorCode}]++
+	}
+}
+
+// SessionStarted implements Metrics.
+func (m *PrometheusMetrics) SessionStarted(transport string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[transport]++
+}
+
+// SessionEnded implements Metrics.
+func (m *PrometheusMetrics) SessionEnded(transport string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[transport]--
+}
+
+// NotificationQueued implements Metrics.
+func (m *PrometheusMetrics) NotificationQueued(transport string, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	depths, ok := m.queueDepth[transport]
+	if !ok {
+		depths = newHistogram(queueDepthBuckets)
+		m.queueDepth[transport] = depths
+	}
+	depths.observe(float64(depth))
+}
+
+// NotificationDropped implements Metrics.
+func (m *PrometheusMetrics) NotificationDropped(transport string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropped[transport]++
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+// The series of each metric are sorted by their labels.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	m.mu.Lock()
+
+	requests := sortedKeys(m.requests, func(a, b requestLabels) int {
+		return strings.Compare(a.method+"\x00"+a.tool, b.method+"\x00"+b.tool)
+	})
+	writeHeader(&buf, "mcp_server_requests_total", "counter", "Requests handled by the server.")
+	for _, labels := range requests {
+		writeSample(&buf, "mcp_server_requests_total", formatLabels("method", labels.method, "tool", labels.tool), float64(m.requests[labels].count))
+	}
+
+	requestErrors := sortedKeys(m.errors, func(a, b requestErrorLabels) int {
+		if c := strings.Compare(a.method+"\x00"+a.tool, b.method+"\x00"+b.tool); c != 0 {
+			return c
+		}
+		return a.code - b.code
+	})
+	writeHeader(&buf, "mcp_server_request_errors_total", "counter", "Requests answered with an error, by JSON-RPC error code.")
+	for _, labels := range requestErrors {
+		writeSample(&buf, "mcp_server_request_errors_total",
+			formatLabels("method", labels.method, "tool", labels.tool, "code", strconv.Itoa(labels.code)), float64(m.errors[labels]))
+	}
+
+	writeHeader(&buf, "mcp_server_request_duration_seconds", "histogram", "Duration of the requests handled by the server.")
+	for _, labels := range requests {
+		writeHistogram(&buf, "mcp_server_request_duration_seconds", []string{"method", labels.method, "tool", labels.tool}, m.requests[labels])
+	}
+
+	writeHeader(&buf, "mcp_server_active_sessions", "gauge", "Sessions registered with the server.")
+	for _, transport := range sortedKeys(m.sessions, strings.Compare) {
+		writeSample(&buf, "mcp_server_active_sessions", formatLabels("transport", transport), float64(m.sessions[transport]))
+	}
+
+	writeHeader(&buf, "mcp_server_notification_queue_depth", "histogram", "Notifications in the queue of a session as a notification is queued.")
+	for _, transport := range sortedKeys(m.queueDepth, strings.Compare) {
+		writeHistogram(&buf, "mcp_server_notification_queue_depth", []string{"transport", transport}, m.queueDepth[transport])
+	}
+
+	writeHeader(&buf, "mcp_server_notifications_dropped_total", "counter", "Notifications dropped because the queue of their session was full.")
+	for _, transport := range sortedKeys(m.dropped, strings.Compare) {
+		writeSample(&buf, "mcp_server_notifications_dropped_total", formatLabels("transport", transport), float64(m.dropped[transport]))
+	}
+
+	m.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+func sortedKeys[K comparable, V any](series map[K]V, compare func(a, b K) int) []K {
+	keys := make([]K, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compare)
+	return keys
+}
+
+func writeHeader(buf *bytes.Buffer, name, metricType, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, value float64) {
+	fmt.Fprintf(buf, "%s%s %s\n", name, labels, formatValue(value))
+}
+
+// writeHistogram writes the cumulative buckets, the sum and the count of a
+// histogram, whose labels are given as name and value pairs
+func writeHistogram(buf *bytes.Buffer, name string, labels []string, h *histogram) {
+	for i, bound := range h.buckets {
+		writeSample(buf, name+"_bucket", formatLabels(append(slices.Clone(labels), "le", formatValue(bound))...), float64(h.counts[i]))
+	}
+	writeSample(buf, name+"_bucket", formatLabels(append(slices.Clone(labels), "le", "+Inf")...), float64(h.count))
+	writeSample(buf, name+"_sum", formatLabels(labels...), h.sum)
+	writeSample(buf, name+"_count", formatLabels(labels...), float64(h.count))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats name and value pairs as a label set
+func formatLabels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, pairs[i], labelValueEscaper.Replace(pairs[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
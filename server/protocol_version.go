@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// headerKeyProtocolVersion is the HTTP header carrying the negotiated protocol
+// version on every request after initialization.
+const headerKeyProtocolVersion = "Mcp-Protocol-Version"
+
+// ProtocolVersionFromContext returns the protocol version negotiated with the
+// client of the current session. It returns an empty string if there is no
+// session or the version is not known, in which case the latest version is
+// assumed.
+func ProtocolVersionFromContext(ctx context.Context) string {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return ""
+	}
+	if sessionWithVersion, ok := session.(SessionWithProtocolVersion); ok {
+		return sessionWithVersion.GetProtocolVersion()
+	}
+	return ""
+}
+
+// validateProtocolVersionHeader checks the protocol version sent by an HTTP
+// client. A missing header is accepted.
+func validateProtocolVersionHeader(version string) error {
+	if version == "" || isSupportedProtocolVersion(version) {
+		return nil
+	}
+	return fmt.Errorf("unsupported protocol version: %s", version)
+}
+
+func isSupportedProtocolVersion(version string) bool {
+	return slices.Contains(mcp.ValidProtocolVersions, version)
+}
+
+// supportsBatches reports whether the protocol version has JSON-RPC batching,
+// which version 2025-06-18 removed. Batches are accepted while the version is
+// not known.
+func supportsBatches(version string) bool {
+	return version == "" || !mcp.ProtocolVersionAtLeast(version, mcp.ProtocolVersion20250618)
+}
+
+// toolsForProtocolVersion removes the fields of the tools that the protocol
+// version does not know about. The tools are copied, not modified.
+func toolsForProtocolVersion(version string, tools []mcp.Tool) []mcp.Tool {
+	if mcp.ProtocolVersionAtLeast(version, mcp.ProtocolVersion20250618) {
+		return tools
+	}
+	adapted := make([]mcp.Tool, len(tools))
+	for i, tool := range tools {
+		tool.Title = ""
+		tool.OutputSchema = mcp.ToolOutputSchema{}
+		tool.RawOutputSchema = nil
+		adapted[i] = tool
+	}
+	return adapted
+}
+
+// promptsForProtocolVersion removes the fields of the prompts that the
+// protocol version does not know about. The prompts are copied, not modified.
+func promptsForProtocolVersion(version string, prompts []mcp.Prompt) []mcp.Prompt {
+	if mcp.ProtocolVersionAtLeast(version, mcp.ProtocolVersion20250618) {
+		return prompts
+	}
+	adapted := make([]mcp.Prompt, len(prompts))
+	for i, prompt := range prompts {
+		prompt.Title = ""
+		adapted[i] = prompt
+	}
+	return adapted
+}
+
+// toolResultForProtocolVersion adapts a tool result to a client using an
+// older protocol version: structured content is dropped, as the result already
+// carries its serialized form as text, and resource links are replaced by a
+// textual reference to the resource. A result with structured content only
+// gets its serialized form as text.
+func toolResultForProtocolVersion(version string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil || mcp.ProtocolVersionAtLeast(version, mcp.ProtocolVersion20250618) {
+		return result
+	}
+	adapted := *result
+	adapted.StructuredContent = nil
+	adapted.Content = make([]mcp.Content, len(result.Content))
+	for i, content := range result.Content {
+		if link, ok := content.(mcp.ResourceLink); ok {
+			content = mcp.NewTextContent(resourceLinkText(link))
+		}
+		adapted.Content[i] = content
+	}
+	if len(adapted.Content) == 0 && result.StructuredContent != nil {
+		if text, err := json.Marshal(result.StructuredContent); err == nil {
+			adapted.Content = []mcp.Content{mcp.NewTextContent(string(text))}
+		}
+	}
+	return &adapted
+}
+
+func resourceLinkText(link mcp.ResourceLink) string {
+	text := fmt.Sprintf("Resource %s: %s", link.Name, link.URI)
+	if link.Description != "" {
+		text += " (" + link.Description + ")"
+	}
+	return text
+}
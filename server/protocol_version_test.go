@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVersionSession is a fakeSession storing its protocol version.
+type fakeVersionSession struct {
+	fakeSession
+	mu      sync.Mutex
+	version string
+}
+
+func (f *fakeVersionSession) GetProtocolVersion() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.version
+}
+
+func (f *fakeVersionSession) SetProtocolVersion(version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = version
+}
+
+var _ SessionWithProtocolVersion = (*fakeVersionSession)(nil)
+
+func newVersionedTestServer() *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0", WithTitle("Test Server"))
+	server.AddTool(mcp.NewTool("weather",
+		mcp.WithToolTitle("Weather"),
+		mcp.WithOutputSchema(mcp.ToolOutputSchema{
+			Properties: map[string]any{"temperature": map[string]any{"type": "number"}},
+		}),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultStructured(map[string]any{"temperature": 21})
+		result.Content = append(result.Content, mcp.NewResourceLink("file:///forecast.txt", "forecast", "The forecast", "text/plain"))
+		return result, nil
+	})
+	server.AddPrompt(mcp.NewPrompt("greeting", mcp.WithPromptTitle("Greeting")),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+	return server
+}
+
+func TestMCPServer_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		clientVersion string
+		wantVersion   string
+		wantFeatures  bool
+	}{
+		{
+			name:          "latest version",
+			clientVersion: mcp.ProtocolVersion20250618,
+			wantVersion:   mcp.ProtocolVersion20250618,
+			wantFeatures:  true,
+		},
+		{
+			name:          "older version",
+			clientVersion: mcp.ProtocolVersion20250326,
+			wantVersion:   mcp.ProtocolVersion20250326,
+			wantFeatures:  false,
+		},
+		{
+			name:          "unknown version",
+			clientVersion: "2099-01-01",
+			wantVersion:   mcp.LATEST_PROTOCOL_VERSION,
+			wantFeatures:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newVersionedTestServer()
+			session := &fakeVersionSession{fakeSession: fakeSession{sessionID: "s1"}}
+			ctx := server.WithContext(context.Background(), session)
+
+			send := func(message string) map[string]any {
+				response := server.HandleMessage(ctx, []byte(message))
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok, "unexpected response %#v", response)
+				data, err := json.Marshal(resp.Result)
+				require.NoError(t, err)
+				var result map[string]any
+				require.NoError(t, json.Unmarshal(data, &result))
+				return result
+			}
+
+			initResult := send(fmt.Sprintf(
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q}}`, tt.clientVersion))
+			assert.Equal(t, tt.wantVersion, initResult["protocolVersion"])
+			assert.Equal(t, tt.wantVersion, session.GetProtocolVersion())
+			assert.Equal(t, tt.wantVersion, ProtocolVersionFromContext(ctx))
+			_, hasServerTitle := initResult["serverInfo"].(map[string]any)["title"]
+			assert.Equal(t, tt.wantFeatures, hasServerTitle)
+
+			tool := send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)["tools"].([]any)[0].(map[string]any)
+			_, hasTitle := tool["title"]
+			_, hasOutputSchema := tool["outputSchema"]
+			assert.Equal(t, tt.wantFeatures, hasTitle)
+			assert.Equal(t, tt.wantFeatures, hasOutputSchema)
+
+			prompt := send(`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`)["prompts"].([]any)[0].(map[string]any)
+			_, hasTitle = prompt["title"]
+			assert.Equal(t, tt.wantFeatures, hasTitle)
+
+			callResult := send(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"weather"}}`)
+			_, hasStructured := callResult["structuredContent"]
+			assert.Equal(t, tt.wantFeatures, hasStructured)
+			content := callResult["content"].([]any)
+			require.Len(t, content, 2)
+			assert.Equal(t, "text", content[0].(map[string]any)["type"])
+			if tt.wantFeatures {
+				assert.Equal(t, "resource_link", content[1].(map[string]any)["type"])
+			} else {
+				assert.Equal(t, map[string]any{
+					"type": "text",
+					"text": "Resource forecast: file:///forecast.txt (The forecast)",
+				}, content[1])
+			}
+		})
+	}
+
+	t.Run("without a session the latest version is assumed", func(t *testing.T) {
+		assert.Equal(t, "", ProtocolVersionFromContext(context.Background()))
+		assert.True(t, mcp.ProtocolVersionAtLeast("", mcp.ProtocolVersion20250618))
+	})
+}
+
+func TestToolResultForProtocolVersion(t *testing.T) {
+	result := &mcp.CallToolResult{StructuredContent: map[string]any{"ok": true}}
+
+	adapted := toolResultForProtocolVersion(mcp.ProtocolVersion20241105, result)
+	assert.Nil(t, adapted.StructuredContent)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent(`{"ok":true}`)}, adapted.Content)
+	// The original result is left untouched
+	assert.NotNil(t, result.StructuredContent)
+	assert.Empty(t, result.Content)
+
+	assert.Same(t, result, toolResultForProtocolVersion(mcp.ProtocolVersion20250618, result))
+}
+
+func TestStreamableHTTP_ProtocolVersionHeader(t *testing.T) {
+	server := httptest.NewServer(NewStreamableHTTPServer(newVersionedTestServer()))
+	defer server.Close()
+
+	var sessionID string
+	post := func(t *testing.T, version string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		if version != "" {
+			req.Header.Set(headerKeyProtocolVersion, version)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	listTools := func(t *testing.T, version string) map[string]any {
+		resp := post(t, version, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var response struct {
+			Result struct {
+				Tools []map[string]any `json:"tools"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Len(t, response.Result.Tools, 1)
+		return response.Result.Tools[0]
+	}
+
+	resp := post(t, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	resp.Body.Close()
+	sessionID = resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	t.Run("unsupported version is rejected", func(t *testing.T) {
+		resp := post(t, "1999-01-01", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("version from the header", func(t *testing.T) {
+		assert.Equal(t, "Weather", listTools(t, mcp.ProtocolVersion20250618)["title"])
+		assert.NotContains(t, listTools(t, mcp.ProtocolVersion20241105), "title")
+	})
+
+	t.Run("missing header assumes 2025-03-26", func(t *testing.T) {
+		assert.NotContains(t, listTools(t, ""), "title")
+	})
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RateLimitRequest is a request counted against the rate limits.
+type RateLimitRequest struct {
+	// Method is mcp.MethodToolsCall, mcp.MethodResourcesRead, or
+	// mcp.MethodSamplingCreateMessage for the sampling requests sent to the client.
+	Method mcp.MCPMethod
+	// Name is the name of the tool or the URI of the resource, empty for sampling.
+	Name string
+}
+
+// RateLimitKeyFunc returns the key of a request. A rate limit counts the
+// requests of each key separately.
+type RateLimitKeyFunc func(ctx context.Context, request RateLimitRequest) string
+
+// RateLimitBySession counts the requests of each session separately.
+func RateLimitBySession(ctx context.Context, request RateLimitRequest) string {
+	if session := ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// RateLimitByName counts the requests for each tool and resource separately.
+func RateLimitByName(ctx context.Context, request RateLimitRequest) string {
+	return request.Name
+}
+
+// RateLimitByPrincipal counts the requests of each authenticated principal
+// separately, and those of the anonymous clients together.
+func RateLimitByPrincipal(ctx context.Context, request RateLimitRequest) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.ID
+	}
+	return ""
+}
+
+// RateLimitByKeys counts the requests of each combination of the keys
+// separately, such as each tool of each session.
+func RateLimitByKeys(keys ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(ctx context.Context, request RateLimitRequest) string {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key(ctx, request)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// RateLimit limits the number of requests of each key. By default, it is a
+// token bucket refilled with Requests tokens per Interval. As a Quota, it
+// counts the requests in fixed windows of Interval instead, for cumulative
+// limits such as calls per day.
+type RateLimit struct {
+	// Name identifies the limit in the errors and hooks.
+	Name string
+	// Methods are the methods the limit applies to, all of them if empty.
+	Methods []mcp.MCPMethod
+	// Names are patterns of the tool names and resource URIs the limit
+	// applies to, all of them if empty. * matches any sequence of characters
+	// and ? any single character.
+	Names []string
+	// Key returns the key of a request. All requests share one key if nil.
+	Key RateLimitKeyFunc
+	// Requests is the number of requests allowed per Interval.
+	Requests int
+	Interval time.Duration
+	// Burst is the size of the token bucket, Requests if zero.
+	Burst int
+	// Quota counts the requests in fixed windows instead of a token bucket.
+	Quota bool
+}
+
+// RateLimitEvent is the state of a rate limit after counting a request.
+type RateLimitEvent struct {
+	Limit   string
+	Key     string
+	Request RateLimitRequest
+	Allowed bool
+	// Remaining is the number of requests the key may still make right away.
+	Remaining int
+	// RetryAfter is how long the key has to wait before its next request, if
+	// it was denied.
+	RetryAfter time.Duration
+}
+
+// RateLimitError is returned for the requests exceeding a rate limit. It
+// wraps ErrRateLimited, and is answered with a mcp.RATE_LIMIT_EXCEEDED error.
+type RateLimitError struct {
+	Limit      string
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit '%s' exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// WithRateLimit adds a rate limit to the tools/call and resources/read
+// requests and to the sampling requests sent to the client. The limits are
+// enforced by a tool and a resource handler middleware, added with the first
+// limit. Exceeding a limit fails the request with a RateLimitError, and every
+// counted request is passed to the OnRateLimit hooks. A request is only counted
+// if all the limits applying to it allow it.
+//
+// WithRateLimit panics if the Requests or Interval of the limit is not positive.
+func WithRateLimit(limit RateLimit) ServerOption {
+	if limit.Requests <= 0 || limit.Interval <= 0 {
+		panic(fmt.Sprintf("rate limit %q: requests and interval must be positive", limit.Name))
+	}
+	return func(s *MCPServer) {
+		if len(s.rateLimiters) == 0 {
+			s.middlewareMu.Lock()
+			s.toolHandlerMiddlewares = append(s.toolHandlerMiddlewares, s.rateLimitToolMiddleware)
+			s.resourceMiddlewares = append(s.resourceMiddlewares, s.rateLimitResourceMiddleware)
+			s.middlewareMu.Unlock()
+		}
+		s.rateLimiters = append(s.rateLimiters, newRateLimiter(limit))
+	}
+}
+
+func (s *MCPServer) rateLimitToolMiddleware(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := s.checkRateLimits(ctx, RateLimitRequest{Method: mcp.MethodToolsCall, Name: request.Params.Name}); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+func (s *MCPServer) rateLimitResourceMiddleware(next ResourceHandlerFunc) ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if err := s.checkRateLimits(ctx, RateLimitRequest{Method: mcp.MethodResourcesRead, Name: request.Params.URI}); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+// checkRateLimits counts the request against the limits applying to it, and
+// returns a RateLimitError for the first one it exceeds. The request is only
+// counted if no limit is exceeded, so that a denied request does not spend the
+// tokens of the other limits.
+func (s *MCPServer) checkRateLimits(ctx context.Context, request RateLimitRequest) error {
+	type count struct {
+		limiter *rateLimiter
+		key     string
+		bucket  *rateLimitBucket
+	}
+	var counts []count
+	for _, limiter := range s.rateLimiters {
+		if !limiter.appliesTo(request) {
+			continue
+		}
+		key := ""
+		if limiter.limit.Key != nil {
+			key = limiter.limit.Key(ctx, request)
+		}
+		counts = append(counts, count{limiter: limiter, key: key})
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	// The limiters are locked in the order of the server, all together so
+	// that no request spends the tokens between the check and the count
+	for i := range counts {
+		counts[i].limiter.mu.Lock()
+	}
+	events := make([]RateLimitEvent, 0, len(counts))
+	var err error
+	for i, c := range counts {
+		now := c.limiter.now()
+		counts[i].bucket = c.limiter.bucket(c.key, now)
+		if retryAfter := c.limiter.retryAfter(counts[i].bucket, now); retryAfter > 0 {
+			events = append(events, RateLimitEvent{
+				Limit:      c.limiter.limit.Name,
+				Key:        c.key,
+				Request:    request,
+				RetryAfter: retryAfter,
+			})
+			err = &RateLimitError{Limit: c.limiter.limit.Name, Key: c.key, RetryAfter: retryAfter}
+			break
+		}
+	}
+	if err == nil {
+		for _, c := range counts {
+			events = append(events, RateLimitEvent{
+				Limit:     c.limiter.limit.Name,
+				Key:       c.key,
+				Request:   request,
+				Allowed:   true,
+				Remaining: c.limiter.spend(c.bucket),
+			})
+		}
+	}
+	for _, c := range counts {
+		c.limiter.mu.Unlock()
+	}
+
+	for _, event := range events {
+		s.hooks.rateLimit(ctx, event)
+	}
+	return err
+}
+
+// rateLimiter holds the state of a rate limit for each key
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rateLimitBucket
+	pruned  time.Time
+}
+
+// rateLimitBucket is the state of a key: the tokens left since last for a
+// token bucket, or the requests made in the window starting at last for a quota
+type rateLimitBucket struct {
+	tokens float64
+	count  int
+	last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+	return &rateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+func (l *rateLimiter) appliesTo(request RateLimitRequest) bool {
+	if len(l.limit.Methods) > 0 && !slices.Contains(l.limit.Methods, request.Method) {
+		return false
+	}
+	return len(l.limit.Names) == 0 || matchesAny(l.limit.Names, request.Name)
+}
+
+// bucket returns the bucket of key brought up to date, creating it if needed.
+// The limiter must be locked.
+func (l *rateLimiter) bucket(key string, now time.Time) *rateLimitBucket {
+	l.prune(now)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+	if l.limit.Quota {
+		if now.Sub(bucket.last) >= l.limit.Interval {
+			bucket.count, bucket.last = 0, now
+		}
+	} else {
+		bucket.tokens = l.refill(bucket, now)
+		bucket.last = now
+	}
+	return bucket
+}
+
+// retryAfter returns how long to wait before the bucket allows a request, zero
+// if it allows one now. The limiter must be locked.
+func (l *rateLimiter) retryAfter(bucket *rateLimitBucket, now time.Time) time.Duration {
+	if l.limit.Quota {
+		if bucket.count >= l.limit.Requests {
+			return ceilMillisecond(bucket.last.Add(l.limit.Interval).Sub(now))
+		}
+		return 0
+	}
+	if bucket.tokens < 1 {
+		return ceilMillisecond(time.Duration(math.Ceil((1 - bucket.tokens) / l.rate())))
+	}
+	return 0
+}
+
+// spend counts a request in the bucket, and returns the requests it still
+// allows right away. The limiter must be locked.
+func (l *rateLimiter) spend(bucket *rateLimitBucket) int {
+	if l.limit.Quota {
+		bucket.count++
+		return l.limit.Requests - bucket.count
+	}
+	bucket.tokens--
+	return int(bucket.tokens)
+}
+
+// ceilMillisecond rounds a retry delay up to the millisecond
+func ceilMillisecond(d time.Duration) time.Duration {
+	return (d + time.Millisecond - 1).Truncate(time.Millisecond)
+}
+
+// rate returns the tokens added to a bucket per nanosecond
+func (l *rateLimiter) rate() float64 {
+	return float64(l.limit.Requests) / float64(l.limit.Interval)
+}
+
+// refill returns the tokens of a bucket at now
+func (l *rateLimiter) refill(bucket *rateLimitBucket, now time.Time) float64 {
+	return math.Min(float64(l.limit.Burst), bucket.tokens+float64(now.Sub(bucket.last))*l.rate())
+}
+
+// prune forgets the keys back to their initial state, at most once per interval
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < l.limit.Interval {
+		return
+	}
+	l.pruned = now
+	for key, bucket := range l.buckets {
+		if l.limit.Quota && now.Sub(bucket.last) >= l.limit.Interval ||
+			!l.limit.Quota && l.refill(bucket, now) >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// newTestLimiter returns a server with the limit, and a take function
+// counting a request of key through checkRateLimits
+func newTestLimiter(limit RateLimit) (take func(key string) (allowed bool, remaining int, retryAfter time.Duration), limiter *rateLimiter, clock *testClock) {
+	clock = &testClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var event RateLimitEvent
+	hooks := &Hooks{}
+	hooks.AddOnRateLimit(func(ctx context.Context, e RateLimitEvent) {
+		event = e
+	})
+	limit.Key = func(ctx context.Context, request RateLimitRequest) string {
+		return request.Name
+	}
+	server := NewMCPServer("test", "1.0.0", WithHooks(hooks), WithRateLimit(limit))
+	limiter = server.rateLimiters[0]
+	limiter.now = clock.Now
+	take = func(key string) (bool, int, time.Duration) {
+		err := server.checkRateLimits(context.Background(), RateLimitRequest{Method: mcp.MethodToolsCall, Name: key})
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			return false, 0, rateLimitErr.RetryAfter
+		}
+		return event.Allowed, event.Remaining, event.RetryAfter
+	}
+	return take, limiter, clock
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	take, limiter, clock := newTestLimiter(RateLimit{Requests: 2, Interval: time.Second, Burst: 3})
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		allowed, left, _ := take("a")
+		require.True(t, allowed)
+		assert.Equal(t, remaining, left)
+	}
+	allowed, _, retryAfter := take("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// keys have their own bucket
+	allowed, _, _ = take("b")
+	assert.True(t, allowed)
+
+	clock.now = clock.now.Add(250 * time.Millisecond)
+	allowed, _, retryAfter = take("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+	clock.now = clock.now.Add(250 * time.Millisecond)
+	allowed, left, _ := take("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 0, left)
+
+	// full buckets are forgotten
+	clock.now = clock.now.Add(time.Hour)
+	take("c")
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestRateLimiter_Quota(t *testing.T) {
+	take, _, clock := newTestLimiter(RateLimit{Requests: 3, Interval: 24 * time.Hour, Quota: true})
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		allowed, left, _ := take("a")
+		require.True(t, allowed)
+		assert.Equal(t, remaining, left)
+		clock.now = clock.now.Add(time.Hour)
+	}
+	// unlike a token bucket, a quota is not refilled over time
+	allowed, _, retryAfter := take("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 21*time.Hour, retryAfter)
+
+	clock.now = clock.now.Add(21 * time.Hour)
+	allowed, left, _ := take("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 2, left)
+}
+
+func TestRateLimitByKeys(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0")
+	ctx := withPrincipal(server.WithContext(context.Background(), NewInProcessSession("session-1", nil)), alice)
+	key := RateLimitByKeys(RateLimitBySession, RateLimitByName, RateLimitByPrincipal)
+	assert.Equal(t, "session-1|search|alice", key(ctx, RateLimitRequest{Method: mcp.MethodToolsCall, Name: "search"}))
+	assert.Equal(t, "||", key(context.Background(), RateLimitRequest{Method: mcp.MethodSamplingCreateMessage}))
+}
+
+func TestWithRateLimit_InvalidLimit(t *testing.T) {
+	assert.PanicsWithValue(t, `rate limit "invalid": requests and interval must be positive`, func() {
+		WithRateLimit(RateLimit{Name: "invalid", Requests: 1})
+	})
+	assert.Panics(t, func() {
+		WithRateLimit(RateLimit{Name: "invalid", Interval: time.Second})
+	})
+}
+
+func TestMCPServer_RateLimitsDeniedRequestsAreNotCounted(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0",
+		WithRateLimit(RateLimit{Name: "all", Requests: 2, Interval: time.Hour, Quota: true}),
+		WithRateLimit(RateLimit{Name: "search", Names: []string{"search"}, Requests: 1, Interval: time.Hour, Quota: true}),
+	)
+	for _, name := range []string{"search", "fetch"} {
+		server.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	ctx := server.WithContext(context.Background(), NewInProcessSession("session-1", nil))
+	call := func(tool string) mcp.JSONRPCMessage {
+		return server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tool+`"}}`))
+	}
+
+	_, ok := call("search").(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	errorResponse, ok := call("search").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, errorResponse.Error.Message, "rate limit 'search' exceeded")
+	// the denied search did not spend the request left by the "all" limit
+	_, ok = call("fetch").(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+	errorResponse, ok = call("fetch").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, errorResponse.Error.Message, "rate limit 'all' exceeded")
+}
+
+type echoSamplingHandler struct{}
+
+func (echoSamplingHandler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return &mcp.CreateMessageResult{Model: "echo"}, nil
+}
+
+func TestMCPServer_RateLimits(t *testing.T) {
+	var events []RateLimitEvent
+	hooks := &Hooks{}
+	hooks.AddOnRateLimit(func(ctx context.Context, event RateLimitEvent) {
+		events = append(events, event)
+	})
+	server := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithRateLimit(RateLimit{
+			Name:     "expensive-tools",
+			Methods:  []mcp.MCPMethod{mcp.MethodToolsCall},
+			Names:    []string{"expensive-*"},
+			Key:      RateLimitByKeys(RateLimitBySession, RateLimitByName),
+			Requests: 1,
+			Interval: time.Minute,
+		}),
+		WithRateLimit(RateLimit{
+			Name:     "reads",
+			Methods:  []mcp.MCPMethod{mcp.MethodResourcesRead},
+			Requests: 1,
+			Interval: time.Hour,
+			Quota:    true,
+		}),
+		WithRateLimit(RateLimit{
+			Name:     "sampling",
+			Methods:  []mcp.MCPMethod{mcp.MethodSamplingCreateMessage},
+			Key:      RateLimitBySession,
+			Requests: 1,
+			Interval: time.Hour,
+			Quota:    true,
+		}),
+	)
+	for _, name := range []string{"expensive-search", "cheap"} {
+		server.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	server.AddResource(mcp.NewResource("file:///a.txt", "a"), textResource("a"))
+
+	session1 := NewInProcessSession("session-1", echoSamplingHandler{})
+	session2 := NewInProcessSession("session-2", echoSamplingHandler{})
+	ctx1 := server.WithContext(context.Background(), session1)
+	ctx2 := server.WithContext(context.Background(), session2)
+	call := func(ctx context.Context, tool string) mcp.JSONRPCMessage {
+		return server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tool+`"}}`))
+	}
+
+	t.Run("tools", func(t *testing.T) {
+		events = nil
+		_, ok := call(ctx1, "expensive-search").(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		errorResponse, ok := call(ctx1, "expensive-search").(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.RATE_LIMIT_EXCEEDED, errorResponse.Error.Code)
+		assert.Equal(t, "rate limit 'expensive-tools' exceeded, retry after 1m0s", errorResponse.Error.Message)
+		data, err := json.Marshal(errorResponse.Error.Data)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"limit":"expensive-tools","retryAfter":60}`, string(data))
+
+		// other sessions and tools are not limited
+		_, ok = call(ctx2, "expensive-search").(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		for range 3 {
+			_, ok = call(ctx1, "cheap").(mcp.JSONRPCResponse)
+			assert.True(t, ok)
+		}
+
+		require.Len(t, events, 3)
+		assert.Equal(t, RateLimitEvent{
+			Limit:     "expensive-tools",
+			Key:       "session-1|expensive-search",
+			Request:   RateLimitRequest{Method: mcp.MethodToolsCall, Name: "expensive-search"},
+			Allowed:   true,
+			Remaining: 0,
+		}, events[0])
+		assert.False(t, events[1].Allowed)
+		assert.Equal(t, time.Minute, events[1].RetryAfter)
+		assert.True(t, events[2].Allowed)
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		message := []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file:///a.txt"}}`)
+		_, ok := server.HandleMessage(ctx1, message).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		errorResponse, ok := server.HandleMessage(ctx2, message).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.RATE_LIMIT_EXCEEDED, errorResponse.Error.Code)
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		request := mcp.CreateMessageRequest{}
+		_, err := server.RequestSampling(ctx1, request)
+		require.NoError(t, err)
+		_, err = server.RequestSampling(ctx1, request)
+		assert.ErrorIs(t, err, ErrRateLimited)
+		_, err = server.RequestSampling(ctx2, request)
+		assert.NoError(t, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -15,16 +16,21 @@ import (
 func (s *MCPServer) HandleMessage(
 	ctx context.Context,
 	message json.RawMessage,
-) mcp.JSONRPCMessage {
+) (response mcp.JSONRPCMessage) {
 	// Add server to context
 	ctx = context.WithValue(ctx, serverKey{}, s)
-	var err *requestError
+
+	// Batches are split and each of their messages handled on its own
+	if isBatch(message) {
+		return s.handleBatch(ctx, message)
+	}
 
 	var baseMessage struct {
-		JSONRPC string        `json:"jsonrpc"`
-		Method  mcp.MCPMethod `json:"method"`
-		ID      any           `json:"id,omitempty"`
-		Result  any           `json:"result,omitempty"`
+		JSONRPC string          `json:"jsonrpc"`
+		Method  mcp.MCPMethod   `json:"method"`
+		ID      any             `json:"id,omitempty"`
+		Params  json.RawMessage `json:"params,omitempty"`
+		Result  any             `json:"result,omitempty"`
 	}
 
 	if err := json.Unmarshal(message, &baseMessage); err != nil {
@@ -53,6 +59,7 @@ func (s *MCPServer) HandleMessage(
 				"Failed to parse notification",
 			)
 		}
+		s.logNotification(ctx, notification.Method)
 		s.handleNotification(ctx, notification)
 		return nil // Return nil for notifications
 	}
@@ -72,6 +79,41 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
+	// Trace the request, as a child of the span of the client if it sent its
+	// trace context. The span ends and the request is measured and logged
+	// with the final response.
+	start := time.Now()
+	ctx, endSpan := s.startRequestSpan(ctx, baseMessage.ID, baseMessage.Method, baseMessage.Params)
+	defer func() {
+		endSpan(response)
+		s.recordRequest(ClientSessionFromContext(ctx), baseMessage.Method, baseMessage.Params, start, response)
+		s.logRequest(ctx, baseMessage.ID, baseMessage.Method, baseMessage.Params, start, response)
+	}()
+
+	// Track the request so that a notifications/cancelled from the client can
+	// abort it. Cancelled requests get no response.
+	ctx, finish := s.trackRequest(ctx, baseMessage.ID)
+	defer func() {
+		if finish() {
+			response = nil
+		}
+	}()
+
+	// Make the progress reporter of the request available to handlers
+	ctx = s.withProgressReporter(ctx, message)
+
+	return s.handleRequestWithMiddlewares(ctx, baseMessage.ID, baseMessage.Method, baseMessage.Params, message)
+}
+
+// handleRequest dispatches a request to the handler of its method
+func (s *MCPServer) handleRequest(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	message json.RawMessage,
+) mcp.JSONRPCMessage {
+	var err *requestError
+
 	// Get request header from ctx
 	h := ctx.Value(requestHeader)
 	headers, ok := h.(http.Header)
@@ -80,260 +122,338 @@ func (s *MCPServer) HandleMessage(
 		headers = make(http.Header)
 	}
 
-	switch baseMessage.Method {
+	switch method {
 	case mcp.MethodInitialize:
 		var request mcp.InitializeRequest
 		var result *mcp.InitializeResult
 		if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeInitialize(ctx, baseMessage.ID, &request)
-			result, err = s.handleInitialize(ctx, baseMessage.ID, request)
+			s.hooks.beforeInitialize(ctx, id, &request)
+			result, err = s.handleInitialize(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterInitialize(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterInitialize(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodPing:
 		var request mcp.PingRequest
 		var result *mcp.EmptyResult
 		if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforePing(ctx, baseMessage.ID, &request)
-			result, err = s.handlePing(ctx, baseMessage.ID, request)
+			s.hooks.beforePing(ctx, id, &request)
+			result, err = s.handlePing(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterPing(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterPing(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodSetLogLevel:
 		var request mcp.SetLevelRequest
 		var result *mcp.EmptyResult
 		if s.capabilities.logging == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("logging %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeSetLevel(ctx, baseMessage.ID, &request)
-			result, err = s.handleSetLevel(ctx, baseMessage.ID, request)
+			s.hooks.beforeSetLevel(ctx, id, &request)
+			result, err = s.handleSetLevel(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterSetLevel(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterSetLevel(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodResourcesList:
 		var request mcp.ListResourcesRequest
 		var result *mcp.ListResourcesResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeListResources(ctx, baseMessage.ID, &request)
-			result, err = s.handleListResources(ctx, baseMessage.ID, request)
+			s.hooks.beforeListResources(ctx, id, &request)
+			result, err = s.handleListResources(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterListResources(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListResources(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodResourcesTemplatesList:
 		var request mcp.ListResourceTemplatesRequest
 		var result *mcp.ListResourceTemplatesResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeListResourceTemplates(ctx, baseMessage.ID, &request)
-			result, err = s.handleListResourceTemplates(ctx, baseMessage.ID, request)
+			s.hooks.beforeListResourceTemplates(ctx, id, &request)
+			result, err = s.handleListResourceTemplates(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterListResourceTemplates(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListResourceTemplates(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodResourcesRead:
 		var request mcp.ReadResourceRequest
 		var result *mcp.ReadResourceResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			request.Header = headers
+			s.hooks.beforeReadResource(ctx, id, &request)
+			result, err = s.handleReadResource(ctx, id, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterReadResource(ctx, id, &request, result)
+		return createResponse(id, *result)
+	case mcp.MethodResourcesSubscribe:
+		var request mcp.SubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeReadResource(ctx, baseMessage.ID, &request)
-			result, err = s.handleReadResource(ctx, baseMessage.ID, request)
+			s.hooks.beforeSubscribe(ctx, id, &request)
+			result, err = s.handleSubscribe(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterReadResource(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterSubscribe(ctx, id, &request, result)
+		return createResponse(id, *result)
+	case mcp.MethodResourcesUnsubscribe:
+		var request mcp.UnsubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			request.Header = headers
+			s.hooks.beforeUnsubscribe(ctx, id, &request)
+			result, err = s.handleUnsubscribe(ctx, id, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterUnsubscribe(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodPromptsList:
 		var request mcp.ListPromptsRequest
 		var result *mcp.ListPromptsResult
 		if s.capabilities.prompts == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("prompts %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeListPrompts(ctx, baseMessage.ID, &request)
-			result, err = s.handleListPrompts(ctx, baseMessage.ID, request)
+			s.hooks.beforeListPrompts(ctx, id, &request)
+			result, err = s.handleListPrompts(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterListPrompts(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListPrompts(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodPromptsGet:
 		var request mcp.GetPromptRequest
 		var result *mcp.GetPromptResult
 		if s.capabilities.prompts == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("prompts %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeGetPrompt(ctx, baseMessage.ID, &request)
-			result, err = s.handleGetPrompt(ctx, baseMessage.ID, request)
+			s.hooks.beforeGetPrompt(ctx, id, &request)
+			result, err = s.handleGetPrompt(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterGetPrompt(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterGetPrompt(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodToolsList:
 		var request mcp.ListToolsRequest
 		var result *mcp.ListToolsResult
 		if s.capabilities.tools == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("tools %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeListTools(ctx, baseMessage.ID, &request)
-			result, err = s.handleListTools(ctx, baseMessage.ID, request)
+			s.hooks.beforeListTools(ctx, id, &request)
+			result, err = s.handleListTools(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterListTools(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListTools(ctx, id, &request, result)
+		return createResponse(id, *result)
 	case mcp.MethodToolsCall:
 		var request mcp.CallToolRequest
 		var result *mcp.CallToolResult
 		if s.capabilities.tools == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("tools %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
 			request.Header = headers
-			s.hooks.beforeCallTool(ctx, baseMessage.ID, &request)
-			result, err = s.handleToolCall(ctx, baseMessage.ID, request)
+			s.hooks.beforeCallTool(ctx, id, &request)
+			result, err = s.handleToolCall(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			s.hooks.onError(ctx, id, method, &request, err)
 			return err.ToJSONRPCError()
 		}
-		s.hooks.afterCallTool(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterCallTool(ctx, id, &request, result)
+		return createResponse(id, *result)
+	case mcp.MethodCompletionComplete:
+		var request mcp.CompleteRequest
+		var result *mcp.CompleteResult
+		if s.capabilities.completions == nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("completions %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			request.Header = headers
+			s.hooks.beforeComplete(ctx, id, &request)
+			result, err = s.handleComplete(ctx, id, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterComplete(ctx, id, &request, result)
+		return createResponse(id, *result)
 	default:
 		return createErrorResponse(
-			baseMessage.ID,
+			id,
 			mcp.METHOD_NOT_FOUND,
-			fmt.Sprintf("Method %s not found", baseMessage.Method),
+			fmt.Sprintf("Method %s not found", method),
 		)
 	}
 }
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rootsRefreshTimeout bounds the roots/list request sent to a client after it
+// notified the server that its roots changed.
+const rootsRefreshTimeout = 30 * time.Second
+
+// sessionRootsStore caches the roots last reported by the client of each
+// session.
+type sessionRootsStore struct {
+	mu    sync.RWMutex
+	roots map[string][]mcp.Root // sessionID -> roots
+}
+
+func newSessionRootsStore() *sessionRootsStore {
+	return &sessionRootsStore{
+		roots: make(map[string][]mcp.Root),
+	}
+}
+
+func (s *sessionRootsStore) get(sessionID string) ([]mcp.Root, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	roots, ok := s.roots[sessionID]
+	return roots, ok
+}
+
+// set stores the roots of the session, reporting whether they differ from the
+// previously stored ones.
+func (s *sessionRootsStore) set(sessionID string, roots []mcp.Root) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.roots[sessionID]
+	s.roots[sessionID] = roots
+	return !ok || !slices.Equal(previous, roots)
+}
+
+func (s *sessionRootsStore) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.roots, sessionID)
+}
+
+// RequestRoots asks the client of the current session for its list of roots.
+// The client must have declared the roots capability during initialization,
+// otherwise ErrClientCapabilityNotSupported is returned.
+// The result also refreshes the roots returned by SessionRoots.
+func (s *MCPServer) RequestRoots(ctx context.Context) (*mcp.ListRootsResult, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	return s.requestRoots(ctx, session)
+}
+
+func (s *MCPServer) requestRoots(ctx context.Context, session ClientSession) (*mcp.ListRootsResult, error) {
+	if session, ok := session.(SessionWithClientCapabilities); ok && session.GetClientCapabilities().Roots == nil {
+		return nil, fmt.Errorf("%w: roots", ErrClientCapabilityNotSupported)
+	}
+	response, err := s.sendRequestToSession(ctx, session, mcp.MethodListRoots, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.ListRootsResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal roots response: %w", err)
+	}
+
+	if s.sessionRoots.set(session.SessionID(), result.Roots) {
+		s.hooks.rootsChanged(ctx, session, result.Roots)
+	}
+	return &result, nil
+}
+
+// SessionRoots returns the roots of the client of the current session. They
+// are requested from the client on first use, then cached until the client
+// sends notifications/roots/list_changed.
+func (s *MCPServer) SessionRoots(ctx context.Context) ([]mcp.Root, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	if roots, ok := s.sessionRoots.get(session.SessionID()); ok {
+		return roots, nil
+	}
+	result, err := s.requestRoots(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return result.Roots, nil
+}
+
+// handleRootsListChanged refreshes the cached roots of the session after the
+// client reported a change. The refresh runs in the background, since the
+// client's response may be read by the same loop that delivered the
+// notification.
+func (s *MCPServer) handleRootsListChanged(ctx context.Context) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+	s.sessionRoots.delete(session.SessionID())
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rootsRefreshTimeout)
+		defer cancel()
+		if _, err := s.requestRoots(ctx, session); err != nil {
+			s.hooks.onError(ctx, nil, mcp.MethodListRoots, nil, err)
+		}
+	}()
+}
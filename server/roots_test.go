@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRequestSession is a fakeSession able to send requests to its client,
+// answered by respond.
+type fakeRequestSession struct {
+	fakeSession
+	respond func(method mcp.MCPMethod, params any) (any, error)
+
+	mu      sync.Mutex
+	methods []mcp.MCPMethod
+}
+
+func (f *fakeRequestSession) requests() []mcp.MCPMethod {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]mcp.MCPMethod(nil), f.methods...)
+}
+
+func (f *fakeRequestSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	f.mu.Lock()
+	f.methods = append(f.methods, method)
+	f.mu.Unlock()
+	result, err := f.respond(method, params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+func TestMCPServer_RequestRoots(t *testing.T) {
+	t.Run("session without request support", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		ctx := server.WithContext(context.Background(), fakeSession{sessionID: "s1"})
+
+		_, err := server.RequestRoots(ctx)
+		assert.ErrorIs(t, err, ErrSessionDoesNotSupportRequests)
+
+		_, err = server.RequestRoots(context.Background())
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("client without the roots capability", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		ctx := server.WithContext(context.Background(), NewInProcessSession("s1", nil))
+		server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"sampling":{}}}}`))
+
+		_, err := server.RequestRoots(ctx)
+		assert.ErrorIs(t, err, ErrClientCapabilityNotSupported)
+		_, err = server.SessionRoots(ctx)
+		assert.ErrorIs(t, err, ErrClientCapabilityNotSupported)
+	})
+
+	t.Run("roots are cached and refreshed on list_changed", func(t *testing.T) {
+		changes := make(chan []mcp.Root, 10)
+		hooks := &Hooks{}
+		hooks.AddOnRootsChanged(func(ctx context.Context, session ClientSession, roots []mcp.Root) {
+			changes <- roots
+		})
+		server := NewMCPServer("test-server", "1.0.0", WithHooks(hooks))
+
+		var mu sync.Mutex
+		clientRoots := []mcp.Root{{URI: "file:///workspace", Name: "workspace"}}
+		session := &fakeRequestSession{
+			fakeSession: fakeSession{sessionID: "s1", initialized: true},
+			respond: func(method mcp.MCPMethod, params any) (any, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if method != mcp.MethodListRoots {
+					return nil, fmt.Errorf("unexpected method %s", method)
+				}
+				return mcp.ListRootsResult{Roots: clientRoots}, nil
+			},
+		}
+		ctx := server.WithContext(context.Background(), session)
+
+		roots, err := server.SessionRoots(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Root{{URI: "file:///workspace", Name: "workspace"}}, roots)
+		assert.Equal(t, []mcp.Root{{URI: "file:///workspace", Name: "workspace"}}, <-changes)
+
+		// Served from the cache
+		_, err = server.SessionRoots(ctx)
+		require.NoError(t, err)
+		assert.Len(t, session.requests(), 1)
+
+		mu.Lock()
+		clientRoots = []mcp.Root{{URI: "file:///other"}}
+		mu.Unlock()
+		server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`))
+
+		select {
+		case roots := <-changes:
+			assert.Equal(t, []mcp.Root{{URI: "file:///other"}}, roots)
+		case <-time.After(time.Second):
+			t.Fatal("roots were not refreshed")
+		}
+		roots, err = server.SessionRoots(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Root{{URI: "file:///other"}}, roots)
+		assert.Len(t, session.requests(), 2)
+	})
+}
+
+func TestClientRequestTracker(t *testing.T) {
+	tracker := newClientRequestTracker()
+
+	t.Run("routes the response to the caller", func(t *testing.T) {
+		result, err := tracker.do(context.Background(), mcp.MethodListRoots, nil, func(request clientRequest) error {
+			go func() {
+				// Requests from the client are not responses
+				assert.False(t, tracker.deliver(json.RawMessage(fmt.Sprintf(
+					`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, request.ID))))
+				assert.True(t, tracker.deliver(json.RawMessage(fmt.Sprintf(
+					`{"jsonrpc":"2.0","id":%d,"result":{"roots":[]}}`, request.ID))))
+			}()
+			return nil
+		}, nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"roots":[]}`, string(result))
+	})
+
+	t.Run("maps error responses", func(t *testing.T) {
+		_, err := tracker.do(context.Background(), mcp.MethodListRoots, nil, func(request clientRequest) error {
+			go tracker.deliver(json.RawMessage(fmt.Sprintf(
+				`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"no roots"}}`, request.ID)))
+			return nil
+		}, nil)
+		assert.EqualError(t, err, "roots/list request failed: no roots")
+	})
+
+	t.Run("send failure and cancellation", func(t *testing.T) {
+		_, err := tracker.do(context.Background(), mcp.MethodListRoots, nil, func(request clientRequest) error {
+			return errors.New("broken pipe")
+		}, nil)
+		assert.ErrorContains(t, err, "broken pipe")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = tracker.do(ctx, mcp.MethodListRoots, nil, func(request clientRequest) error { return nil }, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("unknown responses are not consumed", func(t *testing.T) {
+		assert.False(t, tracker.deliver(json.RawMessage(`{"jsonrpc":"2.0","id":999,"result":{}}`)))
+	})
+}
+
+func TestStreamableHTTP_RequestRoots(t *testing.T) {
+	mcpServer := NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("list_workspace"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := ServerFromContext(ctx).RequestRoots(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Roots[0].URI), nil
+	})
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer))
+	defer server.Close()
+
+	post := func(sessionID string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{}}}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	// The roots/list request is streamed on the response of the tool call
+	resp = post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_workspace"}}`)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() map[string]any {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var message map[string]any
+				require.NoError(t, json.Unmarshal([]byte(data), &message))
+				return message
+			}
+		}
+	}
+
+	request := readEvent()
+	assert.Equal(t, "roots/list", request["method"])
+
+	answer := post(sessionID, fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":%v,"result":{"roots":[{"uri":"file:///workspace"}]}}`, request["id"]))
+	answer.Body.Close()
+	assert.Equal(t, http.StatusAccepted, answer.StatusCode)
+
+	response := readEvent()
+	assert.Equal(t, float64(2), response["id"])
+	result, _ := json.Marshal(response["result"])
+	assert.Contains(t, string(result), "file:///workspace")
+}
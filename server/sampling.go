@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,8 +22,11 @@ func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessa
 	if session == nil {
 		return nil, fmt.Errorf("no active session")
 	}
+	if err := s.checkRateLimits(ctx, RateLimitRequest{Method: mcp.MethodSamplingCreateMessage}); err != nil {
+		return nil, err
+	}
 
-	// Check if the session supports sampling requests
+	// Check if the session handles sampling requests itself
 	if samplingSession, ok := session.(SessionWithSampling); ok {
 		return samplingSession.RequestSampling(ctx, request)
 	}
@@ -32,7 +36,26 @@ func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessa
 		return handler.CreateMessage(ctx, request)
 	}
 
-	return nil, fmt.Errorf("session does not support sampling")
+	// Otherwise send the request to the client
+	if _, ok := session.(SessionWithRequests); !ok {
+		return nil, fmt.Errorf("session does not support sampling")
+	}
+	response, err := s.sendRequestToSession(ctx, session, mcp.MethodSamplingCreateMessage, request.CreateMessageParams)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.CreateMessageResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sampling response: %w", err)
+	}
+	if contentMap, ok := result.Content.(map[string]any); ok {
+		content, err := mcp.ParseContent(contentMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sampling response content: %w", err)
+		}
+		result.Content = content
+	}
+	return &result, nil
 }
 
 // SessionWithSampling extends ClientSession to support sampling requests.
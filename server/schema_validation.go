@@ -0,0 +1,404 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toJSONValue converts v to its generic JSON representation: map[string]any,
+// []any, string, float64, bool or nil. Schemas and values are compared in this
+// form, whatever Go types they were built from.
+func toJSONValue(v any) (any, error) {
+	var data []byte
+	switch v := v.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// validateSchema checks value against a JSON Schema. It supports the subset of
+// keywords commonly used to describe tool inputs and outputs: type, enum,
+// const, properties, required, additionalProperties, items, uniqueItems, the
+// length, size and range constraints, multipleOf, pattern, and the
+// allOf/anyOf/oneOf combinators. Unknown keywords are ignored.
+//
+// Errors locate the invalid value with a JSON pointer, in its URI fragment
+// form: # is the value itself, #/tags/1 the second item of its tags property.
+func validateSchema(schema, value any) error {
+	schemaValue, err := toJSONValue(schema)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	jsonValue, err := toJSONValue(value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	return validateValue(schemaValue, jsonValue, "#")
+}
+
+// pointerToken escapes a property name to be used in a JSON pointer.
+func pointerToken(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func validateValue(schema, value any, path string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		// true, or an empty schema, accept anything
+		if b, isBool := schema.(bool); isBool && !b {
+			return fmt.Errorf("%s: no value is allowed", path)
+		}
+		return nil
+	}
+
+	if t, ok := s["type"]; ok {
+		if err := validateType(t, value, path); err != nil {
+			return err
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		if !containsValue(enum, value) {
+			return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: value %v does not equal %v", path, value, c)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if err := validateObject(s, v, path); err != nil {
+			return err
+		}
+	case []any:
+		if err := validateArray(s, v, path); err != nil {
+			return err
+		}
+	case string:
+		if err := validateString(s, v, path); err != nil {
+			return err
+		}
+	case float64:
+		if err := validateNumber(s, v, path); err != nil {
+			return err
+		}
+	}
+
+	return validateCombinators(s, value, path)
+}
+
+func validateType(t, value any, path string) error {
+	var types []string
+	switch t := t.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	default:
+		return nil
+	}
+	actual := jsonType(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), actual)
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateObject(s map[string]any, value map[string]any, path string) error {
+	if minProperties, ok := s["minProperties"].(float64); ok && float64(len(value)) < minProperties {
+		return fmt.Errorf("%s: expected at least %v properties, got %d", path, minProperties, len(value))
+	}
+	if maxProperties, ok := s["maxProperties"].(float64); ok && float64(len(value)) > maxProperties {
+		return fmt.Errorf("%s: expected at most %v properties, got %d", path, maxProperties, len(value))
+	}
+	if required, ok := s["required"].([]any); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := value[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	for key, item := range value {
+		itemPath := path + "/" + pointerToken(key)
+		if propertySchema, ok := properties[key]; ok {
+			if err := validateValue(propertySchema, item, itemPath); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property %q", path, key)
+			}
+		case map[string]any:
+			if err := validateValue(additional, item, itemPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateArray(s map[string]any, value []any, path string) error {
+	if minItems, ok := s["minItems"].(float64); ok && float64(len(value)) < minItems {
+		return fmt.Errorf("%s: expected at least %v items, got %d", path, minItems, len(value))
+	}
+	if maxItems, ok := s["maxItems"].(float64); ok && float64(len(value)) > maxItems {
+		return fmt.Errorf("%s: expected at most %v items, got %d", path, maxItems, len(value))
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					return fmt.Errorf("%s: items %d and %d are equal", path, i, j)
+				}
+			}
+		}
+	}
+	if items, ok := s["items"]; ok {
+		for i, item := range value {
+			if err := validateValue(items, item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateString(s map[string]any, value string, path string) error {
+	length := float64(len([]rune(value)))
+	if minLength, ok := s["minLength"].(float64); ok && length < minLength {
+		return fmt.Errorf("%s: expected at least %v characters", path, minLength)
+	}
+	if maxLength, ok := s["maxLength"].(float64); ok && length > maxLength {
+		return fmt.Errorf("%s: expected at most %v characters", path, maxLength)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", path, pattern, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s: value %q does not match pattern %q", path, value, pattern)
+		}
+	}
+	return nil
+}
+
+// patterns caches the compiled patterns, as the same schemas are validated on
+// every call of their tool
+var patterns sync.Map
+
+// compilePattern returns the compiled regexp of a pattern
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+func validateNumber(s map[string]any, value float64, path string) error {
+	if minimum, ok := s["minimum"].(float64); ok && value < minimum {
+		return fmt.Errorf("%s: value %v is less than %v", path, value, minimum)
+	}
+	if maximum, ok := s["maximum"].(float64); ok && value > maximum {
+		return fmt.Errorf("%s: value %v is greater than %v", path, value, maximum)
+	}
+	if minimum, ok := s["exclusiveMinimum"].(float64); ok && value <= minimum {
+		return fmt.Errorf("%s: value %v must be greater than %v", path, value, minimum)
+	}
+	if maximum, ok := s["exclusiveMaximum"].(float64); ok && value >= maximum {
+		return fmt.Errorf("%s: value %v must be less than %v", path, value, maximum)
+	}
+	if multipleOf, ok := s["multipleOf"].(float64); ok && multipleOf > 0 && !isMultipleOf(value, multipleOf) {
+		return fmt.Errorf("%s: value %v is not a multiple of %v", path, value, multipleOf)
+	}
+	return nil
+}
+
+// isMultipleOf reports whether value is a multiple of multipleOf. The numbers
+// are compared as the decimals they were written as in JSON, since the binary
+// floats of decimals such as 0.29 and 0.01 do not divide exactly.
+func isMultipleOf(value, multipleOf float64) bool {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return false
+	}
+	v, _ := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	m, _ := new(big.Rat).SetString(strconv.FormatFloat(multipleOf, 'g', -1, 64))
+	return v.Quo(v, m).IsInt()
+}
+
+func validateCombinators(s map[string]any, value any, path string) error {
+	if allOf, ok := s["allOf"].([]any); ok {
+		for _, schema := range allOf {
+			if err := validateValue(schema, value, path); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		matched := false
+		for _, schema := range anyOf {
+			if validateValue(schema, value, path) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value does not match any of the allowed schemas", path)
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matches := 0
+		for _, schema := range oneOf {
+			if validateValue(schema, value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: value must match exactly one schema, matched %d", path, matches)
+		}
+	}
+	return nil
+}
+
+// validateToolOutput checks the structured content of a tool result against
+// the output schema of the tool. Error results are not checked.
+func validateToolOutput(tool mcp.Tool, result *mcp.CallToolResult) error {
+	if !tool.HasOutputSchema() || result == nil || result.IsError {
+		return nil
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("%w: structured content is missing", ErrInvalidToolOutput)
+	}
+	if err := validateSchema(tool.GetOutputSchema(), result.StructuredContent); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToolOutput, err)
+	}
+	return nil
+}
+
+// applyDefaults sets the properties of value, and of the objects nested in it,
+// that are missing but have a default in the schema.
+func applyDefaults(schema, value any) {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+	properties, _ := s["properties"].(map[string]any)
+	switch v := value.(type) {
+	case map[string]any:
+		for key, propertySchema := range properties {
+			item, present := v[key]
+			if present {
+				applyDefaults(propertySchema, item)
+				continue
+			}
+			if propertyMap, ok := propertySchema.(map[string]any); ok {
+				if defaultValue, ok := propertyMap["default"]; ok {
+					v[key] = defaultValue
+				}
+			}
+		}
+	case []any:
+		if items, ok := s["items"]; ok {
+			for _, item := range v {
+				applyDefaults(items, item)
+			}
+		}
+	}
+}
+
+// validateToolArguments checks the arguments of a tool call against the input
+// schema of the tool, once the defaults it declares are applied. It returns
+// the arguments to call the tool with, defaults included.
+func validateToolArguments(tool mcp.Tool, arguments any) (any, error) {
+	inputSchema := tool.GetInputSchema()
+	if inputSchema == nil {
+		return arguments, nil
+	}
+	schema, err := toJSONValue(inputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+
+	// clients may omit the arguments of a tool taking none
+	var value any = map[string]any{}
+	if arguments != nil {
+		if value, err = toJSONValue(arguments); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToolArguments, err)
+		}
+	}
+	applyDefaults(schema, value)
+	if err := validateValue(schema, value, "#"); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToolArguments, err)
+	}
+	return value, nil
+}
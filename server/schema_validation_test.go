@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+			"count": {"type": "integer", "minimum": 0, "maximum": 10},
+			"ratio": {"type": "number", "exclusiveMaximum": 1},
+			"price": {"type": "number", "multipleOf": 0.01},
+			"step": {"type": "number", "multipleOf": 0.1},
+			"mode": {"enum": ["fast", "slow"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"nested": {
+				"type": "object",
+				"properties": {"enabled": {"type": "boolean"}},
+				"additionalProperties": false
+			},
+			"id": {"anyOf": [{"type": "string"}, {"type": "integer"}]}
+		},
+		"required": ["name"]
+	}`)
+
+	tests := []struct {
+		name        string
+		value       any
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			value: map[string]any{
+				"name":   "deploy",
+				"count":  3,
+				"ratio":  0.5,
+				"price":  0.29,
+				"step":   0.3,
+				"mode":   "fast",
+				"tags":   []string{"a", "b"},
+				"nested": map[string]any{"enabled": true},
+				"id":     42,
+			},
+		},
+		{
+			name: "struct values are compared in JSON form",
+			value: struct {
+				Name  string `json:"name"`
+				Count int    `json:"count"`
+			}{Name: "deploy", Count: 10},
+		},
+		{
+			name:        "not an object",
+			value:       "deploy",
+			expectedErr: "#: expected object, got string",
+		},
+		{
+			name:        "missing required property",
+			value:       map[string]any{"count": 1},
+			expectedErr: `#: missing required property "name"`,
+		},
+		{
+			name:        "wrong property type",
+			value:       map[string]any{"name": "deploy", "count": 1.5},
+			expectedErr: "#/count: expected integer, got number",
+		},
+		{
+			name:        "out of range",
+			value:       map[string]any{"name": "deploy", "count": 11},
+			expectedErr: "#/count: value 11 is greater than 10",
+		},
+		{
+			name:        "exclusive maximum",
+			value:       map[string]any{"name": "deploy", "ratio": 1},
+			expectedErr: "#/ratio: value 1 must be less than 1",
+		},
+		{
+			name:  "decimal multiples",
+			value: map[string]any{"name": "deploy", "price": 19.99, "step": 1.7},
+		},
+		{
+			name:        "not a multiple",
+			value:       map[string]any{"name": "deploy", "price": 0.295},
+			expectedErr: "#/price: value 0.295 is not a multiple of 0.01",
+		},
+		{
+			name:        "pattern",
+			value:       map[string]any{"name": "Deploy"},
+			expectedErr: `#/name: value "Deploy" does not match pattern "^[a-z]+$"`,
+		},
+		{
+			name:        "enum",
+			value:       map[string]any{"name": "deploy", "mode": "medium"},
+			expectedErr: "#/mode: value medium is not one of [fast slow]",
+		},
+		{
+			name:        "array items",
+			value:       map[string]any{"name": "deploy", "tags": []any{"a", 1}},
+			expectedErr: "#/tags/1: expected string, got integer",
+		},
+		{
+			name:        "max items",
+			value:       map[string]any{"name": "deploy", "tags": []any{"a", "b", "c"}},
+			expectedErr: "#/tags: expected at most 2 items, got 3",
+		},
+		{
+			name:        "additional properties",
+			value:       map[string]any{"name": "deploy", "nested": map[string]any{"other": 1}},
+			expectedErr: `#/nested: unexpected property "other"`,
+		},
+		{
+			name:        "any of",
+			value:       map[string]any{"name": "deploy", "id": true},
+			expectedErr: "#/id: value does not match any of the allowed schemas",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchema(schema, tt.value)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	re, err := compilePattern("^[a-z]+$")
+	require.NoError(t, err)
+	cached, err := compilePattern("^[a-z]+$")
+	require.NoError(t, err)
+	assert.Same(t, re, cached)
+
+	_, err = compilePattern("[")
+	assert.Error(t, err)
+}
+
+func TestMCPServer_ToolOutputValidation(t *testing.T) {
+	tool := mcp.NewTool("weather", mcp.WithOutputSchema(mcp.ToolOutputSchema{
+		Properties: map[string]any{
+			"temperature": map[string]any{"type": "number"},
+		},
+		Required: []string{"temperature"},
+	}))
+
+	tests := []struct {
+		name        string
+		options     []ServerOption
+		result      *mcp.CallToolResult
+		expectedErr string
+	}{
+		{
+			name:    "valid structured content",
+			options: []ServerOption{WithToolOutputValidation()},
+			result:  mcp.NewToolResultStructured(map[string]any{"temperature": 21.5}),
+		},
+		{
+			name:        "invalid structured content",
+			options:     []ServerOption{WithToolOutputValidation()},
+			result:      mcp.NewToolResultStructured(map[string]any{"temperature": "warm"}),
+			expectedErr: "tool 'weather': tool output does not match its output schema: #/temperature: expected number, got string",
+		},
+		{
+			name:        "missing structured content",
+			options:     []ServerOption{WithToolOutputValidation()},
+			result:      mcp.NewToolResultText("21.5"),
+			expectedErr: "tool 'weather': tool output does not match its output schema: structured content is missing",
+		},
+		{
+			name:    "error results are not validated",
+			options: []ServerOption{WithToolOutputValidation()},
+			result:  mcp.NewToolResultError("sensor offline"),
+		},
+		{
+			name:   "validation disabled",
+			result: mcp.NewToolResultStructured(map[string]any{"temperature": "warm"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0", tt.options...)
+			server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+
+			response := server.HandleMessage(context.Background(), []byte(
+				`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"weather"}}`))
+			if tt.expectedErr != "" {
+				errorResponse, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, mcp.INTERNAL_ERROR, errorResponse.Error.Code)
+				assert.Equal(t, tt.expectedErr, errorResponse.Error.Message)
+				return
+			}
+			result, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			assert.Equal(t, *tt.result, result.Result)
+		})
+	}
+}
+
+func TestMCPServer_ToolArgumentValidation(t *testing.T) {
+	tool := mcp.NewTool("deploy",
+		mcp.WithString("service", mcp.Required(), mcp.Pattern("^[a-z-]+$")),
+		mcp.WithString("environment", mcp.Enum("staging", "production"), mcp.DefaultString("staging")),
+		mcp.WithNumber("replicas", mcp.Min(1), mcp.Max(10), mcp.MultipleOf(1), mcp.DefaultNumber(2)),
+		mcp.WithArray("regions", mcp.WithStringItems(), mcp.UniqueItems(true), mcp.DefaultArray([]string{"eu"})),
+	)
+	rawTool := mcp.NewToolWithRawSchema("raw", "", json.RawMessage(`{
+		"type": "object",
+		"properties": {"labels": {"type": "object", "additionalProperties": {"type": "string"}}}
+	}`))
+
+	tests := []struct {
+		name          string
+		tool          string
+		arguments     string
+		expectedArgs  map[string]any
+		expectedError string
+	}{
+		{
+			name:      "defaults are applied",
+			tool:      "deploy",
+			arguments: `{"service":"api"}`,
+			expectedArgs: map[string]any{
+				"service":     "api",
+				"environment": "staging",
+				"replicas":    float64(2),
+				"regions":     []any{"eu"},
+			},
+		},
+		{
+			name:          "missing required argument",
+			tool:          "deploy",
+			arguments:     `{}`,
+			expectedError: `tool 'deploy': tool arguments do not match the input schema: #: missing required property "service"`,
+		},
+		{
+			name:          "invalid enum value",
+			tool:          "deploy",
+			arguments:     `{"service":"api","environment":"dev"}`,
+			expectedError: "tool 'deploy': tool arguments do not match the input schema: #/environment: value dev is not one of [staging production]",
+		},
+		{
+			name:          "not a multiple",
+			tool:          "deploy",
+			arguments:     `{"service":"api","replicas":1.5}`,
+			expectedError: "tool 'deploy': tool arguments do not match the input schema: #/replicas: value 1.5 is not a multiple of 1",
+		},
+		{
+			name:          "duplicate items",
+			tool:          "deploy",
+			arguments:     `{"service":"api","regions":["eu","eu"]}`,
+			expectedError: "tool 'deploy': tool arguments do not match the input schema: #/regions: items 0 and 1 are equal",
+		},
+		{
+			name:          "raw schema",
+			tool:          "raw",
+			arguments:     `{"labels":{"team/owner":1}}`,
+			expectedError: "tool 'raw': tool arguments do not match the input schema: #/labels/team~1owner: expected string, got integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0", WithToolArgumentValidation())
+			var received any
+			handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				received = request.Params.Arguments
+				return mcp.NewToolResultText("ok"), nil
+			}
+			server.AddTool(tool, handler)
+			server.AddTool(rawTool, handler)
+
+			response := server.HandleMessage(context.Background(), []byte(
+				`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tt.tool+`","arguments":`+tt.arguments+`}}`))
+			if tt.expectedError != "" {
+				errorResponse, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+				assert.Equal(t, tt.expectedError, errorResponse.Error.Message)
+				assert.Nil(t, received, "the handler should not be called")
+				return
+			}
+			_, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedArgs, received)
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+		response := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"deploy"}}`))
+		_, ok := response.(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+}
@@ -2,13 +2,17 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"slices"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -21,8 +25,9 @@ type resourceEntry struct {
 
 // resourceTemplateEntry holds both a template and its handler
 type resourceTemplateEntry struct {
-	template mcp.ResourceTemplate
-	handler  ResourceTemplateHandlerFunc
+	template   mcp.ResourceTemplate
+	handler    ResourceTemplateHandlerFunc
+	completers map[string]ArgumentCompleterFunc
 }
 
 // ServerOption is a function that configures an MCPServer.
@@ -43,6 +48,21 @@ type ToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest) (*mc
 // ToolHandlerMiddleware is a middleware function that wraps a ToolHandlerFunc.
 type ToolHandlerMiddleware func(ToolHandlerFunc) ToolHandlerFunc
 
+// PromptHandlerMiddleware is a middleware function that wraps a PromptHandlerFunc.
+type PromptHandlerMiddleware func(PromptHandlerFunc) PromptHandlerFunc
+
+// ResourceHandlerMiddleware is a middleware function that wraps the handler
+// reading a resource, whether it's a static resource or a resource template.
+type ResourceHandlerMiddleware func(ResourceHandlerFunc) ResourceHandlerFunc
+
+// MethodHandlerFunc handles a request given its method and raw params, and
+// returns the response to send: a mcp.JSONRPCResponse or a mcp.JSONRPCError.
+type MethodHandlerFunc func(ctx context.Context, id any, method mcp.MCPMethod, params json.RawMessage) mcp.JSONRPCMessage
+
+// MethodHandlerMiddleware is a middleware function that wraps the handling of
+// the requests of every method.
+type MethodHandlerMiddleware func(MethodHandlerFunc) MethodHandlerFunc
+
 // ToolFilterFunc is a function that filters tools based on context, typically using session information.
 type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 
@@ -50,12 +70,17 @@ type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 type ServerTool struct {
 	Tool    mcp.Tool
 	Handler ToolHandlerFunc
+	// Timeout limits the duration of the calls to the tool, overriding the
+	// default set with WithToolTimeout.
+	Timeout time.Duration
 }
 
-// ServerPrompt combines a Prompt with its handler function.
+// ServerPrompt combines a Prompt with its handler function and optional
+// argument completers keyed by argument name.
 type ServerPrompt struct {
-	Prompt  mcp.Prompt
-	Handler PromptHandlerFunc
+	Prompt     mcp.Prompt
+	Handler    PromptHandlerFunc
+	Completers map[string]ArgumentCompleterFunc
 }
 
 // ServerResource combines a Resource with its handler function.
@@ -64,6 +89,14 @@ type ServerResource struct {
 	Handler  ResourceHandlerFunc
 }
 
+// ServerResourceTemplate combines a ResourceTemplate with its handler function
+// and optional completers for its variables keyed by variable name.
+type ServerResourceTemplate struct {
+	Template   mcp.ResourceTemplate
+	Handler    ResourceTemplateHandlerFunc
+	Completers map[string]ArgumentCompleterFunc
+}
+
 // serverKey is the context key for storing the server instance
 type serverKey struct{}
 
@@ -105,6 +138,7 @@ type requestError struct {
 	id   any
 	code int
 	err  error
+	data any
 }
 
 func (e *requestError) Error() string {
@@ -122,6 +156,7 @@ func (e *requestError) ToJSONRPCError() mcp.JSONRPCError {
 		}{
 			Code:    e.code,
 			Message: e.err.Error(),
+			Data:    e.data,
 		},
 	}
 }
@@ -130,6 +165,34 @@ func (e *requestError) Unwrap() error {
 	return e.err
 }
 
+// handlerError returns the error of a request whose handler failed with err
+func handlerError(id any, err error) *requestError {
+	var rateLimitErr *RateLimitError
+	switch {
+	case errors.Is(err, ErrToolTimeout):
+		return &requestError{
+			id:   id,
+			code: mcp.REQUEST_TIMEOUT,
+			err:  err,
+		}
+	case errors.As(err, &rateLimitErr):
+		return &requestError{
+			id:   id,
+			code: mcp.RATE_LIMIT_EXCEEDED,
+			err:  err,
+			data: mcp.RateLimitErrorData{
+				Limit:      rateLimitErr.Limit,
+				RetryAfter: rateLimitErr.RetryAfter.Seconds(),
+			},
+		}
+	}
+	return &requestError{
+		id:   id,
+		code: mcp.INTERNAL_ERROR,
+		err:  err,
+	}
+}
+
 // NotificationHandlerFunc handles incoming notifications.
 type NotificationHandlerFunc func(ctx context.Context, notification mcp.JSONRPCNotification)
 
@@ -143,22 +206,46 @@ type MCPServer struct {
 	middlewareMu           sync.RWMutex
 	notificationHandlersMu sync.RWMutex
 	capabilitiesMu         sync.RWMutex
-	toolFiltersMu          sync.RWMutex
+	filtersMu              sync.RWMutex
 
 	name                   string
+	title                  string
 	version                string
 	instructions           string
 	resources              map[string]resourceEntry
 	resourceTemplates      map[string]resourceTemplateEntry
 	prompts                map[string]mcp.Prompt
 	promptHandlers         map[string]PromptHandlerFunc
+	promptCompleters       map[string]map[string]ArgumentCompleterFunc
 	tools                  map[string]ServerTool
 	toolHandlerMiddlewares []ToolHandlerMiddleware
+	promptMiddlewares      []PromptHandlerMiddleware
+	resourceMiddlewares    []ResourceHandlerMiddleware
+	methodMiddlewares      []MethodHandlerMiddleware
 	toolFilters            []ToolFilterFunc
+	resourceFilters        []ResourceFilterFunc
+	templateFilters        []ResourceTemplateFilterFunc
+	promptFilters          []PromptFilterFunc
+	enforceFilters         bool
+	policies               []*Policy
+	rateLimiters           []*rateLimiter
 	notificationHandlers   map[string]NotificationHandlerFunc
 	capabilities           serverCapabilities
 	paginationLimit        *int
 	sessions               sync.Map
+	resourceSubscriptions  *resourceSubscriptionStore
+	inFlightRequests       *inFlightRequestStore
+	sessionRoots           *sessionRootsStore
+	progressInterval       time.Duration
+	clientRequestTimeout   time.Duration
+	toolOutputValidation   bool
+	toolArgumentValidation bool
+	toolTimeout            time.Duration
+	maxBatchSize           int
+	batchConcurrency       int
+	tracer                 Tracer
+	metrics                Metrics
+	logger                 *slog.Logger
 	hooks                  *Hooks
 }
 
@@ -171,10 +258,11 @@ func WithPaginationLimit(limit int) ServerOption {
 
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
-	tools     *toolCapabilities
-	resources *resourceCapabilities
-	prompts   *promptCapabilities
-	logging   *bool
+	tools       *toolCapabilities
+	resources   *resourceCapabilities
+	prompts     *promptCapabilities
+	logging     *bool
+	completions *bool
 }
 
 // resourceCapabilities defines the supported resource-related features
@@ -216,20 +304,60 @@ func WithToolHandlerMiddleware(
 	}
 }
 
+// WithPromptHandlerMiddleware allows adding a middleware for the
+// prompt handler call chain.
+func WithPromptHandlerMiddleware(
+	promptHandlerMiddleware PromptHandlerMiddleware,
+) ServerOption {
+	return func(s *MCPServer) {
+		s.middlewareMu.Lock()
+		s.promptMiddlewares = append(s.promptMiddlewares, promptHandlerMiddleware)
+		s.middlewareMu.Unlock()
+	}
+}
+
+// WithResourceHandlerMiddleware allows adding a middleware for the
+// resource handler call chain. It applies to resource templates too.
+func WithResourceHandlerMiddleware(
+	resourceHandlerMiddleware ResourceHandlerMiddleware,
+) ServerOption {
+	return func(s *MCPServer) {
+		s.middlewareMu.Lock()
+		s.resourceMiddlewares = append(s.resourceMiddlewares, resourceHandlerMiddleware)
+		s.middlewareMu.Unlock()
+	}
+}
+
+// WithMethodHandlerMiddleware allows adding a middleware around the handling
+// of every request, whatever its method, including the hooks. Middlewares see
+// the method and raw params of the request, and the response; they may change
+// the params before passing the request on. Notifications and batches as a
+// whole are not passed to them, but each request of a batch is.
+func WithMethodHandlerMiddleware(
+	methodHandlerMiddleware MethodHandlerMiddleware,
+) ServerOption {
+	return func(s *MCPServer) {
+		s.middlewareMu.Lock()
+		s.methodMiddlewares = append(s.methodMiddlewares, methodHandlerMiddleware)
+		s.middlewareMu.Unlock()
+	}
+}
+
 // WithToolFilter adds a filter function that will be applied to tools before they are returned in list_tools
 func WithToolFilter(
 	toolFilter ToolFilterFunc,
 ) ServerOption {
 	return func(s *MCPServer) {
-		s.toolFiltersMu.Lock()
+		s.filtersMu.Lock()
 		s.toolFilters = append(s.toolFilters, toolFilter)
-		s.toolFiltersMu.Unlock()
+		s.filtersMu.Unlock()
 	}
 }
 
-// WithRecovery adds a middleware that recovers from panics in tool handlers.
+// WithRecovery adds middlewares that recover from panics in tool, prompt and
+// resource handlers, turning them into errors.
 func WithRecovery() ServerOption {
-	return WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+	tools := WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
 		return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
 			defer func() {
 				if r := recover(); r != nil {
@@ -243,6 +371,62 @@ func WithRecovery() ServerOption {
 			return next(ctx, request)
 		}
 	})
+	prompts := WithPromptHandlerMiddleware(func(next PromptHandlerFunc) PromptHandlerFunc {
+		return func(ctx context.Context, request mcp.GetPromptRequest) (result *mcp.GetPromptResult, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf(
+						"panic recovered in %s prompt handler: %v",
+						request.Params.Name,
+						r,
+					)
+				}
+			}()
+			return next(ctx, request)
+		}
+	})
+	resources := WithResourceHandlerMiddleware(func(next ResourceHandlerFunc) ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) (contents []mcp.ResourceContents, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf(
+						"panic recovered in %s resource handler: %v",
+						request.Params.URI,
+						r,
+					)
+				}
+			}()
+			return next(ctx, request)
+		}
+	})
+	return func(s *MCPServer) {
+		tools(s)
+		prompts(s)
+		resources(s)
+	}
+}
+
+// WithToolOutputValidation makes the server check the structured content
+// returned by tools against their output schema. Tools declaring an output
+// schema must then return structured content matching it, unless the result
+// is an error; otherwise the call fails with an internal error wrapping
+// ErrInvalidToolOutput.
+func WithToolOutputValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.toolOutputValidation = true
+	}
+}
+
+// WithToolArgumentValidation makes the server check the arguments of tool
+// calls against the input schema of the tool before calling its handler,
+// raw schemas included. The defaults declared in the schema are applied to the
+// missing arguments first, so handlers receive them. Invalid arguments fail
+// the call with an invalid params error wrapping ErrInvalidToolArguments,
+// locating the invalid value with a JSON pointer.
+func WithToolArgumentValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.toolArgumentValidation = true
+	}
 }
 
 // WithHooks allows adding hooks that will be called before or after
@@ -281,6 +465,14 @@ func WithLogging() ServerOption {
 	}
 }
 
+// WithTitle sets the human-readable title of the server, returned in the
+// initialize response to clients using protocol version 2025-06-18 or later
+func WithTitle(title string) ServerOption {
+	return func(s *MCPServer) {
+		s.title = title
+	}
+}
+
 // WithInstructions sets the server instructions for the client returned in the initialize response
 func WithInstructions(instructions string) ServerOption {
 	return func(s *MCPServer) {
@@ -294,19 +486,28 @@ func NewMCPServer(
 	opts ...ServerOption,
 ) *MCPServer {
 	s := &MCPServer{
-		resources:            make(map[string]resourceEntry),
-		resourceTemplates:    make(map[string]resourceTemplateEntry),
-		prompts:              make(map[string]mcp.Prompt),
-		promptHandlers:       make(map[string]PromptHandlerFunc),
-		tools:                make(map[string]ServerTool),
-		name:                 name,
-		version:              version,
-		notificationHandlers: make(map[string]NotificationHandlerFunc),
+		resources:             make(map[string]resourceEntry),
+		resourceTemplates:     make(map[string]resourceTemplateEntry),
+		prompts:               make(map[string]mcp.Prompt),
+		promptHandlers:        make(map[string]PromptHandlerFunc),
+		promptCompleters:      make(map[string]map[string]ArgumentCompleterFunc),
+		tools:                 make(map[string]ServerTool),
+		name:                  name,
+		version:               version,
+		notificationHandlers:  make(map[string]NotificationHandlerFunc),
+		resourceSubscriptions: newResourceSubscriptionStore(),
+		inFlightRequests:      newInFlightRequestStore(),
+		sessionRoots:          newSessionRootsStore(),
+		progressInterval:      defaultProgressInterval,
+		maxBatchSize:          defaultMaxBatchSize,
+		batchConcurrency:      defaultBatchConcurrency,
+		logger:                slog.Default(),
 		capabilities: serverCapabilities{
-			tools:     nil,
-			resources: nil,
-			prompts:   nil,
-			logging:   nil,
+			tools:       nil,
+			resources:   nil,
+			prompts:     nil,
+			logging:     nil,
+			completions: nil,
 		},
 	}
 
@@ -365,17 +566,25 @@ func (s *MCPServer) RemoveResource(uri string) {
 	}
 }
 
-// AddResourceTemplate registers a new resource template and its handler
+// AddResourceTemplate registers a new resource template and its handler.
+// Completers for the template variables can be attached with WithArgumentCompleter.
 func (s *MCPServer) AddResourceTemplate(
 	template mcp.ResourceTemplate,
 	handler ResourceTemplateHandlerFunc,
+	opts ...CompletionOption,
 ) {
 	s.implicitlyRegisterResourceCapabilities()
 
+	completers := buildCompleters(opts)
+	if len(completers) > 0 {
+		s.implicitlyRegisterCompletionCapabilities()
+	}
+
 	s.resourcesMu.Lock()
 	s.resourceTemplates[template.URITemplate.Raw()] = resourceTemplateEntry{
-		template: template,
-		handler:  handler,
+		template:   template,
+		handler:    handler,
+		completers: completers,
 	}
 	s.resourcesMu.Unlock()
 
@@ -390,10 +599,22 @@ func (s *MCPServer) AddResourceTemplate(
 func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 	s.implicitlyRegisterPromptCapabilities()
 
+	for _, entry := range prompts {
+		if len(entry.Completers) > 0 {
+			s.implicitlyRegisterCompletionCapabilities()
+			break
+		}
+	}
+
 	s.promptsMu.Lock()
 	for _, entry := range prompts {
 		s.prompts[entry.Prompt.Name] = entry.Prompt
 		s.promptHandlers[entry.Prompt.Name] = entry.Handler
+		if len(entry.Completers) > 0 {
+			s.promptCompleters[entry.Prompt.Name] = entry.Completers
+		} else {
+			delete(s.promptCompleters, entry.Prompt.Name)
+		}
 	}
 	s.promptsMu.Unlock()
 
@@ -404,9 +625,10 @@ func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 	}
 }
 
-// AddPrompt registers a new prompt handler with the given name
-func (s *MCPServer) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc) {
-	s.AddPrompts(ServerPrompt{Prompt: prompt, Handler: handler})
+// AddPrompt registers a new prompt handler with the given name.
+// Completers for the prompt arguments can be attached with WithArgumentCompleter.
+func (s *MCPServer) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc, opts ...CompletionOption) {
+	s.AddPrompts(ServerPrompt{Prompt: prompt, Handler: handler, Completers: buildCompleters(opts)})
 }
 
 // DeletePrompts removes prompts from the server
@@ -417,6 +639,7 @@ func (s *MCPServer) DeletePrompts(names ...string) {
 		if _, ok := s.prompts[name]; ok {
 			delete(s.prompts, name)
 			delete(s.promptHandlers, name)
+			delete(s.promptCompleters, name)
 			exists = true
 		}
 	}
@@ -567,8 +790,13 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Logging = &struct{}{}
 	}
 
+	if s.capabilities.completions != nil && *s.capabilities.completions {
+		capabilities.Completions = &struct{}{}
+	}
+
+	protocolVersion := s.protocolVersion(request.Params.ProtocolVersion)
 	result := mcp.InitializeResult{
-		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
+		ProtocolVersion: protocolVersion,
 		ServerInfo: mcp.Implementation{
 			Name:    s.name,
 			Version: s.version,
@@ -576,6 +804,9 @@ func (s *MCPServer) handleInitialize(
 		Capabilities: capabilities,
 		Instructions: s.instructions,
 	}
+	if mcp.ProtocolVersionAtLeast(protocolVersion, mcp.ProtocolVersion20250618) {
+		result.ServerInfo.Title = s.title
+	}
 
 	if session := ClientSessionFromContext(ctx); session != nil {
 		session.Initialize()
@@ -584,6 +815,14 @@ func (s *MCPServer) handleInitialize(
 		if sessionWithClientInfo, ok := session.(SessionWithClientInfo); ok {
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 		}
+		if sessionWithCapabilities, ok := session.(SessionWithClientCapabilities); ok {
+			sessionWithCapabilities.SetClientCapabilities(request.Params.Capabilities)
+		}
+
+		// Store the negotiated protocol version if the session supports it
+		if sessionWithVersion, ok := session.(SessionWithProtocolVersion); ok {
+			sessionWithVersion.SetProtocolVersion(protocolVersion)
+		}
 	}
 	return &result, nil
 }
@@ -689,16 +928,29 @@ func (s *MCPServer) handleListResources(
 	request mcp.ListResourcesRequest,
 ) (*mcp.ListResourcesResult, *requestError) {
 	s.resourcesMu.RLock()
-	resources := make([]mcp.Resource, 0, len(s.resources))
-	for _, entry := range s.resources {
-		resources = append(resources, entry.resource)
+	resourceMap := make(map[string]mcp.Resource, len(s.resources))
+	for uri, entry := range s.resources {
+		resourceMap[uri] = entry.resource
 	}
 	s.resourcesMu.RUnlock()
 
+	// Session resources override the global resources with the same URI
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		for uri, resource := range session.GetSessionResources() {
+			resourceMap[uri] = resource.Resource
+		}
+	}
+
+	resources := make([]mcp.Resource, 0, len(resourceMap))
+	for _, resource := range resourceMap {
+		resources = append(resources, resource)
+	}
+
 	// Sort the resources by name
 	sort.Slice(resources, func(i, j int) bool {
 		return resources[i].Name < resources[j].Name
 	})
+	resources = s.filterResources(ctx, resources)
 	resourcesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
@@ -727,14 +979,27 @@ func (s *MCPServer) handleListResourceTemplates(
 	request mcp.ListResourceTemplatesRequest,
 ) (*mcp.ListResourceTemplatesResult, *requestError) {
 	s.resourcesMu.RLock()
-	templates := make([]mcp.ResourceTemplate, 0, len(s.resourceTemplates))
-	for _, entry := range s.resourceTemplates {
-		templates = append(templates, entry.template)
+	templateMap := make(map[string]mcp.ResourceTemplate, len(s.resourceTemplates))
+	for uriTemplate, entry := range s.resourceTemplates {
+		templateMap[uriTemplate] = entry.template
 	}
 	s.resourcesMu.RUnlock()
+
+	// Session templates override the global templates with the same URI template
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		for uriTemplate, template := range session.GetSessionResourceTemplates() {
+			templateMap[uriTemplate] = template.Template
+		}
+	}
+
+	templates := make([]mcp.ResourceTemplate, 0, len(templateMap))
+	for _, template := range templateMap {
+		templates = append(templates, template)
+	}
 	sort.Slice(templates, func(i, j int) bool {
 		return templates[i].Name < templates[j].Name
 	})
+	templates = s.filterResourceTemplates(ctx, templates)
 	templatesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
@@ -762,50 +1027,96 @@ func (s *MCPServer) handleReadResource(
 	id any,
 	request mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, *requestError) {
-	s.resourcesMu.RLock()
+	// Session resources and templates take precedence over the global ones
+	var sessionResources map[string]ServerResource
+	var sessionTemplates map[string]ServerResourceTemplate
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		sessionResources = session.GetSessionResources()
+		sessionTemplates = session.GetSessionResourceTemplates()
+	}
+
 	// First try direct resource handlers
-	if entry, ok := s.resources[request.Params.URI]; ok {
-		handler := entry.handler
+	var resource mcp.Resource
+	var handler ResourceHandlerFunc
+	if entry, ok := sessionResources[request.Params.URI]; ok {
+		resource, handler = entry.Resource, entry.Handler
+	} else {
+		s.resourcesMu.RLock()
+		if entry, ok := s.resources[request.Params.URI]; ok {
+			resource, handler = entry.resource, entry.handler
+		}
 		s.resourcesMu.RUnlock()
-		contents, err := handler(ctx, request)
-		if err != nil {
+	}
+	// Resources hidden by enforced filters are treated as unknown
+	if handler != nil && s.resourceAllowed(ctx, resource) {
+		if err := s.authorize(ctx, PolicyRequest{Method: mcp.MethodResourcesRead, Name: request.Params.URI}); err != nil {
 			return nil, &requestError{
 				id:   id,
-				code: mcp.INTERNAL_ERROR,
+				code: mcp.INVALID_REQUEST,
 				err:  err,
 			}
 		}
+		contents, err := s.wrapResourceHandler(handler)(ctx, request)
+		if err != nil {
+			return nil, handlerError(id, err)
+		}
 		return &mcp.ReadResourceResult{Contents: contents}, nil
 	}
 
 	// If no direct handler found, try matching against templates
 	var matchedHandler ResourceTemplateHandlerFunc
+	var matchedTemplate mcp.ResourceTemplate
 	var matched bool
-	for _, entry := range s.resourceTemplates {
-		template := entry.template
-		if matchesTemplate(request.Params.URI, template.URITemplate) {
-			matchedHandler = entry.handler
+	for _, entry := range sessionTemplates {
+		if matchesTemplate(request.Params.URI, entry.Template.URITemplate) && s.resourceTemplateAllowed(ctx, entry.Template) {
+			matchedHandler = entry.Handler
+			matchedTemplate = entry.Template
 			matched = true
-			matchedVars := template.URITemplate.Match(request.Params.URI)
-			// Convert matched variables to a map
-			request.Params.Arguments = make(map[string]any, len(matchedVars))
-			for name, value := range matchedVars {
-				request.Params.Arguments[name] = value.V
-			}
 			break
 		}
 	}
-	s.resourcesMu.RUnlock()
+	if !matched {
+		// The filters are not called with the lock held
+		var candidates []resourceTemplateEntry
+		s.resourcesMu.RLock()
+		for _, entry := range s.resourceTemplates {
+			if matchesTemplate(request.Params.URI, entry.template.URITemplate) {
+				candidates = append(candidates, entry)
+			}
+		}
+		s.resourcesMu.RUnlock()
+		for _, entry := range candidates {
+			if s.resourceTemplateAllowed(ctx, entry.template) {
+				matchedHandler = entry.handler
+				matchedTemplate = entry.template
+				matched = true
+				break
+			}
+		}
+	}
 
 	if matched {
-		contents, err := matchedHandler(ctx, request)
-		if err != nil {
-			return nil, &requestError{
-				id:   id,
-				code: mcp.INTERNAL_ERROR,
-				err:  err,
+		// Both the resource and its template must be allowed, as the
+		// policies of the template also hide it and its completions
+		for _, name := range []string{request.Params.URI, matchedTemplate.URITemplate.Raw()} {
+			if err := s.authorize(ctx, PolicyRequest{Method: mcp.MethodResourcesRead, Name: name}); err != nil {
+				return nil, &requestError{
+					id:   id,
+					code: mcp.INVALID_REQUEST,
+					err:  err,
+				}
 			}
 		}
+		matchedVars := matchedTemplate.URITemplate.Match(request.Params.URI)
+		// Convert matched variables to a map
+		request.Params.Arguments = make(map[string]any, len(matchedVars))
+		for name, value := range matchedVars {
+			request.Params.Arguments[name] = value.V
+		}
+		contents, err := s.wrapResourceHandler(ResourceHandlerFunc(matchedHandler))(ctx, request)
+		if err != nil {
+			return nil, handlerError(id, err)
+		}
 		return &mcp.ReadResourceResult{Contents: contents}, nil
 	}
 
@@ -820,6 +1131,64 @@ func (s *MCPServer) handleReadResource(
 	}
 }
 
+// wrapResourceHandler applies the resource handler middlewares to a handler
+func (s *MCPServer) wrapResourceHandler(handler ResourceHandlerFunc) ResourceHandlerFunc {
+	s.middlewareMu.RLock()
+	mw := s.resourceMiddlewares
+	s.middlewareMu.RUnlock()
+
+	handler = traceHandler(s.tracer, handler, "resource handler", nil)
+	// Apply middlewares in reverse order
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = traceHandler(s.tracer, mw[i](handler), "resource middleware", middlewareAttributes(i))
+	}
+	return handler
+}
+
+// handleRequestWithMiddlewares passes a request through the method handler
+// middlewares before dispatching it to the handler of its method.
+func (s *MCPServer) handleRequestWithMiddlewares(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	params json.RawMessage,
+	message json.RawMessage,
+) mcp.JSONRPCMessage {
+	s.middlewareMu.RLock()
+	mw := s.methodMiddlewares
+	s.middlewareMu.RUnlock()
+	if len(mw) == 0 {
+		return s.handleRequest(ctx, id, method, message)
+	}
+
+	var handler MethodHandlerFunc = func(ctx context.Context, id any, newMethod mcp.MCPMethod, newParams json.RawMessage) mcp.JSONRPCMessage {
+		// Middlewares may have changed the request, the original message is
+		// kept otherwise so that the ID is unaltered
+		if newMethod != method || !bytes.Equal(newParams, params) {
+			rebuilt := mcp.JSONRPCRequest{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				ID:      mcp.NewRequestId(id),
+				Request: mcp.Request{Method: string(newMethod)},
+			}
+			if len(newParams) > 0 {
+				rebuilt.Params = newParams
+			}
+			request, err := json.Marshal(rebuilt)
+			if err != nil {
+				return createErrorResponse(id, mcp.INTERNAL_ERROR, fmt.Sprintf("failed to marshal request: %v", err))
+			}
+			return s.handleRequest(ctx, id, newMethod, request)
+		}
+		return s.handleRequest(ctx, id, method, message)
+	}
+
+	// Apply middlewares in reverse order
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = traceMethodHandler(s.tracer, mw[i](handler), middlewareAttributes(i))
+	}
+	return handler(ctx, id, method, params)
+}
+
 // matchesTemplate checks if a URI matches a URI template pattern
 func matchesTemplate(uri string, template *mcp.URITemplate) bool {
 	return template.Regexp().MatchString(uri)
@@ -831,16 +1200,29 @@ func (s *MCPServer) handleListPrompts(
 	request mcp.ListPromptsRequest,
 ) (*mcp.ListPromptsResult, *requestError) {
 	s.promptsMu.RLock()
-	prompts := make([]mcp.Prompt, 0, len(s.prompts))
-	for _, prompt := range s.prompts {
-		prompts = append(prompts, prompt)
+	promptMap := make(map[string]mcp.Prompt, len(s.prompts))
+	for name, prompt := range s.prompts {
+		promptMap[name] = prompt
 	}
 	s.promptsMu.RUnlock()
 
+	// Session prompts override the global prompts with the same name
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithPrompts); ok {
+		for name, prompt := range session.GetSessionPrompts() {
+			promptMap[name] = prompt.Prompt
+		}
+	}
+
+	prompts := make([]mcp.Prompt, 0, len(promptMap))
+	for _, prompt := range promptMap {
+		prompts = append(prompts, prompt)
+	}
+
 	// sort prompts by name
 	sort.Slice(prompts, func(i, j int) bool {
 		return prompts[i].Name < prompts[j].Name
 	})
+	prompts = s.filterPrompts(ctx, prompts)
 	promptsToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
@@ -855,7 +1237,7 @@ func (s *MCPServer) handleListPrompts(
 		}
 	}
 	result := mcp.ListPromptsResult{
-		Prompts: promptsToReturn,
+		Prompts: promptsForProtocolVersion(ProtocolVersionFromContext(ctx), promptsToReturn),
 		PaginatedResult: mcp.PaginatedResult{
 			NextCursor: nextCursor,
 		},
@@ -868,9 +1250,27 @@ func (s *MCPServer) handleGetPrompt(
 	id any,
 	request mcp.GetPromptRequest,
 ) (*mcp.GetPromptResult, *requestError) {
-	s.promptsMu.RLock()
-	handler, ok := s.promptHandlers[request.Params.Name]
-	s.promptsMu.RUnlock()
+	var prompt mcp.Prompt
+	var handler PromptHandlerFunc
+	var ok bool
+	// Session prompts take precedence over the global ones
+	if session, isPromptSession := ClientSessionFromContext(ctx).(SessionWithPrompts); isPromptSession {
+		var entry ServerPrompt
+		if entry, ok = session.GetSessionPrompts()[request.Params.Name]; ok {
+			prompt, handler = entry.Prompt, entry.Handler
+		}
+	}
+	if !ok {
+		s.promptsMu.RLock()
+		prompt = s.prompts[request.Params.Name]
+		handler, ok = s.promptHandlers[request.Params.Name]
+		s.promptsMu.RUnlock()
+	}
+
+	// Prompts hidden by enforced filters are treated as unknown
+	if ok && !s.promptAllowed(ctx, prompt) {
+		ok = false
+	}
 
 	if !ok {
 		return nil, &requestError{
@@ -879,6 +1279,23 @@ func (s *MCPServer) handleGetPrompt(
 			err:  fmt.Errorf("prompt '%s' not found: %w", request.Params.Name, ErrPromptNotFound),
 		}
 	}
+	if err := s.authorize(ctx, PolicyRequest{Method: mcp.MethodPromptsGet, Name: request.Params.Name}); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  err,
+		}
+	}
+
+	s.middlewareMu.RLock()
+	mw := s.promptMiddlewares
+	s.middlewareMu.RUnlock()
+
+	handler = traceHandler(s.tracer, handler, "prompt handler", nil)
+	// Apply middlewares in reverse order
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = traceHandler(s.tracer, mw[i](handler), "prompt middleware", middlewareAttributes(i))
+	}
 
 	result, err := handler(ctx, request)
 	if err != nil {
@@ -950,13 +1367,7 @@ func (s *MCPServer) handleListTools(
 	}
 
 	// Apply tool filters if any are defined
-	s.toolFiltersMu.RLock()
-	if len(s.toolFilters) > 0 {
-		for _, filter := range s.toolFilters {
-			tools = filter(ctx, tools)
-		}
-	}
-	s.toolFiltersMu.RUnlock()
+	tools = s.filterTools(ctx, tools)
 
 	// Apply pagination
 	toolsToReturn, nextCursor, err := listByPagination(
@@ -974,7 +1385,7 @@ func (s *MCPServer) handleListTools(
 	}
 
 	result := mcp.ListToolsResult{
-		Tools: toolsToReturn,
+		Tools: toolsForProtocolVersion(ProtocolVersionFromContext(ctx), toolsToReturn),
 		PaginatedResult: mcp.PaginatedResult{
 			NextCursor: nextCursor,
 		},
@@ -1011,6 +1422,11 @@ func (s *MCPServer) handleToolCall(
 		s.toolsMu.RUnlock()
 	}
 
+	// Tools hidden by enforced filters are treated as unknown
+	if ok && !s.toolAllowed(ctx, tool.Tool) {
+		ok = false
+	}
+
 	if !ok {
 		return nil, &requestError{
 			id:   id,
@@ -1018,8 +1434,27 @@ func (s *MCPServer) handleToolCall(
 			err:  fmt.Errorf("tool '%s' not found: %w", request.Params.Name, ErrToolNotFound),
 		}
 	}
+	if err := s.authorize(ctx, PolicyRequest{Method: mcp.MethodToolsCall, Name: request.Params.Name, Tool: &tool.Tool}); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  err,
+		}
+	}
 
-	finalHandler := tool.Handler
+	if s.toolArgumentValidation {
+		arguments, err := validateToolArguments(tool.Tool, request.Params.Arguments)
+		if err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("tool '%s': %w", request.Params.Name, err),
+			}
+		}
+		request.Params.Arguments = arguments
+	}
+
+	finalHandler := traceHandler(s.tracer, tool.Handler, "tool handler", nil)
 
 	s.middlewareMu.RLock()
 	mw := s.toolHandlerMiddlewares
@@ -1027,25 +1462,38 @@ func (s *MCPServer) handleToolCall(
 
 	// Apply middlewares in reverse order
 	for i := len(mw) - 1; i >= 0; i-- {
-		finalHandler = mw[i](finalHandler)
+		finalHandler = traceHandler(s.tracer, mw[i](finalHandler), "tool middleware", middlewareAttributes(i))
 	}
 
-	result, err := finalHandler(ctx, request)
+	result, err := callToolWithTimeout(ctx, s.toolCallTimeout(tool, request), finalHandler, request)
 	if err != nil {
-		return nil, &requestError{
-			id:   id,
-			code: mcp.INTERNAL_ERROR,
-			err:  err,
+		return nil, handlerError(id, err)
+	}
+
+	if s.toolOutputValidation {
+		if err := validateToolOutput(tool.Tool, result); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  fmt.Errorf("tool '%s': %w", request.Params.Name, err),
+			}
 		}
 	}
 
-	return result, nil
+	return toolResultForProtocolVersion(ProtocolVersionFromContext(ctx), result), nil
 }
 
 func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
 ) mcp.JSONRPCMessage {
+	switch notification.Method {
+	case mcp.MethodNotificationCancelled:
+		s.handleCancelledNotification(ctx, notification)
+	case mcp.MethodNotificationRootsListChanged:
+		s.handleRootsListChanged(ctx)
+	}
+
 	s.notificationHandlersMu.RLock()
 	handler, ok := s.notificationHandlers[notification.Method]
 	s.notificationHandlersMu.RUnlock()
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -39,6 +41,46 @@ type SessionWithTools interface {
 	SetSessionTools(tools map[string]ServerTool)
 }
 
+// SessionWithResources is an extension of ClientSession that can store
+// session-specific resources and resource templates
+type SessionWithResources interface {
+	ClientSession
+	// GetSessionResources returns the resources specific to this session, keyed by URI
+	// This method must be thread-safe for concurrent access
+	GetSessionResources() map[string]ServerResource
+	// SetSessionResources sets resources specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionResources(resources map[string]ServerResource)
+	// GetSessionResourceTemplates returns the resource templates specific to
+	// this session, keyed by URI template
+	// This method must be thread-safe for concurrent access
+	GetSessionResourceTemplates() map[string]ServerResourceTemplate
+	// SetSessionResourceTemplates sets resource templates specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionResourceTemplates(templates map[string]ServerResourceTemplate)
+}
+
+// SessionWithPrompts is an extension of ClientSession that can store session-specific prompts
+type SessionWithPrompts interface {
+	ClientSession
+	// GetSessionPrompts returns the prompts specific to this session, keyed by name
+	// This method must be thread-safe for concurrent access
+	GetSessionPrompts() map[string]ServerPrompt
+	// SetSessionPrompts sets prompts specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionPrompts(prompts map[string]ServerPrompt)
+}
+
+// SessionWithPrincipal is an extension of ClientSession that can store the
+// principal the client was authenticated as
+type SessionWithPrincipal interface {
+	ClientSession
+	// GetPrincipal returns the principal of the client, nil if it is not authenticated
+	GetPrincipal() *Principal
+	// SetPrincipal sets the principal of the client
+	SetPrincipal(principal *Principal)
+}
+
 // SessionWithClientInfo is an extension of ClientSession that can store client info
 type SessionWithClientInfo interface {
 	ClientSession
@@ -48,6 +90,47 @@ type SessionWithClientInfo interface {
 	SetClientInfo(clientInfo mcp.Implementation)
 }
 
+// SessionWithClientCapabilities is an extension of ClientSession that can
+// store the capabilities the client declared on initialize
+type SessionWithClientCapabilities interface {
+	ClientSession
+	// GetClientCapabilities returns the capabilities of the client
+	GetClientCapabilities() mcp.ClientCapabilities
+	// SetClientCapabilities sets the capabilities of the client
+	SetClientCapabilities(capabilities mcp.ClientCapabilities)
+}
+
+// SessionWithProtocolVersion is an extension of ClientSession that can store
+// the protocol version negotiated during initialization
+type SessionWithProtocolVersion interface {
+	ClientSession
+	// GetProtocolVersion returns the negotiated protocol version, or an empty
+	// string if it is not known yet
+	GetProtocolVersion() string
+	// SetProtocolVersion sets the negotiated protocol version
+	SetProtocolVersion(version string)
+}
+
+// SessionWithValues is an extension of ClientSession that can store custom
+// values. The streamable HTTP sessions keep them in the session store, if any.
+type SessionWithValues interface {
+	ClientSession
+	// GetValue returns the value of a key
+	GetValue(key string) (string, bool)
+	// SetValue sets the value of a key
+	SetValue(key, value string)
+}
+
+// SessionWithRequests is an extension of ClientSession that can send requests
+// to its client and wait for the response
+type SessionWithRequests interface {
+	ClientSession
+	// SendRequest sends a request to the client and returns the raw result of
+	// its response. IDs are allocated by the session; it returns when the
+	// client answers or ctx is done, whichever comes first.
+	SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error)
+}
+
 // SessionWithParams is an extension of ClientSession that can store session parameters
 type SessionWithParams interface {
 	ClientSession
@@ -99,6 +182,9 @@ func (s *MCPServer) RegisterSession(
 	if _, exists := s.sessions.LoadOrStore(sessionID, session); exists {
 		return ErrSessionExists
 	}
+	if s.metrics != nil {
+		s.metrics.SessionStarted(sessionTransport(session))
+	}
 	s.hooks.RegisterSession(ctx, session)
 	return nil
 }
@@ -140,7 +226,9 @@ func (s *MCPServer) sendNotificationToAllClients(notification mcp.JSONRPCNotific
 			select {
 			case session.NotificationChannel() <- notification:
 				// Successfully sent notification
+				s.notificationQueued(session)
 			default:
+				s.notificationDropped(session)
 				// Channel is blocked, if there's an error hook, use it
 				if s.hooks != nil && len(s.hooks.OnError) > 0 {
 					err := ErrNotificationChannelBlocked
@@ -168,8 +256,10 @@ func (s *MCPServer) sendNotificationToSpecificClient(session ClientSession, noti
 	}
 	select {
 	case session.NotificationChannel() <- notification:
+		s.notificationQueued(session)
 		return nil
 	default:
+		s.notificationDropped(session)
 		// Channel is blocked, if there's an error hook, use it
 		if s.hooks != nil && len(s.hooks.OnError) > 0 {
 			err := ErrNotificationChannelBlocked
@@ -212,11 +302,17 @@ func (s *MCPServer) UnregisterSession(
 	ctx context.Context,
 	sessionID string,
 ) {
+	s.inFlightRequests.cancelSession(sessionID)
 	sessionValue, ok := s.sessions.LoadAndDelete(sessionID)
 	if !ok {
 		return
 	}
+	s.resourceSubscriptions.deleteSession(sessionID)
+	s.sessionRoots.delete(sessionID)
 	if session, ok := sessionValue.(ClientSession); ok {
+		if s.metrics != nil {
+			s.metrics.SessionEnded(sessionTransport(session))
+		}
 		s.hooks.UnregisterSession(ctx, session)
 	}
 }
@@ -250,8 +346,10 @@ func (s *MCPServer) sendNotificationCore(
 	}
 	select {
 	case session.NotificationChannel() <- notification:
+		s.notificationQueued(session)
 		return nil
 	default:
+		s.notificationDropped(session)
 		// Channel is blocked, if there's an error hook, use it
 		if s.hooks != nil && len(s.hooks.OnError) > 0 {
 			method := notification.Method
@@ -445,3 +543,271 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 
 	return nil
 }
+
+// sessionWithResources returns the session of the given ID if it supports
+// session-specific resources
+func (s *MCPServer) sessionWithResources(sessionID string) (SessionWithResources, error) {
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	session, ok := sessionValue.(SessionWithResources)
+	if !ok {
+		return nil, ErrSessionDoesNotSupportResources
+	}
+	return session, nil
+}
+
+// AddSessionResource adds a resource for a specific session
+func (s *MCPServer) AddSessionResource(sessionID string, resource mcp.Resource, handler ResourceHandlerFunc) error {
+	return s.AddSessionResources(sessionID, ServerResource{Resource: resource, Handler: handler})
+}
+
+// AddSessionResources adds resources for a specific session. They take
+// precedence over the global resources with the same URI.
+func (s *MCPServer) AddSessionResources(sessionID string, resources ...ServerResource) error {
+	session, err := s.sessionWithResources(sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.implicitlyRegisterResourceCapabilities()
+
+	sessionResources := session.GetSessionResources()
+	newSessionResources := make(map[string]ServerResource, len(sessionResources)+len(resources))
+	for k, v := range sessionResources {
+		newSessionResources[k] = v
+	}
+	for _, resource := range resources {
+		newSessionResources[resource.Resource.URI] = resource
+	}
+	session.SetSessionResources(newSessionResources)
+
+	s.notifySessionResourcesChanged(session, "adding resources")
+	return nil
+}
+
+// DeleteSessionResources removes resources from a specific session
+func (s *MCPServer) DeleteSessionResources(sessionID string, uris ...string) error {
+	session, err := s.sessionWithResources(sessionID)
+	if err != nil {
+		return err
+	}
+
+	sessionResources := session.GetSessionResources()
+	if sessionResources == nil {
+		return nil
+	}
+	newSessionResources := make(map[string]ServerResource, len(sessionResources))
+	for k, v := range sessionResources {
+		newSessionResources[k] = v
+	}
+	for _, uri := range uris {
+		delete(newSessionResources, uri)
+	}
+	session.SetSessionResources(newSessionResources)
+
+	s.notifySessionResourcesChanged(session, "deleting resources")
+	return nil
+}
+
+// AddSessionResourceTemplate adds a resource template for a specific session.
+// Completers for the template variables can be attached with WithArgumentCompleter.
+func (s *MCPServer) AddSessionResourceTemplate(
+	sessionID string,
+	template mcp.ResourceTemplate,
+	handler ResourceTemplateHandlerFunc,
+	opts ...CompletionOption,
+) error {
+	return s.AddSessionResourceTemplates(sessionID, ServerResourceTemplate{
+		Template:   template,
+		Handler:    handler,
+		Completers: buildCompleters(opts),
+	})
+}
+
+// AddSessionResourceTemplates adds resource templates for a specific session.
+// They take precedence over the global templates with the same URI template.
+func (s *MCPServer) AddSessionResourceTemplates(sessionID string, templates ...ServerResourceTemplate) error {
+	session, err := s.sessionWithResources(sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.implicitlyRegisterResourceCapabilities()
+	for _, template := range templates {
+		if len(template.Completers) > 0 {
+			s.implicitlyRegisterCompletionCapabilities()
+			break
+		}
+	}
+
+	sessionTemplates := session.GetSessionResourceTemplates()
+	newSessionTemplates := make(map[string]ServerResourceTemplate, len(sessionTemplates)+len(templates))
+	for k, v := range sessionTemplates {
+		newSessionTemplates[k] = v
+	}
+	for _, template := range templates {
+		newSessionTemplates[template.Template.URITemplate.Raw()] = template
+	}
+	session.SetSessionResourceTemplates(newSessionTemplates)
+
+	s.notifySessionResourcesChanged(session, "adding resource templates")
+	return nil
+}
+
+// DeleteSessionResourceTemplates removes resource templates, given by URI
+// template, from a specific session
+func (s *MCPServer) DeleteSessionResourceTemplates(sessionID string, uriTemplates ...string) error {
+	session, err := s.sessionWithResources(sessionID)
+	if err != nil {
+		return err
+	}
+
+	sessionTemplates := session.GetSessionResourceTemplates()
+	if sessionTemplates == nil {
+		return nil
+	}
+	newSessionTemplates := make(map[string]ServerResourceTemplate, len(sessionTemplates))
+	for k, v := range sessionTemplates {
+		newSessionTemplates[k] = v
+	}
+	for _, uriTemplate := range uriTemplates {
+		delete(newSessionTemplates, uriTemplate)
+	}
+	session.SetSessionResourceTemplates(newSessionTemplates)
+
+	s.notifySessionResourcesChanged(session, "deleting resource templates")
+	return nil
+}
+
+func (s *MCPServer) notifySessionResourcesChanged(session ClientSession, action string) {
+	// honor resources.listChanged, as for session tools
+	if session.Initialized() && s.capabilities.resources != nil && s.capabilities.resources.listChanged {
+		s.sendSessionListChanged(session.SessionID(), mcp.MethodNotificationResourcesListChanged, action)
+	}
+}
+
+// AddSessionPrompt adds a prompt for a specific session.
+// Completers for the prompt arguments can be attached with WithArgumentCompleter.
+func (s *MCPServer) AddSessionPrompt(
+	sessionID string,
+	prompt mcp.Prompt,
+	handler PromptHandlerFunc,
+	opts ...CompletionOption,
+) error {
+	return s.AddSessionPrompts(sessionID, ServerPrompt{Prompt: prompt, Handler: handler, Completers: buildCompleters(opts)})
+}
+
+// AddSessionPrompts adds prompts for a specific session. They take precedence
+// over the global prompts with the same name.
+func (s *MCPServer) AddSessionPrompts(sessionID string, prompts ...ServerPrompt) error {
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	session, ok := sessionValue.(SessionWithPrompts)
+	if !ok {
+		return ErrSessionDoesNotSupportPrompts
+	}
+
+	s.implicitlyRegisterPromptCapabilities()
+	for _, prompt := range prompts {
+		if len(prompt.Completers) > 0 {
+			s.implicitlyRegisterCompletionCapabilities()
+			break
+		}
+	}
+
+	sessionPrompts := session.GetSessionPrompts()
+	newSessionPrompts := make(map[string]ServerPrompt, len(sessionPrompts)+len(prompts))
+	for k, v := range sessionPrompts {
+		newSessionPrompts[k] = v
+	}
+	for _, prompt := range prompts {
+		newSessionPrompts[prompt.Prompt.Name] = prompt
+	}
+	session.SetSessionPrompts(newSessionPrompts)
+
+	s.notifySessionPromptsChanged(session, "adding prompts")
+	return nil
+}
+
+// DeleteSessionPrompts removes prompts from a specific session
+func (s *MCPServer) DeleteSessionPrompts(sessionID string, names ...string) error {
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	session, ok := sessionValue.(SessionWithPrompts)
+	if !ok {
+		return ErrSessionDoesNotSupportPrompts
+	}
+
+	sessionPrompts := session.GetSessionPrompts()
+	if sessionPrompts == nil {
+		return nil
+	}
+	newSessionPrompts := make(map[string]ServerPrompt, len(sessionPrompts))
+	for k, v := range sessionPrompts {
+		newSessionPrompts[k] = v
+	}
+	for _, name := range names {
+		delete(newSessionPrompts, name)
+	}
+	session.SetSessionPrompts(newSessionPrompts)
+
+	s.notifySessionPromptsChanged(session, "deleting prompts")
+	return nil
+}
+
+func (s *MCPServer) notifySessionPromptsChanged(session ClientSession, action string) {
+	// honor prompts.listChanged, as for session tools
+	if session.Initialized() && s.capabilities.prompts != nil && s.capabilities.prompts.listChanged {
+		s.sendSessionListChanged(session.SessionID(), mcp.MethodNotificationPromptsListChanged, action)
+	}
+}
+
+// sendSessionListChanged sends a list_changed notification to a single
+// session. Failures are reported to the OnError hooks, since the list was
+// changed anyway.
+func (s *MCPServer) sendSessionListChanged(sessionID string, method string, action string) {
+	err := s.SendNotificationToSpecificClient(sessionID, method, nil)
+	if err == nil || s.hooks == nil || len(s.hooks.OnError) == 0 {
+		return
+	}
+	hooks := s.hooks
+	go func() {
+		hooks.onError(context.Background(), nil, "notification", map[string]any{
+			"method":    method,
+			"sessionID": sessionID,
+		}, fmt.Errorf("failed to send notification after %s: %w", action, err))
+	}()
+}
+
+// sessionItems stores the resources, resource templates or prompts specific to
+// a session. It is safe for concurrent use.
+type sessionItems[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+// get returns a copy of the items
+func (c *sessionItems[T]) get() map[string]T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.items == nil {
+		return nil
+	}
+	items := make(map[string]T, len(c.items))
+	for k, v := range c.items {
+		items[k] = v
+	}
+	return items
+}
+
+func (c *sessionItems[T]) set(items map[string]T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = items
+}
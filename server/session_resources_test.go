@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textResource returns a handler usable for both resources and resource templates.
+func textResource(text string) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: text}}, nil
+	}
+}
+
+func textPrompt(text string) PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult(text, nil), nil
+	}
+}
+
+func expectNotification(t *testing.T, notifications <-chan mcp.JSONRPCNotification, method string) {
+	t.Helper()
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, method, notification.Method)
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Expected %s notification not received", method)
+	}
+}
+
+func TestMCPServer_SessionResources(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, true))
+	server.AddResource(mcp.NewResource("file:///shared.txt", "shared"), textResource("global"))
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///users/{id}", "users"), textResource("global user"))
+
+	session := NewInProcessSession("session-1", nil)
+	session.Initialize()
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := server.WithContext(context.Background(), session)
+
+	require.NoError(t, server.AddSessionResources(session.SessionID(),
+		ServerResource{Resource: mcp.NewResource("file:///shared.txt", "shared"), Handler: textResource("session")},
+		ServerResource{Resource: mcp.NewResource("file:///private.txt", "private"), Handler: textResource("private")},
+	))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationResourcesListChanged)
+	require.NoError(t, server.AddSessionResourceTemplate(session.SessionID(),
+		mcp.NewResourceTemplate("file:///users/{id}", "users"), textResource("session user"),
+		WithArgumentCompleter("id", func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+			return []string{"42"}, nil
+		}),
+	))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationResourcesListChanged)
+
+	read := func(ctx context.Context, uri string) string {
+		response, ok := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"`+uri+`"}}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		return response.Result.(mcp.ReadResourceResult).Contents[0].(mcp.TextResourceContents).Text
+	}
+	assert.Equal(t, "session", read(ctx, "file:///shared.txt"))
+	assert.Equal(t, "private", read(ctx, "file:///private.txt"))
+	assert.Equal(t, "session user", read(ctx, "file:///users/42"))
+	// other sessions only see the global resources
+	assert.Equal(t, "global", read(context.Background(), "file:///shared.txt"))
+	assert.Equal(t, "global user", read(context.Background(), "file:///users/42"))
+
+	response, ok := server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	resources := response.Result.(mcp.ListResourcesResult).Resources
+	require.Len(t, resources, 2)
+	assert.Equal(t, "private", resources[0].Name)
+	assert.Equal(t, "shared", resources[1].Name)
+
+	response, ok = server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":3,"method":"resources/templates/list"}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Len(t, response.Result.(mcp.ListResourceTemplatesResult).ResourceTemplates, 1)
+
+	response, ok = server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":4,"method":"completion/complete","params":{"ref":{"type":"ref/resource","uri":"file:///users/{id}"},"argument":{"name":"id","value":""}}}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, []string{"42"}, response.Result.(mcp.CompleteResult).Completion.Values)
+
+	require.NoError(t, server.DeleteSessionResources(session.SessionID(), "file:///shared.txt", "file:///private.txt"))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationResourcesListChanged)
+	require.NoError(t, server.DeleteSessionResourceTemplates(session.SessionID(), "file:///users/{id}"))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationResourcesListChanged)
+	assert.Equal(t, "global", read(ctx, "file:///shared.txt"))
+	assert.Equal(t, "global user", read(ctx, "file:///users/42"))
+}
+
+func TestMCPServer_SessionPrompts(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPromptCapabilities(true))
+	server.AddPrompt(mcp.NewPrompt("greeting"), textPrompt("global"))
+
+	session := NewInProcessSession("session-1", nil)
+	session.Initialize()
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := server.WithContext(context.Background(), session)
+
+	require.NoError(t, server.AddSessionPrompt(session.SessionID(),
+		mcp.NewPrompt("greeting", mcp.WithArgument("name")), textPrompt("session"),
+		WithArgumentCompleter("name", func(ctx context.Context, request mcp.CompleteRequest) ([]string, error) {
+			return []string{"Alice"}, nil
+		}),
+	))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationPromptsListChanged)
+	require.NoError(t, server.AddSessionPrompts(session.SessionID(),
+		ServerPrompt{Prompt: mcp.NewPrompt("farewell"), Handler: textPrompt("bye")}))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationPromptsListChanged)
+
+	get := func(ctx context.Context, name string) string {
+		response, ok := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"`+name+`"}}`)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		return response.Result.(mcp.GetPromptResult).Description
+	}
+	assert.Equal(t, "session", get(ctx, "greeting"))
+	assert.Equal(t, "bye", get(ctx, "farewell"))
+	assert.Equal(t, "global", get(context.Background(), "greeting"))
+
+	response, ok := server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	prompts := response.Result.(mcp.ListPromptsResult).Prompts
+	require.Len(t, prompts, 2)
+	assert.Equal(t, "farewell", prompts[0].Name)
+	assert.Equal(t, "greeting", prompts[1].Name)
+	assert.Len(t, prompts[1].Arguments, 1)
+
+	response, ok = server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":3,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"greeting"},"argument":{"name":"name","value":"A"}}}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, []string{"Alice"}, response.Result.(mcp.CompleteResult).Completion.Values)
+
+	require.NoError(t, server.DeleteSessionPrompts(session.SessionID(), "greeting", "farewell"))
+	expectNotification(t, session.Notifications(), mcp.MethodNotificationPromptsListChanged)
+	assert.Equal(t, "global", get(ctx, "greeting"))
+	_, ok = server.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"farewell"}}`)).(mcp.JSONRPCError)
+	assert.True(t, ok)
+}
+
+func TestMCPServer_SessionResourcesAndPromptsErrors(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &sessionTestClient{sessionID: "basic", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	err := server.AddSessionResource("unknown", mcp.NewResource("file:///a", "a"), textResource("a"))
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	err = server.AddSessionResource("basic", mcp.NewResource("file:///a", "a"), textResource("a"))
+	assert.ErrorIs(t, err, ErrSessionDoesNotSupportResources)
+	err = server.DeleteSessionResourceTemplates("basic", "file:///{a}")
+	assert.ErrorIs(t, err, ErrSessionDoesNotSupportResources)
+	err = server.AddSessionPrompt("basic", mcp.NewPrompt("a"), textPrompt("a"))
+	assert.ErrorIs(t, err, ErrSessionDoesNotSupportPrompts)
+	err = server.DeleteSessionPrompts("unknown", "a")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestStreamableHTTP_SessionPrompts(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	// the session is registered by its GET stream
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(headerKeySessionID, sessionID)
+	getResp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer getResp.Body.Close()
+	require.Eventually(t, func() bool {
+		return mcpServer.AddSessionPrompt(sessionID, mcp.NewPrompt("session-prompt"), textPrompt("session")) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the prompt is seen by the ephemeral sessions of the POST requests
+	req, err = http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerKeySessionID, sessionID)
+	resp, err = server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var response struct {
+		Result mcp.ListPromptsResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Result.Prompts, 1)
+	assert.Equal(t, "session-prompt", response.Result.Prompts[0].Name)
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SessionState is the state of a session of a StreamableHTTPServer kept in a
+// SessionStore, from which any replica of the server can handle the requests
+// of the session.
+type SessionState struct {
+	SessionID string `json:"sessionId"`
+	// Initialized reports whether the client initialized the session.
+	Initialized bool               `json:"initialized"`
+	ClientInfo  mcp.Implementation `json:"clientInfo"`
+	// ClientCapabilities are the capabilities the client declared on
+	// initialize.
+	ClientCapabilities mcp.ClientCapabilities `json:"clientCapabilities"`
+	// LogLevel is the level the client set with logging/setLevel, if any.
+	LogLevel mcp.LoggingLevel `json:"logLevel,omitempty"`
+	// ProtocolVersion is the protocol version negotiated on initialize.
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// Tools are the names of the session tools, which are turned back into
+	// tools with the resolver of WithSessionToolResolver.
+	Tools []string `json:"tools,omitempty"`
+	// PrincipalID is the ID of the principal that initialized the session.
+	PrincipalID string `json:"principalId,omitempty"`
+	// Values are the custom values of the session, see SessionWithValues.
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// Clone returns a deep copy of the state.
+func (s SessionState) Clone() SessionState {
+	s.Tools = slices.Clone(s.Tools)
+	s.Values = maps.Clone(s.Values)
+	return s
+}
+
+// SessionStore persists the state of the sessions of a StreamableHTTPServer,
+// so that the sessions survive their requests landing on another replica of
+// the server behind a load balancer. Implementations must be safe for
+// concurrent use.
+//
+// A session has a single writer at a time: a replica saves the whole state of
+// a session each time it changes it, without checking that the stored state
+// is still the one it loaded. Two replicas changing the same session at once,
+// such as one handling logging/setLevel while the other adds a session tool,
+// therefore lose the change saved first. Route the requests of a session that
+// change its state to one replica at a time, such as with session affinity,
+// if concurrent changes must not be lost.
+type SessionStore interface {
+	// Load returns the state of a session.
+	// Returns ErrSessionNotFound if the session is not stored.
+	Load(sessionID string) (SessionState, error)
+	// Save stores the state of a session, replacing the previous one: the
+	// last state saved wins.
+	Save(state SessionState) error
+	// Delete removes the state of a session. Deleting a session that is not
+	// stored is not an error.
+	Delete(sessionID string) error
+}
+
+// InMemorySessionStore is a SessionStore keeping the sessions in memory. It
+// only serves a single replica, and is mostly useful in tests.
+type InMemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]SessionState
+}
+
+var _ SessionStore = (*InMemorySessionStore)(nil)
+
+// NewInMemorySessionStore creates an empty in-memory session store.
+func NewInMemorySessionStore() *InMemorySessionStore {
+	return &InMemorySessionStore{
+		sessions: make(map[string]SessionState),
+	}
+}
+
+// Load implements SessionStore.
+func (s *InMemorySessionStore) Load(sessionID string) (SessionState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.sessions[sessionID]
+	if !ok {
+		return SessionState{}, ErrSessionNotFound
+	}
+	return state.Clone(), nil
+}
+
+// Save implements SessionStore.
+func (s *InMemorySessionStore) Save(state SessionState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[state.SessionID] = state.Clone()
+	return nil
+}
+
+// Delete implements SessionStore.
+func (s *InMemorySessionStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// FileSessionStore is a SessionStore keeping every session in a JSON file of
+// a directory. The replicas of a server sharing the directory, such as a
+// network volume, share the sessions. It is a reference for stores backed by
+// a database.
+type FileSessionStore struct {
+	dir string
+}
+
+var _ SessionStore = (*FileSessionStore)(nil)
+
+// NewFileSessionStore creates a session store keeping the sessions in dir,
+// which is created if it does not exist.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session store directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// path returns the path of the file of a session. The session ID is encoded,
+// as it comes from the client.
+func (s *FileSessionStore) path(sessionID string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(sessionID))+".json")
+}
+
+// Load implements SessionStore.
+func (s *FileSessionStore) Load(sessionID string) (SessionState, error) {
+	data, err := os.ReadFile(s.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return SessionState{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return SessionState{}, fmt.Errorf("failed to read session: %w", err)
+	}
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return SessionState{}, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	return state, nil
+}
+
+// Save implements SessionStore. The file of the session is replaced
+// atomically, so that a concurrent Load never reads a partial state.
+func (s *FileSessionStore) Save(state SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	file, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to create session file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(file.Name(), s.path(state.SessionID)); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Delete implements SessionStore.
+func (s *FileSessionStore) Delete(sessionID string) error {
+	if err := os.Remove(s.path(sessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// sessionToolNames returns the sorted names of session tools
+func sessionToolNames(tools map[string]ServerTool) []string {
+	names := slices.Collect(maps.Keys(tools))
+	slices.Sort(names)
+	return names
+}
+
+// sameToolNames reports whether tools are the tools of the names
+func sameToolNames(tools map[string]ServerTool, names []string) bool {
+	return slices.Equal(sessionToolNames(tools), names)
+}
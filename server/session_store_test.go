@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStores(t *testing.T) {
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]SessionStore{
+		"in memory": NewInMemorySessionStore(),
+		"file":      fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.Load("session/1")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+
+			state := SessionState{
+				SessionID:       "session/1",
+				Initialized:     true,
+				ClientInfo:      mcp.Implementation{Name: "client", Version: "1.0.0"},
+				LogLevel:        mcp.LoggingLevelInfo,
+				ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+				Tools:           []string{"search"},
+				PrincipalID:     "alice",
+				Values:          map[string]string{"tenant": "acme"},
+			}
+			require.NoError(t, store.Save(state))
+			state.Values["tenant"] = "changed"
+			loaded, err := store.Load("session/1")
+			require.NoError(t, err)
+			state.Values["tenant"] = "acme"
+			assert.Equal(t, state, loaded)
+
+			state.LogLevel = mcp.LoggingLevelDebug
+			require.NoError(t, store.Save(state))
+			loaded, err = store.Load("session/1")
+			require.NoError(t, err)
+			assert.Equal(t, mcp.LoggingLevelDebug, loaded.LogLevel)
+
+			require.NoError(t, store.Delete("session/1"))
+			require.NoError(t, store.Delete("session/1"))
+			_, err = store.Load("session/1")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+	}
+}
+
+// postSession posts a JSON-RPC message to the session, and returns the
+// response
+func postSession(t *testing.T, url, sessionID string, message string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(message))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(headerKeySessionID, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStreamableHTTP_SessionStore(t *testing.T) {
+	store, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+
+	reportTool := ServerTool{
+		Tool: mcp.NewTool("report"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			session := ClientSessionFromContext(ctx)
+			tenant, _ := session.(SessionWithValues).GetValue("tenant")
+			client := session.(SessionWithClientInfo).GetClientInfo()
+			level := session.(SessionWithLogging).GetLogLevel()
+			return mcp.NewToolResultText(strings.Join([]string{client.Name, tenant, string(level)}, " ")), nil
+		},
+	}
+	resolver := func(sessionID, name string) (ServerTool, bool) {
+		return reportTool, name == "report"
+	}
+
+	// replica creates a replica of the server sharing the store
+	replica := func(hooks *Hooks) *httptest.Server {
+		mcpServer := NewMCPServer("test", "1.0.0", WithHooks(hooks), WithLogging())
+		mcpServer.AddTool(mcp.NewTool("remember"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ClientSessionFromContext(ctx).(SessionWithValues).SetValue("tenant", "acme")
+			return mcp.NewToolResultText("remembered"), nil
+		})
+		server := httptest.NewServer(NewStreamableHTTPServer(mcpServer,
+			WithSessionStore(store),
+			WithSessionToolResolver(resolver),
+		))
+		t.Cleanup(server.Close)
+		return server
+	}
+	hooks := &Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		ClientSessionFromContext(ctx).(SessionWithTools).SetSessionTools(map[string]ServerTool{"report": reportTool})
+	})
+	replicaA := replica(hooks)
+	replicaB := replica(&Hooks{})
+
+	resp := postSession(t, replicaA.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test-client","version":"1.0.0"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	state, err := store.Load(sessionID)
+	require.NoError(t, err)
+	assert.True(t, state.Initialized)
+	assert.Equal(t, "test-client", state.ClientInfo.Name)
+	assert.Equal(t, "2025-06-18", state.ProtocolVersion)
+	assert.Equal(t, []string{"report"}, state.Tools)
+
+	resp = postSession(t, replicaA.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"debug"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = postSession(t, replicaA.URL, sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"remember"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	t.Run("another replica loads the session", func(t *testing.T) {
+		resp := postSession(t, replicaB.URL, sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"report"}}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var response struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Len(t, response.Result.Content, 1)
+		assert.Equal(t, "test-client acme debug", response.Result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("unknown sessions are not found", func(t *testing.T) {
+		resp := postSession(t, replicaB.URL, "unknown", `{"jsonrpc":"2.0","id":5,"method":"ping"}`)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("a session deleted on a replica is gone on the others", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, replicaB.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = store.Load(sessionID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		resp = postSession(t, replicaA.URL, sessionID, `{"jsonrpc":"2.0","id":6,"method":"ping"}`)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
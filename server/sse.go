@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -24,12 +24,18 @@ type sseSession struct {
 	done                chan struct{}
 	eventQueue          chan string // Channel for queuing events
 	sessionID           string
-	requestID           atomic.Int64
+	requests            *clientRequestTracker // tracks requests sent to the client
 	notificationChannel chan mcp.JSONRPCNotification
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
-	tools               sync.Map     // stores session-specific tools
-	clientInfo          atomic.Value // stores session-specific client info
+	tools               sync.Map                             // stores session-specific tools
+	resources           sessionItems[ServerResource]         // stores session-specific resources
+	resourceTemplates   sessionItems[ServerResourceTemplate] // stores session-specific resource templates
+	prompts             sessionItems[ServerPrompt]           // stores session-specific prompts
+	clientInfo          atomic.Value                         // stores session-specific client info
+	clientCapabilities  atomic.Value                         // stores the capabilities of the client
+	principal           atomic.Pointer[Principal]            // stores the authenticated principal
+	protocolVersion     atomic.Value                         // stores the negotiated protocol version
 	params              map[string]string
 }
 
@@ -113,11 +119,90 @@ func (s *sseSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *sseSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if capabilities, ok := s.clientCapabilities.Load().(mcp.ClientCapabilities); ok {
+		return capabilities
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *sseSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(capabilities)
+}
+
+func (s *sseSession) GetPrincipal() *Principal {
+	return s.principal.Load()
+}
+
+func (s *sseSession) SetPrincipal(principal *Principal) {
+	s.principal.Store(principal)
+}
+
+func (s *sseSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *sseSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+// SendRequest queues a request to the client on the SSE stream and waits for
+// the response, which the client posts to the message endpoint.
+func (s *sseSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	return s.requests.do(ctx, method, params, func(request clientRequest) error {
+		eventData, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		select {
+		case s.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
+			return nil
+		case <-s.done:
+			return ErrSessionNotFound
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, s.notificationChannel)
+}
+
+func (s *sseSession) GetSessionResources() map[string]ServerResource {
+	return s.resources.get()
+}
+
+func (s *sseSession) SetSessionResources(resources map[string]ServerResource) {
+	s.resources.set(resources)
+}
+
+func (s *sseSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.resourceTemplates.get()
+}
+
+func (s *sseSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.resourceTemplates.set(templates)
+}
+
+func (s *sseSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.prompts.get()
+}
+
+func (s *sseSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.prompts.set(prompts)
+}
+
 var (
-	_ ClientSession         = (*sseSession)(nil)
-	_ SessionWithTools      = (*sseSession)(nil)
-	_ SessionWithLogging    = (*sseSession)(nil)
-	_ SessionWithClientInfo = (*sseSession)(nil)
+	_ ClientSession                 = (*sseSession)(nil)
+	_ SessionWithTools              = (*sseSession)(nil)
+	_ SessionWithLogging            = (*sseSession)(nil)
+	_ SessionWithClientInfo         = (*sseSession)(nil)
+	_ SessionWithClientCapabilities = (*sseSession)(nil)
+	_ SessionWithProtocolVersion    = (*sseSession)(nil)
+	_ SessionWithRequests           = (*sseSession)(nil)
+	_ SessionWithResources          = (*sseSession)(nil)
+	_ SessionWithPrompts            = (*sseSession)(nil)
+	_ SessionWithPrincipal          = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -134,6 +219,9 @@ type SSEServer struct {
 	srv                          *http.Server
 	contextFunc                  SSEContextFunc
 	dynamicBasePathFunc          DynamicBasePathFunc
+	protectedResource            *ProtectedResource
+	authenticator                Authenticator
+	logger                       *slog.Logger
 
 	keepAlive         bool
 	keepAliveInterval time.Duration
@@ -262,6 +350,32 @@ func WithSSEContextFunc(fn SSEContextFunc) SSEOption {
 	}
 }
 
+// WithSSEProtectedResource makes the server an OAuth 2.1 protected resource:
+// it serves the protected resource metadata, and requires a valid bearer
+// token on the SSE and message endpoints.
+func WithSSEProtectedResource(protectedResource *ProtectedResource) SSEOption {
+	return func(s *SSEServer) {
+		s.protectedResource = protectedResource
+	}
+}
+
+// WithSSEAuthenticator sets the authenticator of the clients. A client that
+// fails to authenticate is rejected, and the requests of a session must be
+// made by the principal that opened it.
+func WithSSEAuthenticator(authenticator Authenticator) SSEOption {
+	return func(s *SSEServer) {
+		s.authenticator = authenticator
+	}
+}
+
+// WithSSELogger sets the logger of the server, by default the logger of the
+// MCPServer.
+func WithSSELogger(logger *slog.Logger) SSEOption {
+	return func(s *SSEServer) {
+		s.logger = logger
+	}
+}
+
 // NewSSEServer creates a new SSE server instance with the given MCP server and options.
 func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 	s := &SSEServer{
@@ -271,6 +385,7 @@ func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 		useFullURLForMessageEndpoint: true,
 		keepAlive:                    false,
 		keepAliveInterval:            10 * time.Second,
+		logger:                       server.logger,
 	}
 
 	// Apply all options
@@ -341,6 +456,19 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.protectedResource != nil {
+		var ok bool
+		if r, ok = s.protectedResource.authorize(w, r); !ok {
+			return
+		}
+	}
+	if s.authenticator != nil {
+		var ok bool
+		if r, ok = authenticateHTTP(s.authenticator, s.protectedResource, w, r); !ok {
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -362,9 +490,11 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		done:                make(chan struct{}),
 		eventQueue:          make(chan string, 100), // Buffer for events
 		sessionID:           sessionID,
+		requests:            newClientRequestTracker(),
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
 		params:              params,
 	}
+	session.SetPrincipal(PrincipalFromContext(r.Context()))
 
 	s.sessions.Store(sessionID, session)
 	defer s.sessions.Delete(sessionID)
@@ -378,6 +508,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer s.server.UnregisterSession(r.Context(), sessionID)
+	defer session.requests.abort(ErrSessionNotFound)
 
 	// Start notification handler for this session
 	go func() {
@@ -411,7 +542,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 				case <-ticker.C:
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(session.requestID.Add(1)),
+						ID:      mcp.NewRequestId(session.requests.nextID()),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -482,6 +613,19 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.protectedResource != nil {
+		var ok bool
+		if r, ok = s.protectedResource.authorize(w, r); !ok {
+			return
+		}
+	}
+	if s.authenticator != nil {
+		var ok bool
+		if r, ok = authenticateHTTP(s.authenticator, s.protectedResource, w, r); !ok {
+			return
+		}
+	}
+
 	sessionID := r.URL.Query().Get("sessionId")
 	if sessionID == "" {
 		s.writeJSONRPCError(w, nil, mcp.INVALID_PARAMS, "Missing sessionId")
@@ -493,6 +637,15 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	session := sessionI.(*sseSession)
+	if !samePrincipal(session.GetPrincipal(), PrincipalFromContext(r.Context())) {
+		http.Error(w, "Forbidden: session belongs to another principal", http.StatusForbidden)
+		return
+	}
+
+	if err := validateProtocolVersionHeader(r.Header.Get(headerKeyProtocolVersion)); err != nil {
+		s.writeJSONRPCError(w, nil, mcp.INVALID_REQUEST, err.Error())
+		return
+	}
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
@@ -507,8 +660,16 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Responses to requests sent to the client are routed to the waiting caller
+	if session.requests.deliver(rawMessage) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// Create a context that preserves all values from parent ctx but won't be canceled when the parent is canceled.
-	// this is required because the http ctx will be canceled when the client disconnects
+	// this is required because the http ctx will be canceled as soon as the 202 is written.
+	// The message is still cancelled by a notifications/cancelled from the client, or when
+	// the SSE connection closes and the session is unregistered.
 	detachedCtx := context.WithoutCancel(ctx)
 
 	// quick return request, send 202 Accepted with no body, then deal the message and sent response via SSE
@@ -528,7 +689,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 			var message string
 			if eventData, err := json.Marshal(response); err != nil {
 				// If there is an error marshalling the response, send a generic error response
-				log.Printf("failed to marshal response: %v", err)
+				s.logger.Error("failed to marshal response", "sessionID", sessionID, "error", err)
 				message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
 			} else {
 				message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
@@ -542,7 +703,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 				// Session is closed, don't try to queue
 			default:
 				// Queue is full, log this situation
-				log.Printf("Event queue full for session %s", sessionID)
+				s.logger.Warn("event queue full, response dropped", "sessionID", sessionID)
 			}
 		}
 	}(messageCtx)
@@ -714,6 +875,10 @@ func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	path := r.URL.Path
+	if s.protectedResource != nil && path == s.protectedResource.MetadataPath() {
+		s.protectedResource.MetadataHandler().ServeHTTP(w, r)
+		return
+	}
 	// Use exact path matching rather than Contains
 	ssePath := s.CompleteSsePath()
 	if ssePath != "" && path == ssePath {
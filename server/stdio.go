@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"sync"
@@ -14,6 +16,7 @@ import (
 	"syscall"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // StdioContextFunc is a function that takes an existing context and returns
@@ -26,18 +29,29 @@ type StdioContextFunc func(ctx context.Context) context.Context
 // It provides a simple way to create command-line MCP servers that
 // communicate via standard input/output streams using JSON-RPC messages.
 type StdioServer struct {
-	server      *MCPServer
-	errLogger   *log.Logger
-	contextFunc StdioContextFunc
+	server        *MCPServer
+	logger        *slog.Logger
+	contextFunc   StdioContextFunc
+	authenticator Authenticator
 }
 
 // StdioOption defines a function type for configuring StdioServer
 type StdioOption func(*StdioServer)
 
+// WithStdioLogger sets the logger of the server, by default the logger of
+// the MCPServer.
+func WithStdioLogger(logger *slog.Logger) StdioOption {
+	return func(s *StdioServer) {
+		s.logger = logger
+	}
+}
+
 // WithErrorLogger sets the error logger for the server
+//
+// Deprecated: use WithStdioLogger.
 func WithErrorLogger(logger *log.Logger) StdioOption {
 	return func(s *StdioServer) {
-		s.errLogger = logger
+		s.SetErrorLogger(logger)
 	}
 }
 
@@ -50,23 +64,30 @@ func WithStdioContextFunc(fn StdioContextFunc) StdioOption {
 	}
 }
 
-// stdioSession is a static client session, since stdio has only one client.
-type stdioSession struct {
-	notifications   chan mcp.JSONRPCNotification
-	initialized     atomic.Bool
-	loggingLevel    atomic.Value
-	clientInfo      atomic.Value                     // stores session-specific client info
-	writer          io.Writer                        // for sending requests to client
-	requestID       atomic.Int64                     // for generating unique request IDs
-	mu              sync.RWMutex                     // protects writer
-	pendingRequests map[int64]chan *samplingResponse // for tracking pending sampling requests
-	pendingMu       sync.RWMutex                     // protects pendingRequests
+// WithStdioAuthenticator sets the authenticator of the client, typically an
+// EnvAuthenticator or a PeerCredentialsAuthenticator. Listen fails if the
+// client cannot be authenticated.
+func WithStdioAuthenticator(authenticator Authenticator) StdioOption {
+	return func(s *StdioServer) {
+		s.authenticator = authenticator
+	}
 }
 
-// samplingResponse represents a response to a sampling request
-type samplingResponse struct {
-	result *mcp.CreateMessageResult
-	err    error
+// stdioSession is a static client session, since stdio has only one client.
+type stdioSession struct {
+	notifications      chan mcp.JSONRPCNotification
+	initialized        atomic.Bool
+	loggingLevel       atomic.Value
+	clientInfo         atomic.Value                         // stores session-specific client info
+	clientCapabilities atomic.Value                         // stores the capabilities of the client
+	principal          atomic.Pointer[Principal]            // stores the authenticated principal
+	protocolVersion    atomic.Value                         // stores the negotiated protocol version
+	resources          sessionItems[ServerResource]         // stores session-specific resources
+	resourceTemplates  sessionItems[ServerResourceTemplate] // stores session-specific resource templates
+	prompts            sessionItems[ServerPrompt]           // stores session-specific prompts
+	writer             io.Writer                            // for sending requests to client
+	mu                 sync.RWMutex                         // protects writer
+	requests           *clientRequestTracker                // tracks requests sent to the client
 }
 
 func (s *stdioSession) SessionID() string {
@@ -100,6 +121,28 @@ func (s *stdioSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *stdioSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if capabilities, ok := s.clientCapabilities.Load().(mcp.ClientCapabilities); ok {
+		return capabilities
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *stdioSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(capabilities)
+}
+
+func (s *stdioSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *stdioSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -112,8 +155,8 @@ func (s *stdioSession) GetLogLevel() mcp.LoggingLevel {
 	return level.(mcp.LoggingLevel)
 }
 
-// RequestSampling sends a sampling request to the client and waits for the response.
-func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+// SendRequest writes a request to the client and waits for the response.
+func (s *stdioSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
 	s.mu.RLock()
 	writer := s.writer
 	s.mu.RUnlock()
@@ -122,57 +165,15 @@ func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMe
 		return nil, fmt.Errorf("no writer available for sending requests")
 	}
 
-	// Generate a unique request ID
-	id := s.requestID.Add(1)
-
-	// Create a response channel for this request
-	responseChan := make(chan *samplingResponse, 1)
-	s.pendingMu.Lock()
-	s.pendingRequests[id] = responseChan
-	s.pendingMu.Unlock()
-
-	// Cleanup function to remove the pending request
-	cleanup := func() {
-		s.pendingMu.Lock()
-		delete(s.pendingRequests, id)
-		s.pendingMu.Unlock()
-	}
-	defer cleanup()
-
-	// Create the JSON-RPC request
-	jsonRPCRequest := struct {
-		JSONRPC string                  `json:"jsonrpc"`
-		ID      int64                   `json:"id"`
-		Method  string                  `json:"method"`
-		Params  mcp.CreateMessageParams `json:"params"`
-	}{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      id,
-		Method:  string(mcp.MethodSamplingCreateMessage),
-		Params:  request.CreateMessageParams,
-	}
-
-	// Marshal and send the request
-	requestBytes, err := json.Marshal(jsonRPCRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal sampling request: %w", err)
-	}
-	requestBytes = append(requestBytes, '\n')
-
-	if _, err := writer.Write(requestBytes); err != nil {
-		return nil, fmt.Errorf("failed to write sampling request: %w", err)
-	}
-
-	// Wait for the response or context cancellation
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
+	return s.requests.do(ctx, method, params, func(request clientRequest) error {
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return err
 		}
-		return response.result, nil
-	}
+		requestBytes = append(requestBytes, '\n')
+		_, err = writer.Write(requestBytes)
+		return err
+	}, s.notifications)
 }
 
 // SetWriter sets the writer for sending requests to the client.
@@ -182,35 +183,75 @@ func (s *stdioSession) SetWriter(writer io.Writer) {
 	s.writer = writer
 }
 
+func (s *stdioSession) GetSessionResources() map[string]ServerResource {
+	return s.resources.get()
+}
+
+func (s *stdioSession) SetSessionResources(resources map[string]ServerResource) {
+	s.resources.set(resources)
+}
+
+func (s *stdioSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.resourceTemplates.get()
+}
+
+func (s *stdioSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.resourceTemplates.set(templates)
+}
+
+func (s *stdioSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.prompts.get()
+}
+
+func (s *stdioSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.prompts.set(prompts)
+}
+
+func (s *stdioSession) GetPrincipal() *Principal {
+	return s.principal.Load()
+}
+
+func (s *stdioSession) SetPrincipal(principal *Principal) {
+	s.principal.Store(principal)
+}
+
 var (
-	_ ClientSession         = (*stdioSession)(nil)
-	_ SessionWithLogging    = (*stdioSession)(nil)
-	_ SessionWithClientInfo = (*stdioSession)(nil)
-	_ SessionWithSampling   = (*stdioSession)(nil)
+	_ ClientSession                 = (*stdioSession)(nil)
+	_ SessionWithLogging            = (*stdioSession)(nil)
+	_ SessionWithClientInfo         = (*stdioSession)(nil)
+	_ SessionWithClientCapabilities = (*stdioSession)(nil)
+	_ SessionWithProtocolVersion    = (*stdioSession)(nil)
+	_ SessionWithRequests           = (*stdioSession)(nil)
+	_ SessionWithResources          = (*stdioSession)(nil)
+	_ SessionWithPrompts            = (*stdioSession)(nil)
+	_ SessionWithPrincipal          = (*stdioSession)(nil)
 )
 
 var stdioSessionInstance = stdioSession{
-	notifications:   make(chan mcp.JSONRPCNotification, 100),
-	pendingRequests: make(map[int64]chan *samplingResponse),
+	notifications: make(chan mcp.JSONRPCNotification, 100),
+	requests:      newClientRequestTracker(),
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
-// It initializes the server with a default error logger that discards all output.
+// It logs with the logger of the MCPServer, which must not write to stdout.
 func NewStdioServer(server *MCPServer) *StdioServer {
 	return &StdioServer{
 		server: server,
-		errLogger: log.New(
-			os.Stderr,
-			"",
-			log.LstdFlags,
-		), // Default to discarding logs
+		logger: server.logger,
 	}
 }
 
 // SetErrorLogger configures where error messages from the StdioServer are logged.
 // The provided logger will receive all error messages generated during server operation.
+//
+// Deprecated: use SetLogger.
 func (s *StdioServer) SetErrorLogger(logger *log.Logger) {
-	s.errLogger = logger
+	s.logger = util.ToSlog(util.NewStdLogger(logger))
+}
+
+// SetLogger sets the logger of the server.
+func (s *StdioServer) SetLogger(logger *slog.Logger) {
+	s.logger = logger
 }
 
 // SetContextFunc sets a function that will be called to customise the context
@@ -228,7 +269,7 @@ func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer)
 		select {
 		case notification := <-stdioSessionInstance.notifications:
 			if err := s.writeResponse(notification, stdout); err != nil {
-				s.errLogger.Printf("Error writing notification: %v", err)
+				s.logger.Error("failed to write notification", "method", notification.Method, "error", err)
 			}
 		case <-ctx.Done():
 			return
@@ -253,7 +294,7 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error reading input: %v", err)
+			s.logger.Error("failed to read input", "error", err)
 			return err
 		}
 
@@ -261,7 +302,7 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error handling message: %v", err)
+			s.logger.Error("failed to handle message", "error", err)
 			return err
 		}
 	}
@@ -301,6 +342,21 @@ func (s *StdioServer) Listen(
 	stdin io.Reader,
 	stdout io.Writer,
 ) error {
+	// Authenticate the client before registering its session, so that hooks see the principal
+	var principal *Principal
+	if s.authenticator != nil {
+		request := &AuthRequest{}
+		if conn, ok := stdin.(net.Conn); ok {
+			request.Conn = conn
+		}
+		var err error
+		if principal, err = s.authenticator.Authenticate(ctx, request); err != nil {
+			return fmt.Errorf("authenticate client: %w", err)
+		}
+		ctx = withPrincipal(ctx, principal)
+	}
+	stdioSessionInstance.SetPrincipal(principal)
+
 	// Set a static client context since stdio only has one client
 	if err := s.server.RegisterSession(ctx, &stdioSessionInstance); err != nil {
 		return fmt.Errorf("register session: %w", err)
@@ -343,29 +399,31 @@ func (s *StdioServer) processMessage(
 		return s.writeResponse(response, writer)
 	}
 
-	// Check if this is a response to a sampling request
-	if s.handleSamplingResponse(rawMessage) {
+	// Check if this is a response to a request sent to the client
+	if s.handleClientResponse(rawMessage) {
 		return nil
 	}
 
-	// Check if this is a tool call that might need sampling (and thus should be processed concurrently)
+	// Process requests, and batches which may contain requests, concurrently:
+	// the loop must keep reading while they run, to deliver the responses to
+	// the requests their handlers send to the client, and the
+	// notifications/cancelled cancelling them.
 	var baseMessage struct {
-		Method string `json:"method"`
+		ID json.RawMessage `json:"id"`
 	}
-	if json.Unmarshal(rawMessage, &baseMessage) == nil && baseMessage.Method == "tools/call" {
-		// Process tool calls concurrently to avoid blocking on sampling requests
+	if isBatch(rawMessage) || (json.Unmarshal(rawMessage, &baseMessage) == nil && baseMessage.ID != nil) {
 		go func() {
 			response := s.server.HandleMessage(ctx, rawMessage)
 			if response != nil {
 				if err := s.writeResponse(response, writer); err != nil {
-					s.errLogger.Printf("Error writing tool response: %v", err)
+					s.logger.Error("failed to write response", "error", err)
 				}
 			}
 		}()
 		return nil
 	}
 
-	// Handle other messages synchronously
+	// Handle notifications synchronously, in order
 	response := s.server.HandleMessage(ctx, rawMessage)
 
 	// Only write response if there is one (not for notifications)
@@ -378,63 +436,10 @@ func (s *StdioServer) processMessage(
 	return nil
 }
 
-// handleSamplingResponse checks if the message is a response to a sampling request
-// and routes it to the appropriate pending request channel.
-func (s *StdioServer) handleSamplingResponse(rawMessage json.RawMessage) bool {
-	return stdioSessionInstance.handleSamplingResponse(rawMessage)
-}
-
-// handleSamplingResponse handles incoming sampling responses for this session
-func (s *stdioSession) handleSamplingResponse(rawMessage json.RawMessage) bool {
-	// Try to parse as a JSON-RPC response
-	var response struct {
-		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
-		Result  json.RawMessage `json:"result,omitempty"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	if err := json.Unmarshal(rawMessage, &response); err != nil {
-		return false
-	}
-	// Parse the ID as int64
-	idInt64, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
-		return false
-	}
-
-	// Look for a pending request with this ID
-	s.pendingMu.RLock()
-	responseChan, exists := s.pendingRequests[idInt64]
-	s.pendingMu.RUnlock()
-
-	if !exists {
-		return false
-	} // Parse and send the response
-	samplingResp := &samplingResponse{}
-
-	if response.Error != nil {
-		samplingResp.err = fmt.Errorf("sampling request failed: %s", response.Error.Message)
-	} else {
-		var result mcp.CreateMessageResult
-		if err := json.Unmarshal(response.Result, &result); err != nil {
-			samplingResp.err = fmt.Errorf("failed to unmarshal sampling response: %w", err)
-		} else {
-			samplingResp.result = &result
-		}
-	}
-
-	// Send the response (non-blocking)
-	select {
-	case responseChan <- samplingResp:
-	default:
-		// Channel is full or closed, ignore
-	}
-
-	return true
+// handleClientResponse checks if the message is a response to a request sent
+// to the client, and routes it to the pending request.
+func (s *StdioServer) handleClientResponse(rawMessage json.RawMessage) bool {
+	return stdioSessionInstance.requests.deliver(rawMessage)
 }
 
 // writeResponse marshals and writes a JSON-RPC response message followed by a newline.
@@ -20,8 +20,8 @@ func TestStdioServer(t *testing.T) {
 		if stdioServer.server == nil {
 			t.Error("MCPServer should not be nil")
 		}
-		if stdioServer.errLogger == nil {
-			t.Error("errLogger should not be nil")
+		if stdioServer.logger == nil {
+			t.Error("logger should not be nil")
 		}
 	})
 
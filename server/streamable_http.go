@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime"
 	"net/http"
 	"net/http/httptest"
@@ -18,6 +20,10 @@ import (
 	"github.com/mark3labs/mcp-go/util"
 )
 
+// errStreamClosed is returned when writing to a stream whose response has
+// already been completed.
+var errStreamClosed = errors.New("stream closed")
+
 // StreamableHTTPOption defines a function type for configuring StreamableHTTPServer
 type StreamableHTTPOption func(*StreamableHTTPServer)
 
@@ -86,10 +92,78 @@ func WithStreamableHTTPServer(srv *http.Server) StreamableHTTPOption {
 	}
 }
 
+// WithHTTPLogger sets the logger of the server, by default the logger of the
+// MCPServer.
+func WithHTTPLogger(logger *slog.Logger) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.logger = logger
+	}
+}
+
 // WithLogger sets the logger for the server
+//
+// Deprecated: use WithHTTPLogger.
 func WithLogger(logger util.Logger) StreamableHTTPOption {
 	return func(s *StreamableHTTPServer) {
-		s.logger = logger
+		s.logger = util.ToSlog(logger)
+	}
+}
+
+// WithEventStore makes the SSE streams resumable. Every event sent on a stream
+// is stored in the event store and carries an ID. A client reconnecting with
+// the Last-Event-ID header of the last event it received gets the events
+// sent after it replayed, whether the stream was the GET stream or the
+// response stream of a POST request.
+//
+// With an event store, a disconnection is not considered as the client
+// cancelling its requests, and the GET stream of a session keeps collecting
+// messages for the resume window (see WithStreamResumeWindow) after its
+// connection ends.
+func WithEventStore(store EventStore) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.eventStore = store
+	}
+}
+
+// WithStreamResumeWindow sets how long the GET stream of a session outlives its
+// connection when an event store is configured, waiting for the client to
+// reconnect. The messages sent meanwhile are stored for the client to receive
+// on reconnection. The default is 5 minutes. It has no effect without
+// WithEventStore.
+func WithStreamResumeWindow(window time.Duration) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.streamResumeWindow = window
+	}
+}
+
+// SessionToolResolver returns the session tool of a name, to restore the tools
+// of a session loaded from the session store.
+type SessionToolResolver func(sessionID, name string) (ServerTool, bool)
+
+// WithSessionStore keeps the state of the sessions in a session store, from
+// which any replica of the server loads the session of a request: whether it
+// is initialized, its client info, log level and protocol version, the names
+// of its tools, the principal that initialized it and its custom values (see
+// SessionWithValues). The requests of a session missing from the store are
+// answered with 404 Not Found, for the client to start a new session.
+//
+// The requests sent to the client, the GET stream and the resources and
+// prompts of a session stay on the replica that handles them. A change of the
+// state saves it whole, so that concurrent changes of a session on different
+// replicas are last-writer-wins, see SessionStore.
+func WithSessionStore(store SessionStore) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.sessionStore = store
+	}
+}
+
+// WithSessionToolResolver sets how the session tools are restored when a
+// session is loaded from the session store, which only keeps their names. The
+// tools the resolver does not know are left out. Without a resolver, the
+// session tools stay on the replica that set them.
+func WithSessionToolResolver(resolver SessionToolResolver) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.sessionToolResolver = resolver
 	}
 }
 
@@ -114,13 +188,15 @@ func WithLogger(logger util.Logger) StreamableHTTPOption {
 // not trigger the session registration. So the methods like `SendNotificationToSpecificClient`
 // or `hooks.onRegisterSession` will not be triggered for POST messages.
 //
-// The current implementation does not support the following features from the specification:
-//   - Batching of requests/notifications/responses in arrays.
-//   - Stream Resumability
+// Streams are resumable once an event store is configured with WithEventStore,
+// and replicas of the server share their sessions with WithSessionStore.
 type StreamableHTTPServer struct {
 	server            *MCPServer
 	sessionTools      *sessionToolsStore
-	sessionRequestIDs sync.Map // sessionId --> last requestID(*atomic.Int64)
+	sessionItems      *sessionItemsStores
+	sessionRequests   sync.Map // sessionId --> requests sent to the client(*clientRequestTracker)
+	standaloneStreams sync.Map // sessionId --> GET stream(*standaloneStream)
+	postStreams       sync.Map // streamId --> POST stream being written(*postStream)
 
 	httpServer *http.Server
 	mu         sync.RWMutex
@@ -129,30 +205,85 @@ type StreamableHTTPServer struct {
 	contextFunc             HTTPContextFunc
 	sessionIdManager        SessionIdManager
 	listenHeartbeatInterval time.Duration
-	logger                  util.Logger
-	sessionLogLevels        *sessionLogLevelsStore
+	logger                  *slog.Logger
+	sessionStates           *sessionStateCache
+	sessionStore            SessionStore
+	sessionToolResolver     SessionToolResolver
+	eventStore              EventStore
+	streamResumeWindow      time.Duration
+	protectedResource       *ProtectedResource
+	authenticator           Authenticator
+}
+
+// defaultStreamResumeWindow is how long a GET stream waits for its client to
+// reconnect by default.
+const defaultStreamResumeWindow = 5 * time.Minute
+
+// WithHTTPProtectedResource makes the server an OAuth 2.1 protected resource:
+// it serves the protected resource metadata, and requires a valid bearer
+// token on the MCP endpoint.
+func WithHTTPProtectedResource(protectedResource *ProtectedResource) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.protectedResource = protectedResource
+	}
+}
+
+// WithHTTPAuthenticator sets the authenticator of the clients. A client that
+// fails to authenticate is rejected, and the requests of a session must be
+// made by the principal that initialized it.
+func WithHTTPAuthenticator(authenticator Authenticator) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.authenticator = authenticator
+	}
 }
 
 // NewStreamableHTTPServer creates a new streamable-http server instance
 func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *StreamableHTTPServer {
 	s := &StreamableHTTPServer{
-		server:           server,
-		sessionTools:     newSessionToolsStore(),
-		sessionLogLevels: newSessionLogLevelsStore(),
-		endpointPath:     "/mcp",
-		sessionIdManager: &InsecureStatefulSessionIdManager{},
-		logger:           util.DefaultLogger(),
+		server:             server,
+		sessionTools:       newSessionToolsStore(),
+		sessionItems:       newSessionItemsStores(),
+		endpointPath:       "/mcp",
+		sessionIdManager:   &InsecureStatefulSessionIdManager{},
+		logger:             server.logger,
+		streamResumeWindow: defaultStreamResumeWindow,
 	}
 
 	// Apply all options
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.sessionStates = newSessionStateCache(s.sessionStore, s.logger)
 	return s
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.protectedResource != nil {
+		if r.URL.Path == s.protectedResource.MetadataPath() {
+			s.protectedResource.MetadataHandler().ServeHTTP(w, r)
+			return
+		}
+		var ok bool
+		if r, ok = s.protectedResource.authorize(w, r); !ok {
+			return
+		}
+	}
+	if s.authenticator != nil {
+		var ok bool
+		if r, ok = authenticateHTTP(s.authenticator, s.protectedResource, w, r); !ok {
+			return
+		}
+	}
+	sessionID := r.Header.Get(headerKeySessionID)
+	if !s.loadSession(w, sessionID) {
+		return
+	}
+	if !s.sessionPrincipalAllowed(r, sessionID) {
+		http.Error(w, "Forbidden: session belongs to another principal", http.StatusForbidden)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodPost:
 		s.handlePost(w, r)
@@ -165,6 +296,57 @@ func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// sessionPrincipalAllowed reports whether the session, if any, was
+// initialized by the principal making the request. Sessions are only bound to
+// principals with an authenticator. With one, the requests of a session whose
+// state is not known, or not bound to a principal, are denied.
+func (s *StreamableHTTPServer) sessionPrincipalAllowed(r *http.Request, sessionID string) bool {
+	if s.authenticator == nil || sessionID == "" {
+		return true
+	}
+	state, ok := s.sessionStates.get(sessionID)
+	if !ok || state.PrincipalID == "" {
+		return false
+	}
+	principal := PrincipalFromContext(r.Context())
+	return principal != nil && principal.ID == state.PrincipalID
+}
+
+// loadSession loads the state of the session of a request from the session
+// store, if any, so that any replica of the server can handle the requests of
+// a session. The requests of a session missing from the store are rejected.
+func (s *StreamableHTTPServer) loadSession(w http.ResponseWriter, sessionID string) bool {
+	if s.sessionStore == nil || sessionID == "" {
+		return true
+	}
+	state, err := s.sessionStore.Load(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		s.logger.Error("failed to load session", "sessionID", sessionID, "error", err)
+		http.Error(w, "Session lookup failed", http.StatusInternalServerError)
+		return false
+	}
+	s.sessionStates.put(state)
+
+	// the tools of the session may have been set on another replica
+	if s.sessionToolResolver != nil && !sameToolNames(s.sessionTools.get(sessionID), state.Tools) {
+		tools := make(map[string]ServerTool, len(state.Tools))
+		for _, name := range state.Tools {
+			tool, ok := s.sessionToolResolver(sessionID, name)
+			if !ok {
+				s.logger.Warn("session tool not found", "sessionID", sessionID, "tool", name)
+				continue
+			}
+			tools[name] = tool
+		}
+		s.sessionTools.set(sessionID, tools)
+	}
+	return true
+}
+
 // Start begins serving the http server on the specified address and path
 // (endpointPath). like:
 //
@@ -174,6 +356,9 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 	if s.httpServer == nil {
 		mux := http.NewServeMux()
 		mux.Handle(s.endpointPath, s)
+		if s.protectedResource != nil {
+			mux.Handle(s.protectedResource.MetadataPath(), s.protectedResource.MetadataHandler())
+		}
 		s.httpServer = &http.Server{
 			Addr:    addr,
 			Handler: mux,
@@ -208,7 +393,8 @@ func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
 // --- internal methods ---
 
 const (
-	headerKeySessionID = "Mcp-Session-Id"
+	headerKeySessionID   = "Mcp-Session-Id"
+	headerKeyLastEventID = "Last-Event-ID"
 )
 
 func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
@@ -231,12 +417,26 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	var baseMessage struct {
 		Method mcp.MCPMethod `json:"method"`
 	}
-	if err := json.Unmarshal(rawData, &baseMessage); err != nil {
+	if isBatch(rawData) {
+		// Batches are validated by the MCP server; initialize is not allowed in them
+		if !json.Valid(rawData) {
+			s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, "request body is not valid json")
+			return
+		}
+	} else if err := json.Unmarshal(rawData, &baseMessage); err != nil {
 		s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, "request body is not valid json")
 		return
 	}
 	isInitializeRequest := baseMessage.Method == mcp.MethodInitialize
 
+	// The client sends the negotiated protocol version on every request
+	// after initialization
+	protocolVersion := r.Header.Get(headerKeyProtocolVersion)
+	if err := validateProtocolVersionHeader(protocolVersion); err != nil {
+		http.Error(w, fmt.Sprintf("Bad Request: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	params := make(map[string]string)
 	for k, v := range r.URL.Query() {
 		params[k] = v[0]
@@ -264,10 +464,44 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionLogLevels, params)
+	// Responses to requests sent to the client are routed to the waiting caller
+	if baseMessage.Method == "" && s.deliverClientResponse(sessionID, rawData) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionItems, s.sessionStates, params)
+	session.initializing = isInitializeRequest
+	principal := PrincipalFromContext(r.Context())
+	session.SetPrincipal(principal)
+	if isInitializeRequest && principal != nil {
+		// bind the session to the principal initializing it
+		s.sessionStates.update(sessionID, func(state *SessionState) {
+			state.PrincipalID = principal.ID
+		})
+	}
+	if !isInitializeRequest {
+		if protocolVersion == "" {
+			// Clients that do not send the header are assumed to use the
+			// version preceding it
+			protocolVersion = mcp.ProtocolVersion20250326
+		}
+		session.SetProtocolVersion(protocolVersion)
+	}
+
+	// With resumable streams, the request outlives the connection, so that
+	// its response can be replayed to the client once it reconnects
+	baseCtx := r.Context()
+	var stream *postStream
+	streamID := newPostStreamID(sessionID)
+	if s.eventStore != nil {
+		baseCtx = context.WithoutCancel(baseCtx)
+		stream = s.openPostStream(streamID)
+		defer s.closePostStream(streamID, stream)
+	}
 
 	// Set the client context before handling the message
-	ctx := s.server.WithContext(r.Context(), session)
+	ctx := s.server.WithContext(baseCtx, session)
 	if s.contextFunc != nil {
 		ctx = s.contextFunc(ctx, r)
 	}
@@ -277,41 +511,49 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	upgradedHeader := false
 	done := make(chan struct{})
 
+	// writeSSE writes a message on the response, upgrading it to an SSE stream
+	// first if needed. It fails once the final response has been written.
+	writeSSE := func(message any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		// if the done chan is closed, as the request is terminated, just return
+		select {
+		case <-done:
+			return errStreamClosed
+		default:
+		}
+		defer func() {
+			flusher, ok := w.(http.Flusher)
+			if ok {
+				flusher.Flush()
+			}
+		}()
+
+		// if there's notifications, upgradedHeader to SSE response
+		if !upgradedHeader {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			upgradedHeader = true
+		}
+		defer stream.notify()
+		return s.writeStreamEvent(w, streamID, message)
+	}
+	s.attachRequests(session, func(request clientRequest) error {
+		// requests to the client can only be sent on an SSE stream
+		session.upgradeToSSE.Store(true)
+		return writeSSE(request)
+	})
+
 	ctx = context.WithValue(ctx, requestHeader, r.Header)
 	go func() {
 		for {
 			select {
 			case nt := <-session.notificationChannel:
-				func() {
-					mu.Lock()
-					defer mu.Unlock()
-					// if the done chan is closed, as the request is terminated, just return
-					select {
-					case <-done:
-						return
-					default:
-					}
-					defer func() {
-						flusher, ok := w.(http.Flusher)
-						if ok {
-							flusher.Flush()
-						}
-					}()
-
-					// if there's notifications, upgradedHeader to SSE response
-					if !upgradedHeader {
-						w.Header().Set("Content-Type", "text/event-stream")
-						w.Header().Set("Connection", "keep-alive")
-						w.Header().Set("Cache-Control", "no-cache")
-						w.WriteHeader(http.StatusOK)
-						upgradedHeader = true
-					}
-					err := writeSSEEvent(w, nt)
-					if err != nil {
-						s.logger.Errorf("Failed to write SSE event: %v", err)
-						return
-					}
-				}()
+				if err := writeSSE(nt); err != nil && err != errStreamClosed {
+					s.logger.Error("failed to write SSE event", "sessionID", sessionID, "error", err)
+				}
 			case <-done:
 				return
 			case <-ctx.Done():
@@ -324,7 +566,12 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	response := s.server.HandleMessage(ctx, rawData)
 	if response == nil {
 		// For notifications, just send 202 Accepted with no body
-		w.WriteHeader(http.StatusAccepted)
+		mu.Lock()
+		defer mu.Unlock()
+		close(done)
+		if !upgradedHeader {
+			w.WriteHeader(http.StatusAccepted)
+		}
 		return
 	}
 
@@ -345,8 +592,8 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 			w.WriteHeader(http.StatusOK)
 			upgradedHeader = true
 		}
-		if err := writeSSEEvent(w, response); err != nil {
-			s.logger.Errorf("Failed to write final SSE response event: %v", err)
+		if err := s.writeStreamEvent(w, streamID, response); err != nil {
+			s.logger.Error("failed to write final SSE response event", "sessionID", sessionID, "error", err)
 		}
 	} else {
 		w.Header().Set("Content-Type", "application/json")
@@ -357,7 +604,7 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		w.WriteHeader(http.StatusOK)
 		err := json.NewEncoder(w).Encode(response)
 		if err != nil {
-			s.logger.Errorf("Failed to write response: %v", err)
+			s.logger.Error("failed to write response", "sessionID", sessionID, "error", err)
 		}
 	}
 }
@@ -369,23 +616,84 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	sessionID := r.Header.Get(headerKeySessionID)
 	// the specification didn't say we should validate the session id
 
+	protocolVersion := r.Header.Get(headerKeyProtocolVersion)
+	if err := validateProtocolVersionHeader(protocolVersion); err != nil {
+		http.Error(w, fmt.Sprintf("Bad Request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// A client reconnecting resumes the stream of the last event it received
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#resumability-and-redelivery
+	lastEventID := ""
+	if s.eventStore != nil {
+		lastEventID = r.Header.Get(headerKeyLastEventID)
+	}
+	if lastEventID != "" {
+		streamID, err := s.eventStore.StreamIDForEvent(lastEventID)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		streamSessionID, isPostStream := parseStreamID(streamID)
+		if sessionID != "" && streamSessionID != sessionID {
+			http.Error(w, "Last-Event-ID does not belong to the session", http.StatusBadRequest)
+			return
+		}
+		if sessionID == "" {
+			// The session comes from the event ID, which the client controls:
+			// it must be checked like the session header
+			if !s.loadSession(w, streamSessionID) {
+				return
+			}
+			if !s.sessionPrincipalAllowed(r, streamSessionID) {
+				http.Error(w, "Forbidden: session belongs to another principal", http.StatusForbidden)
+				return
+			}
+		}
+		if isPostStream {
+			s.resumePostStream(w, r, streamID, lastEventID)
+			return
+		}
+		sessionID = streamSessionID
+	}
+
 	if sessionID == "" {
 		// It's a stateless server,
 		// but the MCP server requires a unique ID for registering, so we use a random one
 		sessionID = uuid.New().String()
 	}
-
-	params := make(map[string]string)
-	for k, v := range r.URL.Query() {
-		params[k] = v[0]
+	if protocolVersion == "" {
+		protocolVersion = mcp.ProtocolVersion20250326
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionLogLevels, params)
-	if err := s.server.RegisterSession(r.Context(), session); err != nil {
-		http.Error(w, fmt.Sprintf("Session registration failed: %v", err), http.StatusBadRequest)
+	// A client reconnecting takes over the stream of its session, which may
+	// not have noticed yet that the previous connection is gone. The stream
+	// is only handed to the principal that opened it.
+	if value, ok := s.standaloneStreams.Load(sessionID); ok &&
+		!samePrincipal(value.(*standaloneStream).session.GetPrincipal(), PrincipalFromContext(r.Context())) {
+		http.Error(w, "Forbidden: stream belongs to another principal", http.StatusForbidden)
 		return
 	}
-	defer s.server.UnregisterSession(r.Context(), sessionID)
+	consumer := newStreamConsumer()
+	stream := s.takeOverStandaloneStream(sessionID, consumer)
+	if stream == nil {
+		params := make(map[string]string)
+		for k, v := range r.URL.Query() {
+			params[k] = v[0]
+		}
+
+		stream = newStandaloneStream(newStreamableHttpSession(sessionID, s.sessionTools, s.sessionItems, s.sessionStates, params), consumer)
+		stream.session.SetPrincipal(PrincipalFromContext(r.Context()))
+		s.attachRequests(stream.session, stream.writeRequest)
+		if err := s.server.RegisterSession(r.Context(), stream.session); err != nil {
+			http.Error(w, fmt.Sprintf("Session registration failed: %v", err), http.StatusBadRequest)
+			return
+		}
+		s.standaloneStreams.Store(sessionID, stream)
+	}
+	session := stream.session
+	session.SetProtocolVersion(protocolVersion)
+	defer s.releaseStandaloneStream(stream, consumer)
 
 	// Set the client context before handling the message
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -400,26 +708,20 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	}
 	flusher.Flush()
 
-	// Start notification handler for this session
+	if lastEventID != "" {
+		if err := s.eventStore.ReplayEventsAfter(lastEventID, func(eventID string, message json.RawMessage) error {
+			return writeSSEEvent(w, eventID, message)
+		}); err != nil {
+			s.logger.Error("failed to replay events", "sessionID", sessionID, "error", err)
+			return
+		}
+		flusher.Flush()
+	}
+
 	done := make(chan struct{})
 	defer close(done)
 	writeChan := make(chan any, 16)
 
-	go func() {
-		for {
-			select {
-			case nt := <-session.notificationChannel:
-				select {
-				case writeChan <- &nt:
-				case <-done:
-					return
-				}
-			case <-done:
-				return
-			}
-		}
-	}()
-
 	if s.listenHeartbeatInterval > 0 {
 		// heartbeat to keep the connection alive
 		go func() {
@@ -454,14 +756,67 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	for {
 		select {
 		case data := <-writeChan:
-			if data == nil {
-				continue
+			// heartbeats are not stored, there is no point replaying them
+			if err := writeSSEEvent(w, "", data); err != nil {
+				s.logger.Error("failed to write SSE event", "sessionID", sessionID, "error", err)
+				return
 			}
-			if err := writeSSEEvent(w, data); err != nil {
-				s.logger.Errorf("Failed to write SSE event: %v", err)
+			flusher.Flush()
+		case nt := <-session.notificationChannel:
+			if err := s.writeStreamEvent(w, sessionID, nt); err != nil {
+				s.logger.Error("failed to write SSE event", "sessionID", sessionID, "error", err)
 				return
 			}
 			flusher.Flush()
+		case request := <-stream.requests:
+			if err := s.writeStreamEvent(w, sessionID, request); err != nil {
+				s.logger.Error("failed to write SSE event", "sessionID", sessionID, "error", err)
+				return
+			}
+			flusher.Flush()
+		case <-consumer.stop:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// resumePostStream replays the events of a POST stream after lastEventID, and
+// the ones still to come if the request is still being handled.
+func (s *StreamableHTTPServer) resumePostStream(w http.ResponseWriter, r *http.Request, streamID string, lastEventID string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	flusher.Flush()
+
+	for {
+		// check whether the stream is still being written before replaying,
+		// so that the last events are not missed when it ends in between
+		value, active := s.postStreams.Load(streamID)
+		if err := s.eventStore.ReplayEventsAfter(lastEventID, func(eventID string, message json.RawMessage) error {
+			lastEventID = eventID
+			return writeSSEEvent(w, eventID, message)
+		}); err != nil {
+			s.logger.Error("failed to replay events", "streamID", streamID, "error", err)
+			return
+		}
+		flusher.Flush()
+		if !active {
+			return
+		}
+
+		stream := value.(*postStream)
+		select {
+		case <-stream.updated:
+		case <-stream.done:
 		case <-r.Context().Done():
 			return
 		}
@@ -483,25 +838,68 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 
 	// remove the session relateddata from the sessionToolsStore
 	s.sessionTools.delete(sessionID)
-	s.sessionLogLevels.delete(sessionID)
-	// remove current session's requstID information
-	s.sessionRequestIDs.Delete(sessionID)
+	s.sessionItems.delete(sessionID)
+	s.sessionStates.delete(sessionID)
+	// cancel the requests still being handled for the session
+	s.server.inFlightRequests.cancelSession(sessionID)
+	s.server.sessionRoots.delete(sessionID)
+	s.server.resourceSubscriptions.deleteSession(sessionID)
+	// fail the requests still waiting for a response from the client
+	if requests, ok := s.sessionRequests.LoadAndDelete(sessionID); ok {
+		requests.(*clientRequestTracker).abort(ErrSessionNotFound)
+	}
+	// end the GET stream, which may be waiting for the client to resume it
+	if stream := s.takeOverStandaloneStream(sessionID, newStreamConsumer()); stream != nil {
+		s.closeStandaloneStream(stream)
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func writeSSEEvent(w io.Writer, data any) error {
+// writeSSEEvent writes data as an SSE event, with the given event ID unless
+// it's empty.
+func writeSSEEvent(w io.Writer, eventID string, data any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
-	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", jsonData)
+	if eventID != "" {
+		_, err = fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", eventID, jsonData)
+	} else {
+		_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", jsonData)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write SSE event: %w", err)
 	}
 	return nil
 }
 
+// writeStreamEvent writes a message sent on a stream as an SSE event. With an
+// event store, the message is stored first, and the event carries its ID.
+func (s *StreamableHTTPServer) writeStreamEvent(w io.Writer, streamID string, message any) error {
+	return writeSSEEvent(w, s.storeEvent(streamID, message), message)
+}
+
+// storeEvent stores a message sent on a stream in the event store, if any, and
+// returns the ID of its event. The message is still sent when it can't be
+// stored, only it can't be replayed.
+func (s *StreamableHTTPServer) storeEvent(streamID string, message any) string {
+	if s.eventStore == nil {
+		return ""
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		s.logger.Error("failed to marshal event", "streamID", streamID, "error", err)
+		return ""
+	}
+	eventID, err := s.eventStore.StoreEvent(streamID, data)
+	if err != nil {
+		s.logger.Error("failed to store event", "streamID", streamID, "error", err)
+		return ""
+	}
+	return eventID
+}
+
 // writeJSONRPCError writes a JSON-RPC error response with the given error details.
 func (s *StreamableHTTPServer) writeJSONRPCError(
 	w http.ResponseWriter,
@@ -514,49 +912,299 @@ func (s *StreamableHTTPServer) writeJSONRPCError(
 	w.WriteHeader(http.StatusBadRequest)
 	err := json.NewEncoder(w).Encode(response)
 	if err != nil {
-		s.logger.Errorf("Failed to write JSONRPCError: %v", err)
+		s.logger.Error("failed to write JSONRPCError", "error", err)
 	}
 }
 
 // nextRequestID gets the next incrementing requestID for the current session
 func (s *StreamableHTTPServer) nextRequestID(sessionID string) int64 {
-	actual, _ := s.sessionRequestIDs.LoadOrStore(sessionID, new(atomic.Int64))
-	counter := actual.(*atomic.Int64)
-	return counter.Add(1)
+	return s.requestTracker(sessionID).nextID()
 }
 
-// --- session ---
-type sessionLogLevelsStore struct {
-	mu   sync.RWMutex
-	logs map[string]mcp.LoggingLevel
+// requestTracker returns the tracker of the requests sent to the client of the
+// given session. It is shared by all the streams of the session, since the
+// client may answer on any of them.
+func (s *StreamableHTTPServer) requestTracker(sessionID string) *clientRequestTracker {
+	actual, _ := s.sessionRequests.LoadOrStore(sessionID, newClientRequestTracker())
+	return actual.(*clientRequestTracker)
 }
 
-func newSessionLogLevelsStore() *sessionLogLevelsStore {
-	return &sessionLogLevelsStore{
-		logs: make(map[string]mcp.LoggingLevel),
+// attachRequests allows the session to send requests to its client through
+// the given stream writer. Stateless sessions cannot, since the response would
+// not be routed back to them.
+func (s *StreamableHTTPServer) attachRequests(session *streamableHttpSession, writeRequest func(request clientRequest) error) {
+	if session.sessionID == "" {
+		return
+	}
+	session.requests = s.requestTracker(session.sessionID)
+	session.writeRequest = writeRequest
+	session.standaloneStream = func() *streamableHttpSession {
+		if stream, ok := s.standaloneStreams.Load(session.sessionID); ok {
+			return stream.(*standaloneStream).session
+		}
+		return nil
 	}
 }
 
-func (s *sessionLogLevelsStore) get(sessionID string) mcp.LoggingLevel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	val, ok := s.logs[sessionID]
+// --- resumable streams ---
+
+// standaloneStream is the GET stream of a session, carrying the messages the
+// server initiates. With an event store, it outlives its connection for the
+// resume window, storing the messages sent meanwhile so that they are
+// replayed once the client reconnects.
+type standaloneStream struct {
+	session  *streamableHttpSession
+	requests chan clientRequest // server -> client requests
+	closed   chan struct{}      // closed once the session is unregistered
+
+	mu      sync.Mutex
+	current *streamConsumer // the connection, or the storing, consuming the messages
+}
+
+// streamConsumer consumes the messages of a GET stream, until it's asked to
+// stop by the one taking over the stream.
+type streamConsumer struct {
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newStreamConsumer() *streamConsumer {
+	return &streamConsumer{
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func newStandaloneStream(session *streamableHttpSession, consumer *streamConsumer) *standaloneStream {
+	return &standaloneStream{
+		session:  session,
+		requests: make(chan clientRequest),
+		closed:   make(chan struct{}),
+		current:  consumer,
+	}
+}
+
+func (st *standaloneStream) writeRequest(request clientRequest) error {
+	select {
+	case st.requests <- request:
+		return nil
+	case <-st.closed:
+		return errStreamClosed
+	}
+}
+
+// takeOverStandaloneStream makes the consumer the one of the GET stream of the
+// session, once the previous one, a connection or the storing of a detached
+// stream, has stopped. It returns nil if there is no stream, or if it can't be
+// taken over without an event store.
+func (s *StreamableHTTPServer) takeOverStandaloneStream(sessionID string, consumer *streamConsumer) *standaloneStream {
+	if s.eventStore == nil {
+		return nil
+	}
+	value, ok := s.standaloneStreams.Load(sessionID)
 	if !ok {
-		return mcp.LoggingLevelError
+		return nil
+	}
+	stream := value.(*standaloneStream)
+
+	stream.mu.Lock()
+	previous := stream.current
+	stream.current = consumer
+	stream.mu.Unlock()
+	if previous != nil {
+		close(previous.stop)
+		<-previous.stopped
+	}
+
+	select {
+	case <-stream.closed:
+		// the resume window elapsed in the meantime
+		return nil
+	default:
+		return stream
 	}
-	return val
 }
 
-func (s *sessionLogLevelsStore) set(sessionID string, level mcp.LoggingLevel) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.logs[sessionID] = level
+// releaseStandaloneStream is called when the connection of a GET stream ends.
+// With an event store, the stream keeps storing its messages for the resume
+// window, unless it has been taken over already. Otherwise it's closed.
+func (s *StreamableHTTPServer) releaseStandaloneStream(stream *standaloneStream, consumer *streamConsumer) {
+	defer close(consumer.stopped)
+	if s.eventStore == nil || s.streamResumeWindow <= 0 {
+		s.closeStandaloneStream(stream)
+		return
+	}
+
+	storing := newStreamConsumer()
+	stream.mu.Lock()
+	if stream.current != consumer {
+		stream.mu.Unlock()
+		return
+	}
+	stream.current = storing
+	stream.mu.Unlock()
+
+	go func() {
+		defer close(storing.stopped)
+		timer := time.NewTimer(s.streamResumeWindow)
+		defer timer.Stop()
+		sessionID := stream.session.sessionID
+		for {
+			select {
+			case nt := <-stream.session.notificationChannel:
+				s.storeEvent(sessionID, nt)
+			case request := <-stream.requests:
+				s.storeEvent(sessionID, request)
+			case <-storing.stop:
+				return
+			case <-timer.C:
+				s.closeStandaloneStream(stream)
+				return
+			}
+		}
+	}()
 }
 
-func (s *sessionLogLevelsStore) delete(sessionID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.logs, sessionID)
+// closeStandaloneStream ends a GET stream, unregistering its session.
+func (s *StreamableHTTPServer) closeStandaloneStream(stream *standaloneStream) {
+	sessionID := stream.session.sessionID
+	close(stream.closed)
+	s.server.UnregisterSession(context.Background(), sessionID)
+	s.standaloneStreams.CompareAndDelete(sessionID, stream)
+}
+
+// postStream tracks the SSE response of a POST request while it's being
+// written, for the clients resuming it to follow the new events.
+type postStream struct {
+	updated chan struct{} // signaled when an event is stored
+	done    chan struct{} // closed once the response is complete
+}
+
+// notify signals the clients resuming the stream that an event was stored.
+func (p *postStream) notify() {
+	if p == nil {
+		return
+	}
+	select {
+	case p.updated <- struct{}{}:
+	default:
+	}
+}
+
+func (s *StreamableHTTPServer) openPostStream(streamID string) *postStream {
+	stream := &postStream{
+		updated: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.postStreams.Store(streamID, stream)
+	return stream
+}
+
+func (s *StreamableHTTPServer) closePostStream(streamID string, stream *postStream) {
+	// remove the stream before signaling its end, for the clients resuming it
+	// to replay the last events once done
+	s.postStreams.Delete(streamID)
+	close(stream.done)
+}
+
+// newPostStreamID returns a new ID for the SSE response of a POST request of
+// the session. The GET stream of a session uses the session ID itself.
+func newPostStreamID(sessionID string) string {
+	return sessionID + "/" + uuid.New().String()
+}
+
+// parseStreamID returns the session of a stream, and whether it's the
+// response of a POST request rather than the GET stream.
+func parseStreamID(streamID string) (sessionID string, isPostStream bool) {
+	i := strings.LastIndex(streamID, "/")
+	if i < 0 {
+		return streamID, false
+	}
+	if _, err := uuid.Parse(streamID[i+1:]); err != nil {
+		return streamID, false
+	}
+	return streamID[:i], true
+}
+
+// deliverClientResponse routes a response posted by the client to the request
+// of the session waiting for it, reporting whether there was one.
+func (s *StreamableHTTPServer) deliverClientResponse(sessionID string, message json.RawMessage) bool {
+	if sessionID == "" {
+		return false
+	}
+	requests, ok := s.sessionRequests.Load(sessionID)
+	if !ok {
+		return false
+	}
+	return requests.(*clientRequestTracker).deliver(message)
+}
+
+// --- session ---
+
+// sessionStateCache keeps the state of the sessions handled by the server,
+// which outlives the ephemeral sessions of the POST requests, and writes it
+// through to the session store, if any.
+type sessionStateCache struct {
+	mu     sync.RWMutex
+	states map[string]SessionState
+	store  SessionStore
+	logger *slog.Logger
+}
+
+func newSessionStateCache(store SessionStore, logger *slog.Logger) *sessionStateCache {
+	return &sessionStateCache{
+		states: make(map[string]SessionState),
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (c *sessionStateCache) get(sessionID string) (SessionState, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	state, ok := c.states[sessionID]
+	return state.Clone(), ok
+}
+
+// put replaces the state of a session with the one loaded from the store
+func (c *sessionStateCache) put(state SessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states[state.SessionID] = state
+}
+
+// update changes the state of a session and saves it whole to the store,
+// replacing the changes other replicas made since it was loaded. Stateless
+// sessions, without an ID, keep no state.
+func (c *sessionStateCache) update(sessionID string, update func(state *SessionState)) {
+	if sessionID == "" {
+		update(&SessionState{})
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.states[sessionID]
+	if !ok {
+		state = SessionState{SessionID: sessionID}
+	}
+	update(&state)
+	c.states[sessionID] = state
+	if c.store != nil {
+		if err := c.store.Save(state.Clone()); err != nil {
+			c.logger.Error("failed to save session", "sessionID", sessionID, "error", err)
+		}
+	}
+}
+
+func (c *sessionStateCache) delete(sessionID string) {
+	c.mu.Lock()
+	delete(c.states, sessionID)
+	c.mu.Unlock()
+	if c.store != nil {
+		if err := c.store.Delete(sessionID); err != nil {
+			c.logger.Error("failed to delete session", "sessionID", sessionID, "error", err)
+		}
+	}
 }
 
 type sessionToolsStore struct {
@@ -588,24 +1236,90 @@ func (s *sessionToolsStore) delete(sessionID string) {
 	delete(s.tools, sessionID)
 }
 
+// sessionItemsStore stores items of a kind, such as resources, for every session
+type sessionItemsStore[T any] struct {
+	mu    sync.RWMutex
+	items map[string]map[string]T // sessionID -> key -> item
+}
+
+func newSessionItemsStore[T any]() *sessionItemsStore[T] {
+	return &sessionItemsStore[T]{
+		items: make(map[string]map[string]T),
+	}
+}
+
+func (s *sessionItemsStore[T]) get(sessionID string) map[string]T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.items[sessionID]
+}
+
+func (s *sessionItemsStore[T]) set(sessionID string, items map[string]T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[sessionID] = items
+}
+
+func (s *sessionItemsStore[T]) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, sessionID)
+}
+
+// sessionItemsStores holds the session-specific resources, resource templates
+// and prompts, which outlive the ephemeral sessions of the POST requests.
+type sessionItemsStores struct {
+	resources         *sessionItemsStore[ServerResource]
+	resourceTemplates *sessionItemsStore[ServerResourceTemplate]
+	prompts           *sessionItemsStore[ServerPrompt]
+}
+
+func newSessionItemsStores() *sessionItemsStores {
+	return &sessionItemsStores{
+		resources:         newSessionItemsStore[ServerResource](),
+		resourceTemplates: newSessionItemsStore[ServerResourceTemplate](),
+		prompts:           newSessionItemsStore[ServerPrompt](),
+	}
+}
+
+func (s *sessionItemsStores) delete(sessionID string) {
+	s.resources.delete(sessionID)
+	s.resourceTemplates.delete(sessionID)
+	s.prompts.delete(sessionID)
+}
+
 // streamableHttpSession is a session for streamable-http transport
 // When in POST handlers(request/notification), it's ephemeral, and only exists in the life of the request handler.
 // When in GET handlers(listening), it's a real session, and will be registered in the MCP server.
 type streamableHttpSession struct {
 	sessionID           string
 	notificationChannel chan mcp.JSONRPCNotification // server -> client notifications
+	requests            *clientRequestTracker
+	writeRequest        func(request clientRequest) error // writes a server -> client request on the stream
+	standaloneStream    func() *streamableHttpSession     // returns the GET stream of the session, if any
 	tools               *sessionToolsStore
+	items               *sessionItemsStores
 	upgradeToSSE        atomic.Bool
-	logLevels           *sessionLogLevelsStore
+	states              *sessionStateCache
+	initializing        bool // whether the session is handling the initialize request
 	params              map[string]string
+	protocolVersion     atomic.Value // the version negotiated on initialize, or sent by the client
+	principal           atomic.Pointer[Principal]
 }
 
-func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore, levels *sessionLogLevelsStore, params map[string]string) *streamableHttpSession {
+func newStreamableHttpSession(
+	sessionID string,
+	toolStore *sessionToolsStore,
+	itemStores *sessionItemsStores,
+	states *sessionStateCache,
+	params map[string]string,
+) *streamableHttpSession {
 	s := &streamableHttpSession{
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
 		tools:               toolStore,
-		logLevels:           levels,
+		items:               itemStores,
+		states:              states,
 		params:              params,
 	}
 	return s
@@ -624,8 +1338,10 @@ func (s *streamableHttpSession) NotificationChannel() chan<- mcp.JSONRPCNotifica
 }
 
 func (s *streamableHttpSession) Initialize() {
-	// do nothing
-	// the session is ephemeral, no real initialized action needed
+	// the session is ephemeral, only the state of the session records it
+	s.states.update(s.sessionID, func(state *SessionState) {
+		state.Initialized = true
+	})
 }
 
 func (s *streamableHttpSession) Initialized() bool {
@@ -634,11 +1350,54 @@ func (s *streamableHttpSession) Initialized() bool {
 }
 
 func (s *streamableHttpSession) SetLogLevel(level mcp.LoggingLevel) {
-	s.logLevels.set(s.sessionID, level)
+	s.states.update(s.sessionID, func(state *SessionState) {
+		state.LogLevel = level
+	})
 }
 
 func (s *streamableHttpSession) GetLogLevel() mcp.LoggingLevel {
-	return s.logLevels.get(s.sessionID)
+	state, _ := s.states.get(s.sessionID)
+	if state.LogLevel == "" {
+		return mcp.LoggingLevelError
+	}
+	return state.LogLevel
+}
+
+func (s *streamableHttpSession) GetClientInfo() mcp.Implementation {
+	state, _ := s.states.get(s.sessionID)
+	return state.ClientInfo
+}
+
+func (s *streamableHttpSession) SetClientInfo(clientInfo mcp.Implementation) {
+	s.states.update(s.sessionID, func(state *SessionState) {
+		state.ClientInfo = clientInfo
+	})
+}
+
+func (s *streamableHttpSession) GetClientCapabilities() mcp.ClientCapabilities {
+	state, _ := s.states.get(s.sessionID)
+	return state.ClientCapabilities
+}
+
+func (s *streamableHttpSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.states.update(s.sessionID, func(state *SessionState) {
+		state.ClientCapabilities = capabilities
+	})
+}
+
+func (s *streamableHttpSession) GetValue(key string) (string, bool) {
+	state, _ := s.states.get(s.sessionID)
+	value, ok := state.Values[key]
+	return value, ok
+}
+
+func (s *streamableHttpSession) SetValue(key, value string) {
+	s.states.update(s.sessionID, func(state *SessionState) {
+		if state.Values == nil {
+			state.Values = make(map[string]string)
+		}
+		state.Values[key] = value
+	})
 }
 
 var _ ClientSession = (*streamableHttpSession)(nil)
@@ -648,12 +1407,53 @@ func (s *streamableHttpSession) GetSessionTools() map[string]ServerTool {
 }
 
 func (s *streamableHttpSession) SetSessionTools(tools map[string]ServerTool) {
-	s.tools.set(s.sessionID, tools)
+	s.states.update(s.sessionID, func(state *SessionState) {
+		s.tools.set(s.sessionID, tools)
+		state.Tools = sessionToolNames(tools)
+	})
+}
+
+func (s *streamableHttpSession) GetSessionResources() map[string]ServerResource {
+	return s.items.resources.get(s.sessionID)
+}
+
+func (s *streamableHttpSession) SetSessionResources(resources map[string]ServerResource) {
+	s.items.resources.set(s.sessionID, resources)
+}
+
+func (s *streamableHttpSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.items.resourceTemplates.get(s.sessionID)
+}
+
+func (s *streamableHttpSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.items.resourceTemplates.set(s.sessionID, templates)
+}
+
+func (s *streamableHttpSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.items.prompts.get(s.sessionID)
+}
+
+func (s *streamableHttpSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.items.prompts.set(s.sessionID, prompts)
+}
+
+func (s *streamableHttpSession) GetPrincipal() *Principal {
+	return s.principal.Load()
+}
+
+func (s *streamableHttpSession) SetPrincipal(principal *Principal) {
+	s.principal.Store(principal)
 }
 
 var (
-	_ SessionWithTools   = (*streamableHttpSession)(nil)
-	_ SessionWithLogging = (*streamableHttpSession)(nil)
+	_ SessionWithTools              = (*streamableHttpSession)(nil)
+	_ SessionWithResources          = (*streamableHttpSession)(nil)
+	_ SessionWithPrompts            = (*streamableHttpSession)(nil)
+	_ SessionWithLogging            = (*streamableHttpSession)(nil)
+	_ SessionWithPrincipal          = (*streamableHttpSession)(nil)
+	_ SessionWithClientInfo         = (*streamableHttpSession)(nil)
+	_ SessionWithClientCapabilities = (*streamableHttpSession)(nil)
+	_ SessionWithValues             = (*streamableHttpSession)(nil)
 )
 
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
@@ -662,6 +1462,55 @@ func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
 
 var _ SessionWithStreamableHTTPConfig = (*streamableHttpSession)(nil)
 
+func (s *streamableHttpSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *streamableHttpSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+	if s.initializing {
+		// the other requests carry their version in a header
+		s.states.update(s.sessionID, func(state *SessionState) {
+			state.ProtocolVersion = version
+		})
+	}
+}
+
+var _ SessionWithProtocolVersion = (*streamableHttpSession)(nil)
+
+// SendRequest sends a request to the client and waits for the response, which
+// the client posts back to the server. The request is written on the stream of
+// the session, or on the GET stream of the session once that one has ended.
+func (s *streamableHttpSession) SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	if s.requests == nil {
+		// stateless sessions cannot route the response back to the caller
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+	return s.requests.do(ctx, method, params, func(request clientRequest) error {
+		err := s.writeRequest(request)
+		if err != errStreamClosed {
+			return err
+		}
+
+		stream := s.standaloneStream()
+		if stream == nil || stream == s {
+			return ErrSessionNotFound
+		}
+		if err := stream.writeRequest(request); err != nil {
+			if err == errStreamClosed {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		return nil
+	}, s.notificationChannel)
+}
+
+var _ SessionWithRequests = (*streamableHttpSession)(nil)
+
 // --- session id manager ---
 
 type SessionIdManager interface {
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resourceSubscriptionStore keeps track of the resource URIs each session has
+// subscribed to through resources/subscribe.
+type resourceSubscriptionStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[string]struct{} // sessionID -> URI set
+}
+
+func newResourceSubscriptionStore() *resourceSubscriptionStore {
+	return &resourceSubscriptionStore{
+		subscriptions: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *resourceSubscriptionStore) subscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uris, ok := s.subscriptions[sessionID]
+	if !ok {
+		uris = make(map[string]struct{})
+		s.subscriptions[sessionID] = uris
+	}
+	uris[uri] = struct{}{}
+}
+
+func (s *resourceSubscriptionStore) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uris, ok := s.subscriptions[sessionID]
+	if !ok {
+		return
+	}
+	delete(uris, uri)
+	if len(uris) == 0 {
+		delete(s.subscriptions, sessionID)
+	}
+}
+
+// sessionsFor returns the IDs of the sessions subscribed to the given URI.
+func (s *resourceSubscriptionStore) sessionsFor(uri string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var sessionIDs []string
+	for sessionID, uris := range s.subscriptions {
+		if _, ok := uris[uri]; ok {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// urisFor returns the URIs the given session is subscribed to, sorted.
+func (s *resourceSubscriptionStore) urisFor(sessionID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uris := make([]string, 0, len(s.subscriptions[sessionID]))
+	for uri := range s.subscriptions[sessionID] {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+func (s *resourceSubscriptionStore) deleteSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, sessionID)
+}
+
+// resourceExists reports whether the URI is served by a registered resource or
+// matches a registered resource template.
+func (s *MCPServer) resourceExists(uri string) bool {
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	if _, ok := s.resources[uri]; ok {
+		return true
+	}
+	for _, entry := range s.resourceTemplates {
+		if matchesTemplate(uri, entry.template.URITemplate) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionSession returns the session of a subscribe/unsubscribe request,
+// or a request error if subscriptions are not available for it.
+func (s *MCPServer) subscriptionSession(ctx context.Context, id any) (ClientSession, *requestError) {
+	s.capabilitiesMu.RLock()
+	subscribe := s.capabilities.resources != nil && s.capabilities.resources.subscribe
+	s.capabilitiesMu.RUnlock()
+	if !subscribe {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("resource subscriptions %w", ErrUnsupported),
+		}
+	}
+
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  ErrSessionNotFound,
+		}
+	}
+	return session, nil
+}
+
+func (s *MCPServer) handleSubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.SubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, reqErr := s.subscriptionSession(ctx, id)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	if !s.resourceExists(request.Params.URI) {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.RESOURCE_NOT_FOUND,
+			err: fmt.Errorf(
+				"cannot subscribe to resource URI '%s': %w",
+				request.Params.URI,
+				ErrResourceNotFound,
+			),
+		}
+	}
+	if err := s.authorize(ctx, PolicyRequest{Method: mcp.MethodResourcesRead, Name: request.Params.URI}); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  err,
+		}
+	}
+
+	s.resourceSubscriptions.subscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+func (s *MCPServer) handleUnsubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.UnsubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, reqErr := s.subscriptionSession(ctx, id)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	s.resourceSubscriptions.unsubscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+// ResourceSubscriptions returns the resource URIs the given session is
+// currently subscribed to.
+func (s *MCPServer) ResourceSubscriptions(sessionID string) []string {
+	return s.resourceSubscriptions.urisFor(sessionID)
+}
+
+// NotifyResourceUpdated sends a notifications/resources/updated notification
+// for the given URI to every session that subscribed to it. Sessions that did
+// not subscribe are left alone.
+func (s *MCPServer) NotifyResourceUpdated(uri string) {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationResourceUpdated,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"uri": uri,
+				},
+			},
+		},
+	}
+
+	for _, sessionID := range s.resourceSubscriptions.sessionsFor(uri) {
+		sessionValue, ok := s.sessions.Load(sessionID)
+		if !ok {
+			continue
+		}
+		session, ok := sessionValue.(ClientSession)
+		if !ok || !session.Initialized() {
+			continue
+		}
+		// Failures are already reported through the OnError hook
+		_ = s.sendNotificationToSpecificClient(session, notification)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSubscriptionTestServer(subscribe bool) *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(subscribe, false))
+	server.AddResource(
+		mcp.NewResource("test://static", "Static"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, nil
+		},
+	)
+	server.AddResourceTemplate(
+		mcp.NewResourceTemplate("repo://{owner}/{name}", "Repository"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, nil
+		},
+	)
+	return server
+}
+
+func subscriptionMessage(method mcp.MCPMethod, uri string) []byte {
+	return []byte(fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"method":"%s","params":{"uri":"%s"}}`,
+		method, uri,
+	))
+}
+
+func TestMCPServer_ResourceSubscribe(t *testing.T) {
+	t.Run("subscribe capability disabled", func(t *testing.T) {
+		server := newSubscriptionTestServer(false)
+		session := fakeSession{sessionID: "s1", initialized: true}
+		ctx := server.WithContext(context.Background(), session)
+
+		response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, "test://static"))
+		errResp, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.METHOD_NOT_FOUND, errResp.Error.Code)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		server := newSubscriptionTestServer(true)
+		session := fakeSession{sessionID: "s1", initialized: true}
+		ctx := server.WithContext(context.Background(), session)
+
+		response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, "test://missing"))
+		errResp, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.RESOURCE_NOT_FOUND, errResp.Error.Code)
+		assert.Empty(t, server.ResourceSubscriptions("s1"))
+	})
+
+	t.Run("static resource and template match", func(t *testing.T) {
+		server := newSubscriptionTestServer(true)
+		session := fakeSession{sessionID: "s1", initialized: true}
+		ctx := server.WithContext(context.Background(), session)
+
+		for _, uri := range []string{"test://static", "repo://mark3labs/mcp-go"} {
+			response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, uri))
+			_, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "subscribe to %s", uri)
+		}
+		assert.Equal(t, []string{"repo://mark3labs/mcp-go", "test://static"}, server.ResourceSubscriptions("s1"))
+
+		response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesUnsubscribe, "test://static"))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		assert.Equal(t, []string{"repo://mark3labs/mcp-go"}, server.ResourceSubscriptions("s1"))
+	})
+}
+
+func TestMCPServer_NotifyResourceUpdated(t *testing.T) {
+	server := newSubscriptionTestServer(true)
+
+	subscribed := fakeSession{
+		sessionID:           "subscribed",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	other := fakeSession{
+		sessionID:           "other",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), subscribed))
+	require.NoError(t, server.RegisterSession(context.Background(), other))
+
+	ctx := server.WithContext(context.Background(), subscribed)
+	response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, "repo://mark3labs/mcp-go"))
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+
+	server.NotifyResourceUpdated("repo://mark3labs/mcp-go")
+	server.NotifyResourceUpdated("test://static")
+
+	select {
+	case notification := <-subscribed.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+		assert.Equal(t, "repo://mark3labs/mcp-go", notification.Params.AdditionalFields["uri"])
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("expected resource updated notification")
+	}
+	assert.Empty(t, subscribed.notificationChannel)
+	assert.Empty(t, other.notificationChannel)
+
+	// Unregistering the session drops its subscriptions
+	server.UnregisterSession(context.Background(), subscribed.SessionID())
+	assert.Empty(t, server.ResourceSubscriptions(subscribed.SessionID()))
+}
+
+func TestStreamableHTTP_DeleteDropsResourceSubscriptions(t *testing.T) {
+	server := newSubscriptionTestServer(true)
+	testServer := httptest.NewServer(NewStreamableHTTPServer(server))
+	defer testServer.Close()
+
+	resp := postSession(t, testServer.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test-client","version":"1.0.0"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+	resp = postSession(t, testServer.URL, sessionID, string(subscriptionMessage(mcp.MethodResourcesSubscribe, "test://static")))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"test://static"}, server.ResourceSubscriptions(sessionID))
+
+	req, err := http.NewRequest(http.MethodDelete, testServer.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(headerKeySessionID, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, server.ResourceSubscriptions(sessionID))
+}
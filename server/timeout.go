@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithToolTimeout sets the default timeout of the tool calls, for the tools
+// without a ServerTool.Timeout. A call still running when its timeout expires
+// fails with an error wrapping ErrToolTimeout, answered with a
+// mcp.REQUEST_TIMEOUT error, and its context is cancelled.
+func WithToolTimeout(timeout time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.toolTimeout = timeout
+	}
+}
+
+// toolCallTimeout returns the timeout of a call to tool: the timeout of the
+// tool or the default one, or the one sent by the client if it is shorter
+func (s *MCPServer) toolCallTimeout(tool ServerTool, request mcp.CallToolRequest) time.Duration {
+	timeout := tool.Timeout
+	if timeout <= 0 {
+		timeout = s.toolTimeout
+	}
+	if clientTimeout := requestTimeout(request.Params.Meta); clientTimeout > 0 && (timeout <= 0 || clientTimeout < timeout) {
+		timeout = clientTimeout
+	}
+	return timeout
+}
+
+// requestTimeout returns the timeout sent by the client in the metadata of a
+// request, 0 if none
+func requestTimeout(meta *mcp.Meta) time.Duration {
+	if meta == nil {
+		return 0
+	}
+	var milliseconds float64
+	switch value := meta.AdditionalFields[mcp.TimeoutMetaKey].(type) {
+	case float64:
+		milliseconds = value
+	case int:
+		milliseconds = float64(value)
+	case int64:
+		milliseconds = float64(value)
+	default:
+		return 0
+	}
+	return time.Duration(milliseconds * float64(time.Millisecond))
+}
+
+// callToolWithTimeout calls handler with a context cancelled after timeout,
+// and stops waiting for it when the timeout expires. Without a timeout, the
+// handler is called directly.
+func callToolWithTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	handler ToolHandlerFunc,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if timeout <= 0 {
+		return handler(ctx, request)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan toolCallResult, 1)
+	go func() {
+		result, err := handler(timeoutCtx, request)
+		done <- toolCallResult{result, err}
+	}()
+
+	call, ok := waitToolCall(ctx, timeoutCtx, done)
+	if !ok {
+		return nil, fmt.Errorf("tool '%s' did not complete in %s: %w", request.Params.Name, timeout, ErrToolTimeout)
+	}
+	if call.err != nil && timeoutCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		// the handler gave up because of the timeout
+		return nil, fmt.Errorf("tool '%s' did not complete in %s: %w: %w", request.Params.Name, timeout, ErrToolTimeout, call.err)
+	}
+	return call.result, call.err
+}
+
+// toolCallResult is what a tool handler returned
+type toolCallResult struct {
+	result *mcp.CallToolResult
+	err    error
+}
+
+// waitToolCall waits for the handler to return on done, until timeoutCtx
+// expires. It reports false if the handler did not return in time. A handler
+// returning at the deadline wins over the timeout. If the request itself is
+// cancelled, the cause of the cancellation is returned without waiting for
+// the handler, which may ignore its context: it returns into the buffered
+// done channel whenever it completes.
+func waitToolCall(ctx, timeoutCtx context.Context, done <-chan toolCallResult) (toolCallResult, bool) {
+	select {
+	case call := <-done:
+		return call, true
+	case <-timeoutCtx.Done():
+	}
+	// the select picks randomly when both are ready: give the result a second
+	// chance before reporting the timeout
+	select {
+	case call := <-done:
+		return call, true
+	default:
+	}
+	if ctx.Err() != nil {
+		return toolCallResult{err: context.Cause(ctx)}, true
+	}
+	return toolCallResult{}, false
+}
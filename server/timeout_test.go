@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_ToolCallTimeout(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithToolTimeout(time.Minute))
+	withMeta := func(fields map[string]any) mcp.CallToolRequest {
+		var request mcp.CallToolRequest
+		request.Params.Meta = &mcp.Meta{AdditionalFields: fields}
+		return request
+	}
+
+	assert.Equal(t, time.Minute, server.toolCallTimeout(ServerTool{}, mcp.CallToolRequest{}))
+	assert.Equal(t, time.Hour, server.toolCallTimeout(ServerTool{Timeout: time.Hour}, mcp.CallToolRequest{}))
+	// the client timeout only applies when it is shorter
+	assert.Equal(t, 1500*time.Millisecond, server.toolCallTimeout(ServerTool{}, withMeta(map[string]any{mcp.TimeoutMetaKey: 1500.0})))
+	assert.Equal(t, time.Minute, server.toolCallTimeout(ServerTool{}, withMeta(map[string]any{mcp.TimeoutMetaKey: 3600000})))
+	assert.Equal(t, time.Minute, server.toolCallTimeout(ServerTool{}, withMeta(map[string]any{mcp.TimeoutMetaKey: "soon"})))
+
+	withoutDefault := NewMCPServer("test", "1.0.0")
+	assert.Zero(t, withoutDefault.toolCallTimeout(ServerTool{}, mcp.CallToolRequest{}))
+	assert.Equal(t, 2*time.Second, withoutDefault.toolCallTimeout(ServerTool{}, withMeta(map[string]any{mcp.TimeoutMetaKey: int64(2000)})))
+}
+
+func TestMCPServer_ToolTimeouts(t *testing.T) {
+	var errs []error
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		errs = append(errs, err)
+	})
+	server := NewMCPServer("test", "1.0.0", WithHooks(hooks), WithToolTimeout(50*time.Millisecond))
+
+	hung := make(chan struct{})
+	defer close(hung)
+	deadlines := make(chan time.Duration, 1)
+	server.AddTools(
+		ServerTool{
+			// ignores its context
+			Tool: mcp.NewTool("hung"),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				<-hung
+				return mcp.NewToolResultText("too late"), nil
+			},
+		},
+		ServerTool{
+			Tool: mcp.NewTool("cooperative"),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+		ServerTool{
+			Tool: mcp.NewTool("slow"),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				deadline, _ := ctx.Deadline()
+				deadlines <- time.Until(deadline)
+				time.Sleep(100 * time.Millisecond)
+				return mcp.NewToolResultText("done"), nil
+			},
+			Timeout: time.Minute,
+		},
+	)
+
+	for _, tool := range []string{"hung", "cooperative"} {
+		t.Run(tool, func(t *testing.T) {
+			errs = nil
+			start := time.Now()
+			response := server.HandleMessage(context.Background(), []byte(
+				`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tool+`"}}`))
+			assert.Less(t, time.Since(start), time.Second)
+			errorResponse, ok := response.(mcp.JSONRPCError)
+			require.True(t, ok)
+			assert.Equal(t, mcp.REQUEST_TIMEOUT, errorResponse.Error.Code)
+			assert.Contains(t, errorResponse.Error.Message, "tool '"+tool+"' did not complete in 50ms")
+			require.Len(t, errs, 1)
+			assert.True(t, errors.Is(errs[0], ErrToolTimeout))
+		})
+	}
+
+	t.Run("tool timeout overrides the default", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow"}}`))
+		_, ok := response.(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		assert.Greater(t, <-deadlines, 50*time.Second)
+	})
+
+	t.Run("shorter client timeout is honoured", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"slow","_meta":{"timeoutMs":20}}}`))
+		errorResponse, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.REQUEST_TIMEOUT, errorResponse.Error.Code)
+		assert.LessOrEqual(t, <-deadlines, 20*time.Millisecond)
+	})
+}
+
+func TestWaitToolCall_Deadline(t *testing.T) {
+	// the handler returned at the deadline: both channels are ready, and the
+	// result must win every time
+	for range 100 {
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), 0)
+		done := make(chan toolCallResult, 1)
+		done <- toolCallResult{result: mcp.NewToolResultText("done")}
+
+		call, ok := waitToolCall(context.Background(), timeoutCtx, done)
+		cancel()
+		require.True(t, ok)
+		assert.Equal(t, mcp.NewToolResultText("done"), call.result)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, ok := waitToolCall(context.Background(), timeoutCtx, make(chan toolCallResult))
+	assert.False(t, ok)
+}
+
+func TestCallToolWithTimeout_Cancelled(t *testing.T) {
+	hung := make(chan struct{})
+	defer close(hung)
+	// ignores its context
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-hung
+		return mcp.NewToolResultText("too late"), nil
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(10*time.Millisecond, func() { cancel(ErrRequestCancelled) })
+	start := time.Now()
+	result, err := callToolWithTimeout(ctx, time.Minute, handler, mcp.CallToolRequest{})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrRequestCancelled)
+	assert.NotErrorIs(t, err, ErrToolTimeout)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SpanKind is the role of a span in the exchanges with the client.
+type SpanKind int
+
+const (
+	// SpanKindInternal is a span of the handling of a request, such as a
+	// middleware or a handler.
+	SpanKindInternal SpanKind = iota
+	// SpanKindServer is the span of a request received from the client.
+	SpanKindServer
+	// SpanKindClient is the span of a request sent to the client.
+	SpanKindClient
+)
+
+// Tracer starts the spans of the requests handled by the server, and
+// propagates their trace context to and from the client through the _meta of
+// the requests. The otelmcp package adapts OpenTelemetry to it.
+type Tracer interface {
+	mcp.TracePropagator
+	// Start starts a span as a child of the span of ctx, and returns a copy of
+	// ctx holding the new span. The attributes are strings, ints and bools.
+	Start(ctx context.Context, name string, kind SpanKind, attributes map[string]any) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attributes map[string]any)
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+	End()
+}
+
+// WithTracer traces the requests handled by the server. Each request gets a
+// server span, a child of the span of the client if the request carries a
+// W3C trace context in its _meta, and the tool, resource, prompt and method
+// middlewares and handlers get child spans of their own. The requests sent
+// to the client, such as sampling requests, get client spans whose trace
+// context is sent in their _meta.
+func WithTracer(tracer Tracer) ServerOption {
+	return func(s *MCPServer) {
+		s.tracer = tracer
+	}
+}
+
+// startRequestSpan starts the span of a request received from the client, and
+// returns the function ending it with the response
+func (s *MCPServer) startRequestSpan(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	params json.RawMessage,
+) (context.Context, func(response mcp.JSONRPCMessage)) {
+	if s.tracer == nil {
+		return ctx, func(mcp.JSONRPCMessage) {}
+	}
+	ctx = mcp.ExtractTraceContext(ctx, s.tracer, params)
+
+	name := string(method)
+	attributes := map[string]any{
+		"mcp.method.name":    string(method),
+		"jsonrpc.request.id": fmt.Sprint(id),
+	}
+	if session := ClientSessionFromContext(ctx); session != nil {
+		attributes["mcp.session.id"] = session.SessionID()
+	}
+	switch target, uri := requestTarget(params); method {
+	case mcp.MethodToolsCall:
+		name += " " + target
+		attributes["gen_ai.tool.name"] = target
+	case mcp.MethodPromptsGet:
+		name += " " + target
+		attributes["gen_ai.prompt.name"] = target
+	case mcp.MethodResourcesRead:
+		attributes["mcp.resource.uri"] = uri
+	}
+
+	ctx, span := s.tracer.Start(ctx, name, SpanKindServer, attributes)
+	return ctx, func(response mcp.JSONRPCMessage) {
+		if errorResponse, ok := response.(mcp.JSONRPCError); ok {
+			span.SetAttributes(map[string]any{"rpc.jsonrpc.error_code": errorResponse.Error.Code})
+			span.RecordError(errors.New(errorResponse.Error.Message))
+		}
+		span.End()
+	}
+}
+
+// requestTarget returns the name of the tool or prompt, and the URI of the
+// resource, of the params of a request
+func requestTarget(params json.RawMessage) (name, uri string) {
+	var target struct {
+		Name string `json:"name"`
+		URI  string `json:"uri"`
+	}
+	if len(params) > 0 && json.Unmarshal(params, &target) == nil {
+		return target.Name, target.URI
+	}
+	return "", ""
+}
+
+// startClientRequestSpan starts the span of a request sent to the client, and
+// returns its params with the trace context of the span added to their _meta
+func (s *MCPServer) startClientRequestSpan(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (context.Context, any, Span, error) {
+	ctx, span := s.tracer.Start(ctx, string(method), SpanKindClient, map[string]any{
+		"mcp.method.name": string(method),
+	})
+	params, err := mcp.InjectTraceContext(ctx, s.tracer, params)
+	if err != nil {
+		span.RecordError(err)
+		span.End()
+		return ctx, nil, nil, err
+	}
+	return ctx, params, span, nil
+}
+
+// traceHandler wraps a tool, resource or prompt handler, or one of their
+// middlewares, in a span. The handler is returned as is without a tracer.
+func traceHandler[Req, Res any, H ~func(context.Context, Req) (Res, error)](
+	tracer Tracer,
+	handler H,
+	name string,
+	attributes map[string]any,
+) H {
+	if tracer == nil {
+		return handler
+	}
+	return func(ctx context.Context, request Req) (Res, error) {
+		ctx, span := tracer.Start(ctx, name, SpanKindInternal, attributes)
+		defer span.End()
+		result, err := handler(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+		}
+		return result, err
+	}
+}
+
+// traceMethodHandler wraps a method handler middleware in a span
+func traceMethodHandler(tracer Tracer, handler MethodHandlerFunc, attributes map[string]any) MethodHandlerFunc {
+	if tracer == nil {
+		return handler
+	}
+	return func(ctx context.Context, id any, method mcp.MCPMethod, params json.RawMessage) mcp.JSONRPCMessage {
+		ctx, span := tracer.Start(ctx, "method middleware", SpanKindInternal, attributes)
+		defer span.End()
+		response := handler(ctx, id, method, params)
+		if errorResponse, ok := response.(mcp.JSONRPCError); ok {
+			span.RecordError(errors.New(errorResponse.Error.Message))
+		}
+		return response
+	}
+}
+
+// middlewareAttributes returns the attributes of the span of the middleware
+// at index in its chain
+func middlewareAttributes(index int) map[string]any {
+	return map[string]any{"mcp.middleware.index": index}
+}
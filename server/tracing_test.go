@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+type testSpan struct {
+	tracer     *testTracer
+	id         string
+	parent     string
+	name       string
+	kind       SpanKind
+	attributes map[string]any
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attributes map[string]any) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for key, value := range attributes {
+		s.attributes[key] = value
+	}
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended = true
+}
+
+type testSpanKey struct{}
+
+type remoteParentKey struct{}
+
+// testTracer records its spans, and propagates the ID of the current span as
+// the parent ID of a W3C traceparent
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, kind SpanKind, attributes map[string]any) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &testSpan{
+		tracer:     t,
+		id:         fmt.Sprintf("%016x", len(t.spans)+1),
+		name:       name,
+		kind:       kind,
+		attributes: make(map[string]any),
+	}
+	for key, value := range attributes {
+		span.attributes[key] = value
+	}
+	if parent, ok := ctx.Value(testSpanKey{}).(*testSpan); ok {
+		span.parent = parent.id
+	} else if remote, ok := ctx.Value(remoteParentKey{}).(string); ok {
+		span.parent = remote
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (t *testTracer) Inject(ctx context.Context, fields map[string]string) {
+	if span, ok := ctx.Value(testSpanKey{}).(*testSpan); ok {
+		fields[mcp.TraceParentMetaKey] = "00-" + testTraceID + "-" + span.id + "-01"
+	}
+}
+
+func (t *testTracer) Extract(ctx context.Context, fields map[string]string) context.Context {
+	parts := strings.Split(fields[mcp.TraceParentMetaKey], "-")
+	if len(parts) != 4 {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, parts[2])
+}
+
+// span returns the recorded span with the given name
+func (t *testTracer) span(name string) *testSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestMCPServer_Tracing(t *testing.T) {
+	tracer := &testTracer{}
+	server := NewMCPServer("test", "1.0.0",
+		WithTracer(tracer),
+		WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return next
+		}),
+	)
+	server.AddTool(mcp.NewTool("search"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("found"), nil
+	})
+	server.AddTool(mcp.NewTool("broken"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, fmt.Errorf("out of order")
+	})
+	ctx := server.WithContext(context.Background(), NewInProcessSession("session-1", nil))
+
+	t.Run("request spans continue the trace of the client", func(t *testing.T) {
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{
+			"name":"search","_meta":{"traceparent":"00-`+testTraceID+`-00f067aa0ba902b7-01"}}}`))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+
+		request := tracer.span("tools/call search")
+		require.NotNil(t, request)
+		assert.Equal(t, SpanKindServer, request.kind)
+		assert.Equal(t, "00f067aa0ba902b7", request.parent)
+		assert.Equal(t, map[string]any{
+			"mcp.method.name":    "tools/call",
+			"jsonrpc.request.id": "1",
+			"mcp.session.id":     "session-1",
+			"gen_ai.tool.name":   "search",
+		}, request.attributes)
+		assert.True(t, request.ended)
+
+		middleware := tracer.span("tool middleware")
+		require.NotNil(t, middleware)
+		assert.Equal(t, request.id, middleware.parent)
+		assert.Equal(t, 0, middleware.attributes["mcp.middleware.index"])
+		handler := tracer.span("tool handler")
+		require.NotNil(t, handler)
+		assert.Equal(t, middleware.id, handler.parent)
+		assert.Equal(t, SpanKindInternal, handler.kind)
+		assert.True(t, handler.ended)
+	})
+
+	t.Run("failed requests are recorded", func(t *testing.T) {
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"broken"}}`))
+		_, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+
+		request := tracer.span("tools/call broken")
+		require.NotNil(t, request)
+		assert.Empty(t, request.parent)
+		assert.Equal(t, mcp.INTERNAL_ERROR, request.attributes["rpc.jsonrpc.error_code"])
+		assert.EqualError(t, request.err, "out of order")
+	})
+}
+
+func TestMCPServer_TracingClientRequests(t *testing.T) {
+	tracer := &testTracer{}
+	server := NewMCPServer("test", "1.0.0", WithTracer(tracer))
+	var sent any
+	session := &fakeRequestSession{
+		fakeSession: fakeSession{sessionID: "session-1"},
+		respond: func(method mcp.MCPMethod, params any) (any, error) {
+			sent = params
+			return mcp.ListRootsResult{}, nil
+		},
+	}
+	ctx, span := tracer.Start(server.WithContext(context.Background(), session), "tools/call", SpanKindServer, nil)
+	span.End()
+
+	_, err := server.SendRequestToClient(ctx, mcp.MethodListRoots, nil)
+	require.NoError(t, err)
+
+	request := tracer.span(string(mcp.MethodListRoots))
+	require.NotNil(t, request)
+	assert.Equal(t, SpanKindClient, request.kind)
+	assert.Equal(t, "0000000000000001", request.parent)
+	assert.True(t, request.ended)
+	assert.Equal(t, map[string]any{
+		"_meta": map[string]any{mcp.TraceParentMetaKey: "00-" + testTraceID + "-" + request.id + "-01"},
+	}, sent)
+}
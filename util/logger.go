@@ -1,10 +1,16 @@
 package util
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"strconv"
+	"strings"
 )
 
-// Logger defines a minimal logging interface
+// Logger defines a minimal logging interface. The servers and transports log
+// with a *slog.Logger, ToSlog routes their logs to a Logger.
 type Logger interface {
 	Infof(format string, v ...any)
 	Errorf(format string, v ...any)
@@ -14,8 +20,13 @@ type Logger interface {
 
 // DefaultStdLogger implements Logger using the standard library's log.Logger.
 func DefaultLogger() Logger {
+	return NewStdLogger(log.Default())
+}
+
+// NewStdLogger implements Logger using a standard library's log.Logger.
+func NewStdLogger(logger *log.Logger) Logger {
 	return &stdLogger{
-		logger: log.Default(),
+		logger: logger,
 	}
 }
 
@@ -31,3 +42,81 @@ func (l *stdLogger) Infof(format string, v ...any) {
 func (l *stdLogger) Errorf(format string, v ...any) {
 	l.logger.Printf("ERROR: "+format, v...)
 }
+
+// --- log/slog Adapter ---
+
+// ToSlog returns a *slog.Logger writing to logger, for the servers and
+// transports configured with a Logger. The records of level Info and above are
+// logged, the warnings and errors with Errorf, with their attributes appended
+// to the message as key=value pairs.
+func ToSlog(logger Logger) *slog.Logger {
+	return slog.New(&loggerHandler{logger: logger})
+}
+
+// loggerHandler is a slog.Handler writing to a Logger
+type loggerHandler struct {
+	logger Logger
+	// attrs are the formatted attributes of WithAttrs
+	attrs string
+	// group is the prefix of the keys of the attributes, from WithGroup
+	group string
+}
+
+func (h *loggerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+func (h *loggerHandler) Handle(ctx context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&b, h.group, attr)
+		return true
+	})
+	if record.Level >= slog.LevelWarn {
+		h.logger.Errorf("%s", b.String())
+	} else {
+		h.logger.Infof("%s", b.String())
+	}
+	return nil
+}
+
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, attr := range attrs {
+		appendAttr(&b, h.group, attr)
+	}
+	return &loggerHandler{logger: h.logger, attrs: b.String(), group: h.group}
+}
+
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// appendAttr appends an attribute as " key=value", flattening groups into
+// dotted keys
+func appendAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			appendAttr(b, prefix, member)
+		}
+		return
+	}
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, attr.Key, value)
+}
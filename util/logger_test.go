@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Infof(format string, v ...any) {
+	l.lines = append(l.lines, "INFO "+fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Errorf(format string, v ...any) {
+	l.lines = append(l.lines, "ERROR "+fmt.Sprintf(format, v...))
+}
+
+func TestToSlog(t *testing.T) {
+	recorder := &recordingLogger{}
+	logger := ToSlog(recorder).With("sessionID", "abc")
+
+	logger.Debug("hidden")
+	logger.Info("request handled", "method", "tools/call", slog.Group("tool", "name", "search"))
+	logger.WithGroup("http").Warn("write failed", "error", fmt.Errorf("broken pipe"), "status", 500)
+
+	want := []string{
+		`INFO request handled sessionID=abc method=tools/call tool.name=search`,
+		`ERROR write failed sessionID=abc http.error="broken pipe" http.status=500`,
+	}
+	if len(recorder.lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), recorder.lines)
+	}
+	for i, line := range want {
+		if recorder.lines[i] != line {
+			t.Errorf("Expected line %q, got %q", line, recorder.lines[i])
+		}
+	}
+}